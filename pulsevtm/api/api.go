@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
@@ -8,7 +9,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/sky-uk/go-rest-api"
+	"github.com/hashicorp/go-version"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api/rest"
 )
 
 const defaultAPIVersion = "3.8"
@@ -25,6 +27,10 @@ type Params struct {
 	Debug      bool
 	Timeout    time.Duration
 	Headers    map[string]string
+	// TLSConfig, when set, is used for connections to the server in place of the configuration built from IgnoreSSL
+	TLSConfig *tls.Config
+	// Transport, when set, is shared by every request made by the client so connections are reused
+	Transport http.RoundTripper
 }
 
 // Client - the Pulse Secure vTM Client struct
@@ -122,6 +128,9 @@ func Connect(params Params) (*Client, error) {
 		Debug:     params.Debug,
 		Headers:   params.Headers,
 		Timeout:   params.Timeout,
+
+		TLSClientConfig: params.TLSConfig,
+		Transport:       params.Transport,
 	}
 
 	supportedVersionsMap := make(map[string]interface{})
@@ -136,20 +145,31 @@ func Connect(params Params) (*Client, error) {
 			new(VTMError),
 		)
 		err := client.request(api)
-		if err != nil || api.StatusCode() != http.StatusOK {
+		if err == nil && api.StatusCode() != http.StatusOK {
+			err = fmt.Errorf("[ERROR] Unexpected status %d while fetching list of available API versions", api.StatusCode())
+		}
+		if err != nil {
 			log.Println("[ERROR] Error while fetching list of available API versions: ", err)
 			return nil, err
 		}
 
+		children, ok := supportedVersionsMap["children"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("[ERROR] Unexpected format of the list of available API versions")
+		}
 		versions := make([]string, 0)
-		for _, version := range supportedVersionsMap["children"].([]interface{}) {
-			if vAsMap, ok := version.(map[string]interface{}); ok {
-				versions = append(versions, vAsMap["name"].(string))
+		for _, child := range children {
+			if vAsMap, ok := child.(map[string]interface{}); ok {
+				if name, ok := vAsMap["name"].(string); ok {
+					versions = append(versions, name)
+				}
 			}
 		}
 
-		client.VersionsSupported = versions
-		sort.Sort(sort.Reverse(sort.StringSlice(client.VersionsSupported)))
+		client.VersionsSupported = sortAPIVersions(versions)
+		if len(client.VersionsSupported) == 0 {
+			return nil, fmt.Errorf("[ERROR] No API versions available on %s", params.Server)
+		}
 		client.currentVersion = client.VersionsSupported[0]
 		log.Println("[DEBUG] Working with REST API Version: ", client.currentVersion)
 
@@ -158,6 +178,29 @@ func Connect(params Params) (*Client, error) {
 	return client, nil
 }
 
+// sortAPIVersions - returns the API versions which can be parsed, newest first.
+// Versions are compared semantically, so 10.0 is newer than 5.1
+func sortAPIVersions(names []string) []string {
+	parsed := make(version.Collection, 0)
+	byVersion := make(map[*version.Version]string)
+	for _, name := range names {
+		v, err := version.NewVersion(name)
+		if err != nil {
+			log.Printf("[DEBUG] Ignoring API version %s: %v", name, err)
+			continue
+		}
+		parsed = append(parsed, v)
+		byVersion[v] = name
+	}
+	sort.Sort(sort.Reverse(parsed))
+
+	sorted := make([]string, 0, len(parsed))
+	for _, v := range parsed {
+		sorted = append(sorted, byVersion[v])
+	}
+	return sorted
+}
+
 // GetAllResourceTypes - returns the list of all types of configuration resources
 func (client *Client) GetAllResourceTypes() ([]map[string]interface{}, error) {
 
@@ -196,10 +239,19 @@ func FormatNestedStruct(str map[string]interface{}, level int) string {
 	level++
 	for key, value := range str {
 		outStr += strings.Repeat("\t", level) + key
-		if vAsStr, ok := value.(string); ok {
-			outStr += " : " + vAsStr
-		} else {
-			outStr += FormatNestedStruct(value.(map[string]interface{}), level)
+		switch typed := value.(type) {
+		case string:
+			outStr += " : " + typed
+		case map[string]interface{}:
+			outStr += FormatNestedStruct(typed, level)
+		case []interface{}:
+			items := make(map[string]interface{})
+			for index, item := range typed {
+				items[fmt.Sprint(index)] = item
+			}
+			outStr += FormatNestedStruct(items, level)
+		default:
+			outStr += " : " + fmt.Sprint(typed)
 		}
 		outStr += "\n"
 	}
@@ -214,13 +266,14 @@ func FormatErrorText(tmErr *VTMError) string {
 	return retStr
 }
 
+// request - performs the request, returning the *VTMError the server responded with when there's one
 func (client *Client) request(api *rest.BaseAPI) error {
 	err := client.restClient.Do(api)
 	client.StatusCode = client.restClient.StatusCode
 	tmErr := api.ErrorObject().(*VTMError)
 	if tmErr.ErrorText != "" {
-		errStr := FormatErrorText(tmErr)
-		err = fmt.Errorf(errStr)
+		tmErr.StatusCode = client.StatusCode
+		return tmErr
 	}
 	return err
 }
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// backupArchiveContentType - the content type of backups transferred as tar archives
+const backupArchiveContentType = "application/x-tar"
+
+// backupArchivePath - returns the path of a full backup, independently of the path the client works with
+func (client *Client) backupArchivePath(tm, backupName string) string {
+	return apiPrefix + "/" + client.currentVersion + "/status/" + tm + "/backups/full/" + backupName
+}
+
+// streamRequest - performs a request whose body, or whose response's body, is streamed rather than encoded
+// as JSON. A *VTMError is returned when the server responds with one.
+func (client *Client) streamRequest(method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, client.restClient.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if client.restClient.User != "" {
+		req.SetBasicAuth(client.restClient.User, client.restClient.Password)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	transport := client.restClient.Transport
+	if transport == nil {
+		tlsConfig := client.restClient.TLSClientConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{InsecureSkipVerify: client.restClient.IgnoreSSL}
+		}
+		transport = &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}
+	}
+	// Archives can be large, so unlike other requests the transfer isn't limited by the client's timeout
+	httpClient := &http.Client{Transport: transport}
+	if client.params.Debug {
+		log.Printf("[TRACE] Going to perform streamed request:[%s] %s\n", method, req.URL)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	client.StatusCode = res.StatusCode
+	if res.StatusCode >= http.StatusBadRequest {
+		defer res.Body.Close()
+		tmErr := &VTMError{StatusCode: res.StatusCode}
+		errorBody, _ := ioutil.ReadAll(res.Body)
+		if strings.Contains(res.Header.Get("Content-Type"), "json") && json.Unmarshal(errorBody, tmErr) == nil && tmErr.ErrorText != "" {
+			return nil, tmErr
+		}
+		return nil, StatusCodeError(res.StatusCode)
+	}
+	return res, nil
+}
+
+// DownloadBackup - streams a full backup as a tar archive to out
+func (client *Client) DownloadBackup(tm, backupName string, out io.Writer) error {
+	res, err := client.streamRequest(http.MethodGet, client.backupArchivePath(tm, backupName), nil, map[string]string{
+		"Accept": backupArchiveContentType,
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, backupArchiveContentType) {
+		return fmt.Errorf("[ERROR] Expected backup %s to be returned as %s, got %s", backupName, backupArchiveContentType, contentType)
+	}
+	_, err = io.Copy(out, res.Body)
+	return err
+}
+
+// UploadBackup - creates a full backup from a tar archive
+func (client *Client) UploadBackup(tm, backupName string, archive io.Reader) error {
+	res, err := client.streamRequest(http.MethodPut, client.backupArchivePath(tm, backupName), archive, map[string]string{
+		"Content-Type": backupArchiveContentType,
+	})
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
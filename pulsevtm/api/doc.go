@@ -0,0 +1,8 @@
+// Package api is the provider's copy of github.com/sky-uk/go-pulse-vtm/api 0.4.52, carrying the changes the provider
+// needs until they're released upstream. Params.TLSConfig and Params.Transport let the connection trust a CA,
+// present a client certificate, go through a proxy and reuse connections. Errors from the REST API are returned as
+// a *VTMError, holding the error_info tree and the status code of the response. Backups can be downloaded and
+// uploaded as tar archives.
+//
+// It's covered by the BSD 3-Clause licence in LICENSE.
+package api
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VTMError : Generic error object for Pulse Secure vTM
+type VTMError struct {
+	ErrorID   string                 `json:"error_id"`
+	ErrorText string                 `json:"error_text,omitempty"`
+	ErrorInfo map[string]interface{} `json:"error_info,omitempty"`
+	// StatusCode is the HTTP status code of the response the error was returned in
+	StatusCode int `json:"-"`
+}
+
+// VTMErrorLeaf : a single error in the error_info tree of a VTMError, e.g. the error with a property of a resource
+type VTMErrorLeaf struct {
+	// Path is the keys leading to the error in the error_info tree, e.g. [basic port]
+	Path      []string
+	ErrorID   string
+	ErrorText string
+}
+
+// StatusCodeError : the HTTP status code of a failed request which didn't return a VTMError
+type StatusCodeError int
+
+// Error - returns the status code along with its text
+func (code StatusCodeError) Error() string {
+	return fmt.Sprintf("HTTP status %d %s", int(code), http.StatusText(int(code)))
+}
+
+// Error - returns the error id and text followed by each of the errors in error_info
+func (tmErr *VTMError) Error() string {
+	return FormatErrorText(tmErr)
+}
+
+// Summary - returns the error id and text, without the errors in error_info
+func (tmErr *VTMError) Summary() string {
+	return tmErr.ErrorID + ": " + tmErr.ErrorText
+}
+
+// Leaves - returns the errors in the error_info tree, ordered by their path
+func (tmErr *VTMError) Leaves() []VTMErrorLeaf {
+	leaves := collectErrorLeaves(tmErr.ErrorInfo, []string{})
+	sort.Slice(leaves, func(i, j int) bool {
+		return strings.Join(leaves[i].Path, ".") < strings.Join(leaves[j].Path, ".")
+	})
+	return leaves
+}
+
+// collectErrorLeaves - recursively navigates error_info collecting its leaves. A map holding error_id or error_text is
+// a leaf, as is any value other than a map or a list.
+func collectErrorLeaves(value interface{}, path []string) []VTMErrorLeaf {
+	leafPath := append([]string{}, path...)
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		errorID, hasID := typed["error_id"].(string)
+		errorText, hasText := typed["error_text"].(string)
+		if hasID || hasText {
+			return []VTMErrorLeaf{{Path: leafPath, ErrorID: errorID, ErrorText: errorText}}
+		}
+		leaves := make([]VTMErrorLeaf, 0)
+		for key, child := range typed {
+			leaves = append(leaves, collectErrorLeaves(child, append(leafPath, key))...)
+		}
+		return leaves
+	case []interface{}:
+		leaves := make([]VTMErrorLeaf, 0)
+		for index, child := range typed {
+			leaves = append(leaves, collectErrorLeaves(child, append(leafPath, strconv.Itoa(index)))...)
+		}
+		return leaves
+	case nil:
+		return []VTMErrorLeaf{}
+	default:
+		return []VTMErrorLeaf{{Path: leafPath, ErrorText: fmt.Sprint(typed)}}
+	}
+}
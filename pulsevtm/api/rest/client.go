@@ -25,6 +25,11 @@ type Client struct {
 	Headers    map[string]string
 	Timeout    time.Duration // in seconds
 	StatusCode int
+	// TLSClientConfig, when set, is used in place of the configuration built from IgnoreSSL
+	TLSClientConfig *tls.Config
+	// Transport, when set, is used for every request so connections are kept alive and reused between them.
+	// Otherwise a new transport, configured from TLSClientConfig or IgnoreSSL, is built for each request.
+	Transport http.RoundTripper
 }
 
 func (restClient *Client) formatRequestPayload(api *BaseAPI) (io.Reader, error) {
@@ -105,11 +110,19 @@ func (restClient *Client) Do(api *BaseAPI) error {
 		req.Header.Set(headerKey, headerValue)
 	}
 
-	tr := &http.Transport{
-		TLSClientConfig:   &tls.Config{InsecureSkipVerify: restClient.IgnoreSSL},
-		MaxIdleConns:      10,
-		IdleConnTimeout:   30 * time.Second,
-		DisableKeepAlives: true,
+	tr := restClient.Transport
+	if tr == nil {
+		tlsConfig := restClient.TLSClientConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{InsecureSkipVerify: restClient.IgnoreSSL}
+		}
+
+		tr = &http.Transport{
+			TLSClientConfig:   tlsConfig,
+			MaxIdleConns:      10,
+			IdleConnTimeout:   30 * time.Second,
+			DisableKeepAlives: true,
+		}
 	}
 
 	httpClient := &http.Client{
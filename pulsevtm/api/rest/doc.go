@@ -0,0 +1,6 @@
+// Package rest is the provider's copy of github.com/sky-uk/go-rest-api 0.0.11, carrying the changes the provider
+// needs until they're released upstream: Client.TLSClientConfig and Client.Transport, so requests can be made with a
+// TLS configuration and a transport of the provider's choosing.
+//
+// It's covered by the BSD 3-Clause licence in LICENSE.
+package rest
@@ -0,0 +1,451 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"log"
+	"sort"
+	"strings"
+)
+
+// minimumAPIVersion : the oldest REST API version the provider's schemas can be used with
+const minimumAPIVersion = "3.8"
+
+// apiVersionName : the name the REST API gives a property of a resource from a version on
+type apiVersionName struct {
+	since string
+	// name is empty when the property is named as in the version the schemas are modelled on
+	name string
+	// missing is set when the property doesn't exist from the version on
+	missing bool
+}
+
+// introducedIn : the names of a property the REST API only has from a version on
+func introducedIn(apiVersion string) []apiVersionName {
+	return []apiVersionName{{since: minimumAPIVersion, missing: true}, {since: apiVersion}}
+}
+
+// renamedIn : the names of a property the REST API names differently before a version
+func renamedIn(apiVersion, before string) []apiVersionName {
+	return []apiVersionName{{since: minimumAPIVersion, name: before}, {since: apiVersion}}
+}
+
+// apiVersionResource : how the REST API versions name the properties of a resource type
+type apiVersionResource struct {
+	// basicKeys returns the attributes of the resource's schema held in the basic section of its properties, or is
+	// nil when the basic section is a block of the schema
+	basicKeys func() []string
+	// sectionName converts between the name of a block of the resource's schema and the section of its properties
+	sectionName func(string) string
+	// names holds the names of the properties which differ between versions, by their path in the properties, as
+	// the version the schemas are modelled on names them. The names of a property are listed from the minimum
+	// version on, and a property not listed has the same name in every version.
+	names map[string][]apiVersionName
+}
+
+// apiVersionResources : how the REST API versions name the properties of each resource type whose properties differ
+// between versions. The schemas are modelled on 5.x; versions later than that are taken to name properties as it does.
+var apiVersionResources = map[string]apiVersionResource{
+	"pulsevtm_global_settings": {
+		sectionName: globalSettingsSectionName,
+		names: map[string][]apiVersionName{
+			"admin.cipher_suites":             renamedIn("4.0", "ssl3_ciphers"),
+			"l4accel":                         introducedIn("4.0"),
+			"ssl.allow_rehandshake":           renamedIn("4.0", "ssl3_allow_rehandshake"),
+			"ssl.cipher_suites":               renamedIn("4.0", "ssl3_ciphers"),
+			"ssl.diffie_hellman_modulus_size": renamedIn("4.0", "ssl3_diffie_hellman_key_length"),
+			"ssl.min_rehandshake_interval":    renamedIn("4.0", "ssl3_min_rehandshake_interval"),
+			"transaction_export":              introducedIn("4.0"),
+		},
+	},
+	"pulsevtm_pool": {
+		basicKeys:   basicPoolKeys,
+		sectionName: poolSectionName,
+		names: map[string][]apiVersionName{
+			"l4accel":            introducedIn("4.0"),
+			"ssl.cipher_suites":  renamedIn("4.0", "ssl_ciphers"),
+			"ssl.support_ssl3":   renamedIn("4.0", "ssl_support_ssl3"),
+			"ssl.support_tls1":   renamedIn("4.0", "ssl_support_tls1"),
+			"ssl.support_tls1_1": renamedIn("4.0", "ssl_support_tls1_1"),
+			"ssl.support_tls1_2": renamedIn("4.0", "ssl_support_tls1_2"),
+		},
+	},
+	"pulsevtm_virtual_server": {
+		basicKeys:   basicVirtualServerKeys,
+		sectionName: sectionName,
+		names: map[string][]apiVersionName{
+			"auth":                                 introducedIn("4.0"),
+			"basic.bypass_data_plane_acceleration": introducedIn("4.0"),
+			"basic.proxy_protocol":                 introducedIn("4.0"),
+			"l4accel":                              introducedIn("4.0"),
+			"request_tracing":                      introducedIn("4.0"),
+			"ssl.cipher_suites":                    renamedIn("4.0", "ssl_ciphers"),
+			"ssl.support_ssl3":                     renamedIn("4.0", "ssl_support_ssl3"),
+			"ssl.support_tls1":                     renamedIn("4.0", "ssl_support_tls1"),
+			"ssl.support_tls1_1":                   renamedIn("4.0", "ssl_support_tls1_1"),
+			"ssl.support_tls1_2":                   renamedIn("4.0", "ssl_support_tls1_2"),
+			"transaction_export":                   introducedIn("4.0"),
+		},
+	},
+}
+
+// pulseVTMProvider : a schema.Provider which, when planning, checks the configuration of a resource against the
+// REST API version of the traffic manager it's connected to
+type pulseVTMProvider struct {
+	*schema.Provider
+}
+
+// Diff : fails the plan of a resource using attributes the connected REST API version doesn't support, or failing the
+// plan checks of its resource type, and makes the plan changes of its resource type
+func (p *pulseVTMProvider) Diff(info *terraform.InstanceInfo, s *terraform.InstanceState, c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+	err := checkPlan(info.Type, s, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range clusterMetas(p.Meta()) {
+		err := checkAPIVersionSupport(info.Type, c, meta)
+		if err != nil {
+			return nil, err
+		}
+		err = checkPlanOnTrafficManager(info.Type, c, meta)
+		if err != nil {
+			return nil, err
+		}
+	}
+	diff, err := p.Provider.Diff(info, s, c)
+	if err != nil {
+		return nil, err
+	}
+	return changePlan(info.Type, s, c, diff), nil
+}
+
+// Apply : applies the planned change of a resource to each cluster it's managed on, taking the safety backup first
+// when the provider is configured to take one. A change failing part way through the clusters isn't rolled back.
+func (p *pulseVTMProvider) Apply(info *terraform.InstanceInfo, s *terraform.InstanceState, d *terraform.InstanceDiff) (*terraform.InstanceState, error) {
+	resource, ok := p.ResourcesMap[info.Type]
+	if !ok {
+		return p.Provider.Apply(info, s, d)
+	}
+	return applyWithSafetyBackup(info.Type, s, p.Meta(), func() (*terraform.InstanceState, error) {
+		return applyToClusters(resource, s, d, p.Meta())
+	})
+}
+
+// parseAPIVersion : parses a REST API version so it can be compared semantically
+func parseAPIVersion(apiVersion string) (*version.Version, error) {
+	parsed, err := version.NewVersion(apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] PulseVTM REST API version %s is not in the format <major>.<minor>", apiVersion)
+	}
+	return parsed, nil
+}
+
+// validateAPIVersion : check the REST API version is one the provider's schemas can be used with, the minimum
+// version or any later one. The properties the versions name differently are converted using apiVersionResources.
+func validateAPIVersion(apiVersion string) error {
+	parsed, err := parseAPIVersion(apiVersion)
+	if err != nil {
+		return err
+	}
+	if parsed.LessThan(version.Must(version.NewVersion(minimumAPIVersion))) {
+		return fmt.Errorf("[ERROR] PulseVTM REST API version %s is not supported, the provider supports %s and later", apiVersion, minimumAPIVersion)
+	}
+	return nil
+}
+
+// validateAPIVersionConstraint : check the REST API version constraint can be parsed
+func validateAPIVersionConstraint(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) == "" {
+		return
+	}
+	_, err := version.NewConstraint(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q must be a version constraint such as \">= 4.0, < 7.0\": %v", k, err))
+	}
+	return
+}
+
+// listAPIVersions : returns the REST API versions a traffic manager offers, newest first. The client lists them when
+// it connects without a version.
+func listAPIVersions(params api.Params) ([]string, error) {
+	params.APIVersion = ""
+	client, err := api.Connect(params)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] PulseVTM error whilst listing the REST API versions offered by %s: %v", params.Server, err)
+	}
+	return client.VersionsSupported, nil
+}
+
+// selectAPIVersion : returns the highest of the offered REST API versions which satisfies the constraint and which
+// the provider supports. Versions are compared semantically, so 10.0 is higher than 5.1.
+func selectAPIVersion(offered []string, constraint string) (string, error) {
+	var constraints version.Constraints
+	if constraint != "" {
+		var err error
+		constraints, err = version.NewConstraint(constraint)
+		if err != nil {
+			return "", fmt.Errorf("[ERROR] PulseVTM REST API version constraint %q is invalid: %v", constraint, err)
+		}
+	}
+
+	candidates := make(version.Collection, 0)
+	names := make(map[*version.Version]string)
+	for _, name := range offered {
+		candidate, err := version.NewVersion(name)
+		if err != nil {
+			log.Printf("[DEBUG] PulseVTM ignoring REST API version %s: %v", name, err)
+			continue
+		}
+		if constraints != nil && !constraints.Check(candidate) {
+			continue
+		}
+		if validateAPIVersion(name) != nil {
+			continue
+		}
+		candidates = append(candidates, candidate)
+		names[candidate] = name
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("[ERROR] PulseVTM none of the REST API versions offered, %s, satisfy the constraint %q and are supported by the provider",
+			strings.Join(offered, ", "), constraint)
+	}
+
+	sort.Sort(candidates)
+	return names[candidates[len(candidates)-1]], nil
+}
+
+// negotiateAPIVersion : returns the highest REST API version offered by the traffic manager which satisfies the
+// constraint and which the provider supports
+func negotiateAPIVersion(params api.Params, constraint string) (string, error) {
+	offered, err := listAPIVersions(params)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[DEBUG] PulseVTM REST API versions offered by %s: %v", params.Server, offered)
+
+	selected, err := selectAPIVersion(offered, constraint)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[INFO] PulseVTM negotiated REST API version %s from %v with constraint %q", selected, offered, constraint)
+	return selected, nil
+}
+
+// connectedAPIVersion : returns the REST API version the provider is connected to, or false when it isn't known
+func connectedAPIVersion(m interface{}) (*version.Version, bool) {
+	config, ok := m.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	apiVersion, ok := config["apiVersion"].(string)
+	if !ok {
+		return nil, false
+	}
+	parsed, err := parseAPIVersion(apiVersion)
+	if err != nil {
+		return nil, false
+	}
+	return parsed, true
+}
+
+// apiVersionPropertyName : returns the name a REST API version gives a property, or false when the version doesn't
+// have it. name is the name of the property in the version the schemas are modelled on.
+func apiVersionPropertyName(apiVersion *version.Version, names []apiVersionName, name string) (string, bool) {
+	current := apiVersionName{}
+	for _, candidate := range names {
+		if apiVersion.LessThan(version.Must(version.NewVersion(candidate.since))) {
+			break
+		}
+		current = candidate
+	}
+	if current.missing {
+		return "", false
+	}
+	if current.name == "" {
+		return name, true
+	}
+	return current.name, true
+}
+
+// propertyPath : returns the path in the properties of a resource of an attribute of its schema
+func (r apiVersionResource) propertyPath(attribute string) string {
+	if r.basicKeys != nil {
+		for _, key := range r.basicKeys() {
+			if key == attribute {
+				return "basic." + attribute
+			}
+		}
+	}
+	return r.sectionName(attribute)
+}
+
+// schemaPath : returns the attribute of the schema of a resource holding a property, and the path to the property
+// within it
+func (r apiVersionResource) schemaPath(path string) (string, []string) {
+	parts := strings.Split(path, ".")
+	if r.basicKeys != nil && parts[0] == "basic" && len(parts) > 1 {
+		return parts[1], parts[2:]
+	}
+	return r.sectionName(parts[0]), parts[1:]
+}
+
+// isAttributeSupported : whether the REST API version the provider is connected to supports an attribute of a
+// resource type. Everything is supported when the version isn't known.
+func isAttributeSupported(m interface{}, resourceType, attribute string) bool {
+	apiVersion, ok := connectedAPIVersion(m)
+	if !ok {
+		return true
+	}
+	resource, ok := apiVersionResources[resourceType]
+	if !ok {
+		return true
+	}
+	_, ok = apiVersionPropertyName(apiVersion, resource.names[resource.propertyPath(attribute)], attribute)
+	return ok
+}
+
+// supportedAttributes : filters a list of attributes of a resource type down to those the connected REST API
+// version supports
+func supportedAttributes(m interface{}, resourceType string, attributes []string) []string {
+	supported := make([]string, 0)
+	for _, attribute := range attributes {
+		if isAttributeSupported(m, resourceType, attribute) {
+			supported = append(supported, attribute)
+		}
+	}
+	return supported
+}
+
+// apiVersionPropertyPaths : returns the paths of the properties of a resource type which differ between versions,
+// parents before the properties within them
+func apiVersionPropertyPaths(resource apiVersionResource) []string {
+	paths := make([]string, 0)
+	for path := range resource.names {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		depthI, depthJ := strings.Count(paths[i], "."), strings.Count(paths[j], ".")
+		if depthI != depthJ {
+			return depthI < depthJ
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// renameProperty : renames a property within the properties, or sections and tables of them, found at the path of
+// its parents. The property is removed when it's renamed to nothing.
+func renameProperty(value interface{}, parents []string, from, to string) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if len(parents) > 0 {
+			renameProperty(typed[parents[0]], parents[1:], from, to)
+			return
+		}
+		property, ok := typed[from]
+		if !ok {
+			return
+		}
+		delete(typed, from)
+		if to != "" {
+			typed[to] = property
+		}
+	case []interface{}:
+		for _, element := range typed {
+			renameProperty(element, parents, from, to)
+		}
+	}
+}
+
+// toAPIVersionProperties : renames the properties of a resource, named as the version the schemas are modelled on
+// names them, as the connected REST API version names them, and removes those the version doesn't have
+func toAPIVersionProperties(m interface{}, resourceType string, properties map[string]interface{}) {
+	apiVersion, ok := connectedAPIVersion(m)
+	if !ok {
+		return
+	}
+	resource := apiVersionResources[resourceType]
+	paths := apiVersionPropertyPaths(resource)
+	// Properties are renamed before their parents, whilst the path to them is still named as in the schemas
+	for i := len(paths) - 1; i >= 0; i-- {
+		parts := strings.Split(paths[i], ".")
+		name := parts[len(parts)-1]
+		apiName, _ := apiVersionPropertyName(apiVersion, resource.names[paths[i]], name)
+		if apiName != name {
+			renameProperty(properties, parts[:len(parts)-1], name, apiName)
+		}
+	}
+}
+
+// fromAPIVersionProperties : renames the properties of a resource read from the connected REST API version as the
+// version the schemas are modelled on names them
+func fromAPIVersionProperties(m interface{}, resourceType string, properties map[string]interface{}) {
+	apiVersion, ok := connectedAPIVersion(m)
+	if !ok {
+		return
+	}
+	resource := apiVersionResources[resourceType]
+	// Parents are renamed before the properties within them, so the path to them is named as in the schemas
+	for _, path := range apiVersionPropertyPaths(resource) {
+		parts := strings.Split(path, ".")
+		name := parts[len(parts)-1]
+		apiName, ok := apiVersionPropertyName(apiVersion, resource.names[path], name)
+		if ok && apiName != name {
+			renameProperty(properties, parts[:len(parts)-1], apiName, name)
+		}
+	}
+}
+
+// isConfigured : whether the configuration of a resource sets the property at the path within an attribute, in any
+// element of the blocks and tables along the path
+func isConfigured(c *terraform.ResourceConfig, key string, path []string) bool {
+	if len(path) == 0 {
+		return c.IsSet(key)
+	}
+	value, ok := c.Get(key)
+	if !ok {
+		return false
+	}
+	elements, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for i := range elements {
+		if isConfigured(c, fmt.Sprintf("%s.%d.%s", key, i, path[0]), path[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAPIVersionSupport : returns an error naming every attribute set in the configuration of a resource which the
+// connected REST API version doesn't support, including those within blocks
+func checkAPIVersionSupport(resourceType string, c *terraform.ResourceConfig, m interface{}) error {
+	if c == nil {
+		return nil
+	}
+	apiVersion, ok := connectedAPIVersion(m)
+	if !ok {
+		return nil
+	}
+	resource := apiVersionResources[resourceType]
+	unsupported := make([]string, 0)
+	for path, names := range resource.names {
+		if _, ok := apiVersionPropertyName(apiVersion, names, ""); ok {
+			continue
+		}
+		attribute, within := resource.schemaPath(path)
+		if isConfigured(c, attribute, within) {
+			unsupported = append(unsupported, strings.Join(append([]string{attribute}, within...), "."))
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	sort.Strings(unsupported)
+	return fmt.Errorf("[ERROR] %s attributes %s are not supported by PulseVTM REST API version %s",
+		resourceType, strings.Join(unsupported, ", "), m.(map[string]interface{})["apiVersion"])
+}
@@ -0,0 +1,343 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func testResourceConfig(t *testing.T, raw map[string]interface{}) *terraform.ResourceConfig {
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("unexpected error building configuration: %v", err)
+	}
+	return terraform.NewResourceConfig(rawConfig)
+}
+
+// testConfiguredProvider : returns a provider configured against the test server
+func testConfiguredProvider(t *testing.T, server *testVTMServer, apiVersion string) (*pulseVTMProvider, error) {
+	provider := Provider().(*pulseVTMProvider)
+	providerConfig := map[string]interface{}{
+		"vtm_user":             "admin",
+		"vtm_password":         "password",
+		"vtm_server":           server.URL,
+		"allow_unverified_ssl": true,
+	}
+	if apiVersion != "" {
+		providerConfig["api_version"] = apiVersion
+	}
+	return provider, provider.Configure(testResourceConfig(t, providerConfig))
+}
+
+func TestProviderDetectsAPIVersion(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.setAPIVersions("3.8", "5.1", "10.0", "9.1")
+
+	provider, err := testConfiguredProvider(t, server, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiVersion := provider.Meta().(map[string]interface{})["apiVersion"]; apiVersion != "10.0" {
+		t.Errorf("expected API version 10.0 to be detected, got %v", apiVersion)
+	}
+}
+
+func TestListAPIVersionsNewestFirst(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.setAPIVersions("3.8", "10.0", "5.1", "9.1")
+
+	versions, err := listAPIVersions(api.Params{Server: server.URL, Username: "admin", Password: "password", IgnoreSSL: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(versions) != "[10.0 9.1 5.1 3.8]" {
+		t.Errorf("expected the versions newest first, got %v", versions)
+	}
+}
+
+func TestProviderRejectsUnsupportedAPIVersion(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+
+	_, err := testConfiguredProvider(t, server, "3.5")
+	if err == nil {
+		t.Fatalf("expected an error configuring API version 3.5")
+	}
+	if !regexp.MustCompile(`version 3.5 is not supported, the provider supports 3.8 and later`).MatchString(err.Error()) {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
+func TestProviderPlanFailsForAttributesUnsupportedByAPIVersion(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.setAPIVersions("3.8")
+
+	provider, err := testConfiguredProvider(t, server, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info := &terraform.InstanceInfo{Type: "pulsevtm_virtual_server"}
+
+	_, err = provider.Diff(info, nil, testResourceConfig(t, map[string]interface{}{
+		"name":           "web",
+		"pool":           "web-pool",
+		"port":           80,
+		"proxy_protocol": true,
+		"l4accel": []interface{}{
+			map[string]interface{}{"rst_on_service_failure": true},
+		},
+	}))
+	if err == nil {
+		t.Fatalf("expected the plan to fail for attributes unsupported by API version 3.8")
+	}
+	if !regexp.MustCompile(`pulsevtm_virtual_server attributes l4accel, proxy_protocol are not supported by PulseVTM REST API version 3.8`).MatchString(err.Error()) {
+		t.Errorf("unexpected error message: %v", err)
+	}
+
+	diff, err := provider.Diff(info, nil, testResourceConfig(t, map[string]interface{}{
+		"name": "web",
+		"pool": "web-pool",
+		"port": 80,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff == nil || len(diff.Attributes) == 0 {
+		t.Errorf("expected a diff for a new virtual server")
+	}
+}
+
+func TestSupportedAttributesForAPIVersion(t *testing.T) {
+	keys := []string{"pool", "proxy_protocol", "bypass_data_plane_acceleration", "port"}
+
+	supported := supportedAttributes(map[string]interface{}{"apiVersion": "3.8"}, "pulsevtm_virtual_server", keys)
+	if len(supported) != 2 || supported[0] != "pool" || supported[1] != "port" {
+		t.Errorf("expected only pool and port to be supported by 3.8, got %v", supported)
+	}
+
+	for _, apiVersion := range []string{"4.0", "5.1", "6.0", "10.0"} {
+		supported = supportedAttributes(map[string]interface{}{"apiVersion": apiVersion}, "pulsevtm_virtual_server", keys)
+		if len(supported) != len(keys) {
+			t.Errorf("expected every attribute to be supported by %s, got %v", apiVersion, supported)
+		}
+	}
+}
+
+func TestAPIVersionPropertiesAreNamedAsInTheSchema(t *testing.T) {
+	resources := Provider().(*pulseVTMProvider).ResourcesMap
+	for resourceType, apiVersionResource := range apiVersionResources {
+		resource, ok := resources[resourceType]
+		if !ok {
+			t.Errorf("%s isn't a resource of the provider", resourceType)
+			continue
+		}
+		for path, names := range apiVersionResource.names {
+			attribute, within := apiVersionResource.schemaPath(path)
+			attributeSchema, ok := resource.Schema[attribute]
+			for _, name := range within {
+				if !ok {
+					break
+				}
+				elem, isResource := attributeSchema.Elem.(*schema.Resource)
+				if !isResource {
+					ok = false
+					break
+				}
+				attributeSchema, ok = elem.Schema[name]
+			}
+			if !ok {
+				t.Errorf("%s has no attribute for property %s", resourceType, path)
+			}
+			if len(names) == 0 || names[0].since != minimumAPIVersion {
+				t.Errorf("%s property %s: expected names from version %s on, got %v", resourceType, path, minimumAPIVersion, names)
+			}
+			for _, name := range names {
+				if err := validateAPIVersion(name.since); err != nil {
+					t.Errorf("%s property %s: %v", resourceType, path, err)
+				}
+			}
+		}
+	}
+}
+
+func TestVirtualServerPropertiesNamedByAPIVersion(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	m["apiVersion"] = "3.8"
+	virtualServer := resourceVirtualServer()
+	// The virtual server as 3.8 reads it, without the sections introduced later
+	properties := map[string]interface{}{"basic": map[string]interface{}{}, "aptimizer": map[string]interface{}{"profile": []interface{}{}}}
+	for _, section := range supportedAttributes(m, "pulsevtm_virtual_server", virtualServerSectionNames()) {
+		if properties[sectionName(section)] == nil {
+			properties[sectionName(section)] = map[string]interface{}{}
+		}
+	}
+	server.setResource("virtual_servers/web", map[string]interface{}{"properties": properties})
+
+	d := schema.TestResourceDataRaw(t, virtualServer.Schema, map[string]interface{}{
+		"name": "web",
+		"pool": "web-pool",
+		"port": 443,
+		"ssl": []interface{}{
+			map[string]interface{}{"cipher_suites": "SSL_RSA_WITH_AES_128_CBC_SHA", "support_tls1_2": "enabled"},
+		},
+	})
+	err := virtualServer.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	properties = server.getResource("virtual_servers/web")["properties"].(map[string]interface{})
+	ssl := properties["ssl"].(map[string]interface{})
+	if ssl["ssl_ciphers"] != "SSL_RSA_WITH_AES_128_CBC_SHA" || ssl["ssl_support_tls1_2"] != "enabled" {
+		t.Errorf("expected the SSL properties to be named as 3.8 names them, got %v", ssl)
+	}
+	if _, ok := ssl["cipher_suites"]; ok {
+		t.Errorf("expected cipher_suites not to be sent to 3.8, got %v", ssl)
+	}
+	for _, section := range []string{"auth", "l4accel", "request_tracing", "transaction_export"} {
+		if _, ok := properties[section]; ok {
+			t.Errorf("expected the %s section not to be sent to 3.8", section)
+		}
+	}
+	basic := properties["basic"].(map[string]interface{})
+	if _, ok := basic["proxy_protocol"]; ok {
+		t.Errorf("expected proxy_protocol not to be sent to 3.8, got %v", basic)
+	}
+
+	if d.Get("ssl.0.cipher_suites") != "SSL_RSA_WITH_AES_128_CBC_SHA" || d.Get("ssl.0.support_tls1_2") != "enabled" {
+		t.Errorf("expected the SSL properties read from 3.8 to be named as in the schema, got %v", d.Get("ssl"))
+	}
+}
+
+func TestPoolReadLeavesOutSectionsUnsupportedByAPIVersion(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	properties := map[string]interface{}{"basic": map[string]interface{}{"nodes_table": []interface{}{}}}
+	for _, section := range poolSectionNames() {
+		properties[poolSectionName(section)] = map[string]interface{}{}
+	}
+	delete(properties, "l4accel")
+	server.setResource("pools/web", map[string]interface{}{"properties": properties})
+	m := server.meta(t)
+	m["apiVersion"] = "3.8"
+
+	d := schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{"name": "web"})
+	d.SetId("web")
+	err := resourcePoolRead(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := d.GetOk("pool_connection"); !ok {
+		t.Errorf("expected the pool_connection section to be read")
+	}
+}
+
+func TestSelectAPIVersion(t *testing.T) {
+	offered := []string{"3.5", "3.8", "4.0", "5.1", "5.2", "6.0", "10.0"}
+	cases := []struct {
+		Constraint string
+		Expected   string
+	}{
+		{"", "10.0"},
+		{">= 4.0, < 7.0", "6.0"},
+		{">= 4.0, < 6.0", "5.2"},
+		{"< 5.0", "4.0"},
+		{"~> 3.8", "3.8"},
+	}
+	for _, tc := range cases {
+		selected, err := selectAPIVersion(offered, tc.Constraint)
+		if err != nil {
+			t.Errorf("constraint %q: unexpected error: %v", tc.Constraint, err)
+			continue
+		}
+		if selected != tc.Expected {
+			t.Errorf("constraint %q: expected version %s, got %s", tc.Constraint, tc.Expected, selected)
+		}
+	}
+
+	// Compared as strings 5.9 would be chosen
+	selected, err := selectAPIVersion([]string{"5.9", "5.10"}, "")
+	if err != nil || selected != "5.10" {
+		t.Errorf("expected version 5.10 to be selected, got %s: %v", selected, err)
+	}
+
+	_, err = selectAPIVersion(offered, "< 3.8")
+	if err == nil {
+		t.Errorf("expected an error when no supported version satisfies the constraint")
+	}
+}
+
+func TestProviderNegotiatesAPIVersionWithinConstraint(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.setAPIVersions("10.0", "3.8", "5.1", "5.2", "6.1")
+
+	provider := Provider().(*pulseVTMProvider)
+	err := provider.Configure(testResourceConfig(t, map[string]interface{}{
+		"vtm_user":               "admin",
+		"vtm_password":           "password",
+		"vtm_server":             server.URL,
+		"allow_unverified_ssl":   true,
+		"api_version_constraint": ">= 4.0, < 7.0",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiVersion := provider.Meta().(map[string]interface{})["apiVersion"]; apiVersion != "6.1" {
+		t.Errorf("expected API version 6.1 to be negotiated, got %v", apiVersion)
+	}
+}
+
+func TestAPIVersionDataSource(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.setAPIVersions("3.8", "5.1", "6.0")
+
+	provider, err := testConfiguredProvider(t, server, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dataSource := provider.DataSourcesMap["pulsevtm_api_version"]
+	d := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{})
+	err = dataSource.Read(d, provider.Meta())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("api_version") != "6.0" || d.Get("clusters.#") != 0 {
+		t.Errorf("expected the negotiated API version 6.0 and no clusters, got %v and %v", d.Get("api_version"), d.Get("clusters"))
+	}
+}
+
+func TestAPIVersionDataSourceWithClusters(t *testing.T) {
+	servers := map[string]*testVTMServer{"primary": newTestVTMServer(t), "secondary": newTestVTMServer(t)}
+	for _, server := range servers {
+		defer server.Close()
+	}
+	provider := testClustersProvider(t, servers)
+
+	dataSource := provider.DataSourcesMap["pulsevtm_api_version"]
+	d := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{})
+	err := dataSource.Read(d, provider.Meta())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("api_version") != "5.1" || d.Get("clusters.#") != 2 {
+		t.Fatalf("expected API version 5.1 on two clusters, got %v and %v", d.Get("api_version"), d.Get("clusters"))
+	}
+	for i, name := range []string{"primary", "secondary"} {
+		prefix := fmt.Sprintf("clusters.%d.", i)
+		if d.Get(prefix+"name") != name || d.Get(prefix+"api_version") != "5.1" {
+			t.Errorf("expected cluster %s to use API version 5.1, got %v", name, d.Get("clusters"))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceAPIVersion() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAPIVersionRead,
+
+		Schema: map[string]*schema.Schema{
+			"api_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The REST API version in use, on the first cluster when clusters are configured",
+			},
+			"clusters": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The REST API version in use on each cluster, when clusters are configured",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"api_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAPIVersionRead(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+
+	err := d.Set("api_version", config["apiVersion"])
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst setting attribute api_version: %v", err)
+	}
+	clusterVersions := make([]map[string]interface{}, 0)
+	if clusters, ok := config["clusters"].([]*vtmCluster); ok {
+		for _, cluster := range clusters {
+			clusterVersions = append(clusterVersions, map[string]interface{}{
+				"name":        cluster.name,
+				"api_version": cluster.meta["apiVersion"],
+			})
+		}
+	}
+	err = d.Set("clusters", clusterVersions)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst setting attribute clusters: %v", err)
+	}
+	d.SetId(fmt.Sprintf("%v", config["server"]))
+	return nil
+}
@@ -0,0 +1,96 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"sort"
+)
+
+func dataSourceBackups() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBackupsRead,
+
+		Schema: map[string]*schema.Schema{
+			"traffic_manager": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "local_tm",
+				Description: "The traffic manager to list the backups of",
+			},
+			"backups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The full backups on the traffic manager, oldest first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"time_stamp": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// listBackups : returns the name and metadata of each full backup on a traffic manager, oldest first. client must be
+// the status client, as backups are status resources.
+func listBackups(client *api.Client, trafficManager string) ([]map[string]interface{}, error) {
+	children, err := client.GetAllBackups(trafficManager)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] PulseVTM error whilst listing backups on %s: %v", trafficManager, err)
+	}
+	backups := make([]map[string]interface{}, 0)
+	for _, child := range children {
+		name, ok := child["name"].(string)
+		if !ok {
+			continue
+		}
+		backup, err := client.GetBackup(trafficManager, name)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] PulseVTM error whilst retrieving backup %s on %s: %v", name, trafficManager, err)
+		}
+		properties := backupProperties(backup)
+		properties["name"] = name
+		backups = append(backups, properties)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i]["time_stamp"].(int) < backups[j]["time_stamp"].(int)
+	})
+	return backups, nil
+}
+
+func dataSourceBackupsRead(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["statusClient"].(*api.Client)
+
+	trafficManager := d.Get("traffic_manager").(string)
+	backups, err := listBackups(client, trafficManager)
+	if err != nil {
+		return err
+	}
+	err = d.Set("backups", backups)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst setting attribute backups: %v", err)
+	}
+	d.SetId(trafficManager)
+	return nil
+}
@@ -3,7 +3,7 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"net/http"
 )
 
@@ -0,0 +1,79 @@
+package pulsevtm
+
+import (
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
+	"strings"
+)
+
+// planChecks : checks of the configuration of a resource type, taken along with its state, which span several
+// attributes and so can't be made by the attributes' own validation
+var planChecks = map[string]func(*terraform.InstanceState, *terraform.ResourceConfig) error{
+	"pulsevtm_dns_record":     checkDNSRecordConfig,
+	"pulsevtm_ssl_cas_file":   checkSSLCasConfig,
+	"pulsevtm_ssl_client_key": util.CheckSSLKeyConfig,
+	"pulsevtm_ssl_server_key": util.CheckSSLKeyConfig,
+	"pulsevtm_ssl_ticket_key": checkSSLTicketKeyConfig,
+}
+
+// planTrafficManagerChecks : checks of the configuration of a resource type against the configuration of the traffic
+// manager it's planned for
+var planTrafficManagerChecks = map[string]func(*terraform.ResourceConfig, interface{}) error{
+	"pulsevtm_dns_zone": checkDNSZoneOrigin,
+}
+
+// planChanges : changes made to the plan of a resource type beyond those following from its configuration, such as
+// those due with the passing of time
+var planChanges = map[string]func(*terraform.InstanceState, *terraform.ResourceConfig, *terraform.InstanceDiff) *terraform.InstanceDiff{
+	"pulsevtm_ssl_ticket_key": planTicketKeyRotation,
+}
+
+// checkPlan : returns an error when the configuration of a resource fails the checks of its resource type
+func checkPlan(resourceType string, s *terraform.InstanceState, c *terraform.ResourceConfig) error {
+	check, ok := planChecks[resourceType]
+	if !ok || c == nil {
+		return nil
+	}
+	return check(s, c)
+}
+
+// changePlan : returns the plan of a resource with the changes of its resource type made to it
+func changePlan(resourceType string, s *terraform.InstanceState, c *terraform.ResourceConfig, diff *terraform.InstanceDiff) *terraform.InstanceDiff {
+	change, ok := planChanges[resourceType]
+	if !ok || c == nil {
+		return diff
+	}
+	return change(s, c, diff)
+}
+
+// checkPlanOnTrafficManager : returns an error when the configuration of a resource fails the checks of its resource
+// type against the configuration of a traffic manager
+func checkPlanOnTrafficManager(resourceType string, c *terraform.ResourceConfig, m interface{}) error {
+	check, ok := planTrafficManagerChecks[resourceType]
+	if !ok || c == nil {
+		return nil
+	}
+	return check(c, m)
+}
+
+// plannedString : returns the value of a string attribute in the configuration of a resource, and whether it's known
+// when planning
+func plannedString(c *terraform.ResourceConfig, attribute string) (string, bool) {
+	if c.IsComputed(attribute) {
+		return "", false
+	}
+	v, ok := c.Get(attribute)
+	if !ok {
+		return "", false
+	}
+	value, ok := v.(string)
+	return value, ok && !strings.Contains(value, config.UnknownVariableValue)
+}
+
+// plannedReference : whether an attribute in the configuration of a resource refers to a resource of a type, which
+// Terraform then plans before it
+func plannedReference(c *terraform.ResourceConfig, attribute, resourceType string) bool {
+	raw, ok := c.Raw[attribute].(string)
+	return ok && strings.Contains(raw, "${"+resourceType+".")
+}
@@ -0,0 +1,127 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"log"
+	"net/http"
+	"time"
+)
+
+// poolHealthPollInterval is how often the statistics of a pool's nodes are checked whilst waiting for it to become healthy
+var poolHealthPollInterval = 5 * time.Second
+
+// waitForHealthySchema : returns the schema of the optional block used to wait for a pool to become healthy after an apply
+func waitForHealthySchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"min_healthy_nodes": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validatePositiveInteger,
+					Description:  "The minimum number of nodes which must be passing their monitors",
+				},
+				"timeout": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      300,
+					ValidateFunc: validatePositiveInteger,
+					Description:  "The maximum time, in seconds, to wait for the nodes to become healthy before failing the apply",
+				},
+			},
+		},
+	}
+}
+
+// getPoolNodeStatistics : returns the statistics of a node of a pool on the local traffic manager, read with the
+// status client
+func getPoolNodeStatistics(statusClient *api.Client, pool, node string) (map[string]interface{}, error) {
+	statistics := make(map[string]interface{})
+	err := statusClient.GetByURL(statusClient.RootPath+"/local_tm/statistics/nodes/per_pool_node/"+pool+"-"+node, &statistics)
+	if err != nil {
+		return nil, err
+	}
+	nodeStatistics, ok := statistics["statistics"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[ERROR] PulseVTM unexpected statistics format for node %s in pool %s", node, pool)
+	}
+	return nodeStatistics, nil
+}
+
+// countHealthyPoolNodes : returns the number of active nodes of a pool which the local traffic manager sees as alive
+func countHealthyPoolNodes(client, statusClient *api.Client, pool string) (int, error) {
+	nodesTable, err := getPoolNodesTable(client, pool)
+	if err != nil {
+		return 0, err
+	}
+
+	healthyNodes := 0
+	for _, item := range nodesTable {
+		entry := item.(map[string]interface{})
+		if state, ok := entry["state"]; ok && state != "active" {
+			continue
+		}
+		node := entry["node"].(string)
+		nodeStatistics, err := getPoolNodeStatistics(statusClient, pool, node)
+		if err != nil {
+			if statusClient.StatusCode == http.StatusNotFound {
+				// The node hasn't been monitored yet
+				continue
+			}
+			return 0, err
+		}
+		if nodeStatistics["state"] == "alive" {
+			healthyNodes++
+		}
+	}
+	return healthyNodes, nil
+}
+
+// waitForHealthyPool : waits for the number of healthy nodes of a pool to reach the minimum configured in the
+// wait_for_healthy block, returning an error if that doesn't happen within the configured timeout
+func waitForHealthyPool(client, statusClient *api.Client, pool string, d *schema.ResourceData) error {
+	waitForHealthy := d.Get("wait_for_healthy").([]interface{})
+	if len(waitForHealthy) == 0 || waitForHealthy[0] == nil {
+		return nil
+	}
+	waitForHealthyConfig := waitForHealthy[0].(map[string]interface{})
+	minHealthyNodes := waitForHealthyConfig["min_healthy_nodes"].(int)
+	timeout := time.Duration(waitForHealthyConfig["timeout"].(int)) * time.Second
+
+	log.Printf("[INFO] PulseVTM waiting up to %s for pool %s to have %d healthy nodes", timeout, pool, minHealthyNodes)
+	healthyNodes := 0
+	stateConf := &resource.StateChangeConf{
+		Pending:      []string{"unhealthy"},
+		Target:       []string{"healthy"},
+		Timeout:      timeout,
+		PollInterval: poolHealthPollInterval,
+		Refresh: func() (interface{}, string, error) {
+			var err error
+			healthyNodes, err = countHealthyPoolNodes(client, statusClient, pool)
+			if err != nil {
+				return nil, "", err
+			}
+			if healthyNodes < minHealthyNodes {
+				log.Printf("[DEBUG] PulseVTM pool %s has %d of %d required healthy nodes", pool, healthyNodes, minHealthyNodes)
+				return healthyNodes, "unhealthy", nil
+			}
+			return healthyNodes, "healthy", nil
+		},
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		if _, ok := err.(*resource.TimeoutError); ok {
+			return fmt.Errorf("[ERROR] PulseVTM pool %s had %d healthy nodes after %s, %d are required", pool, healthyNodes, timeout, minHealthyNodes)
+		}
+		return fmt.Errorf("[ERROR] PulseVTM error whilst waiting for pool %s to become healthy: %v", pool, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func testPoolHealthServer(t *testing.T, nodeStates map[string]func() string) *testVTMServer {
+	server := newTestVTMServer(t)
+	server.setResource("pools/web", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{
+				"nodes_table": []interface{}{
+					map[string]interface{}{"node": "10.0.0.1:80", "priority": 1, "state": "active", "weight": 1},
+					map[string]interface{}{"node": "10.0.0.2:80", "priority": 1, "state": "active", "weight": 1},
+					map[string]interface{}{"node": "10.0.0.3:80", "priority": 1, "state": "disabled", "weight": 1},
+				},
+			},
+		},
+	})
+	for node, state := range nodeStates {
+		state := state
+		server.handlers["/api/tm/5.1/status/local_tm/statistics/nodes/per_pool_node/web-"+node] = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"statistics":{"current_conn":0,"state":"%s"}}`, state())
+		}
+	}
+	return server
+}
+
+func testPoolHealthResourceData(t *testing.T, minHealthyNodes, timeout int) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{
+		"name": "web",
+		"wait_for_healthy": []interface{}{
+			map[string]interface{}{
+				"min_healthy_nodes": minHealthyNodes,
+				"timeout":           timeout,
+			},
+		},
+	})
+}
+
+func TestCountHealthyPoolNodesIgnoresDeadAndInactiveNodes(t *testing.T) {
+	server := testPoolHealthServer(t, map[string]func() string{
+		"10.0.0.1:80": func() string { return "alive" },
+		"10.0.0.2:80": func() string { return "dead" },
+		"10.0.0.3:80": func() string { return "alive" },
+	})
+	defer server.Close()
+
+	m := server.meta(t)
+	healthyNodes, err := countHealthyPoolNodes(m["jsonClient"].(*api.Client), m["statusClient"].(*api.Client), "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if healthyNodes != 1 {
+		t.Errorf("expected 1 healthy node, got %d", healthyNodes)
+	}
+}
+
+func TestWaitForHealthyPoolWaitsForNodesToComeAlive(t *testing.T) {
+	defer func(interval time.Duration) { poolHealthPollInterval = interval }(poolHealthPollInterval)
+	poolHealthPollInterval = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	polls := 0
+	server := testPoolHealthServer(t, map[string]func() string{
+		"10.0.0.1:80": func() string { return "alive" },
+		"10.0.0.2:80": func() string {
+			mu.Lock()
+			defer mu.Unlock()
+			polls++
+			if polls < 3 {
+				return "unknown"
+			}
+			return "alive"
+		},
+	})
+	defer server.Close()
+
+	m := server.meta(t)
+	err := waitForHealthyPool(m["jsonClient"].(*api.Client), m["statusClient"].(*api.Client), "web", testPoolHealthResourceData(t, 2, 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if polls != 3 {
+		t.Errorf("expected the pool to be polled until both nodes were alive, polled %d times", polls)
+	}
+}
+
+func TestWaitForHealthyPoolFailsAfterTimeout(t *testing.T) {
+	defer func(interval time.Duration) { poolHealthPollInterval = interval }(poolHealthPollInterval)
+	poolHealthPollInterval = 100 * time.Millisecond
+
+	server := testPoolHealthServer(t, map[string]func() string{
+		"10.0.0.1:80": func() string { return "alive" },
+		"10.0.0.2:80": func() string { return "dead" },
+	})
+	defer server.Close()
+
+	m := server.meta(t)
+	err := waitForHealthyPool(m["jsonClient"].(*api.Client), m["statusClient"].(*api.Client), "web", testPoolHealthResourceData(t, 2, 1))
+	if err == nil {
+		t.Fatalf("expected an error when the pool never becomes healthy")
+	}
+	if !regexp.MustCompile(`pool web had 1 healthy nodes after 1s, 2 are required`).MatchString(err.Error()) {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
+func TestWaitForHealthyPoolIsANoOpWhenNotConfigured(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{
+		"name": "web",
+	})
+	err := waitForHealthyPool(nil, nil, "web", d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPoolNodeStatisticsLeaveTheConfigurationClientAlone(t *testing.T) {
+	server := testPoolHealthServer(t, nil)
+	defer server.Close()
+	m := server.meta(t)
+	client := m["jsonClient"].(*api.Client)
+	client.WorkWithConfigurationResources()
+
+	// Other resources keep reading configuration with the JSON client whilst statistics are being polled
+	var configurationPools error
+	server.handlers[testVTMStatusPath+"local_tm/statistics/nodes/per_pool_node/web-10.0.0.1:80"] = func(w http.ResponseWriter, r *http.Request) {
+		configurationPools = client.GetByName("pools", "web", new(map[string]interface{}))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"statistics":{"current_conn":0,"state":"alive"}}`)
+	}
+	_, err := getPoolNodeStatistics(m["statusClient"].(*api.Client), "web", "10.0.0.1:80")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if configurationPools != nil {
+		t.Errorf("expected configuration to be readable whilst statistics are read, got %v", configurationPools)
+	}
+	if client.RootPath != "/api/tm/5.1/config/active" {
+		t.Errorf("expected the JSON client to be left working with configuration resources, got %s", client.RootPath)
+	}
+}
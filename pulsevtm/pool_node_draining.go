@@ -0,0 +1,172 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"log"
+	"net/http"
+	"time"
+)
+
+// poolNodeDrainPollInterval is how often the statistics of draining nodes are checked
+var poolNodeDrainPollInterval = 5 * time.Second
+
+// poolNodeDrainingSchema : returns the attributes used to opt in to draining nodes before they're removed from a pool
+func poolNodeDrainingSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"drain_on_removal": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Whether a node should be set to draining, and its active connections allowed to finish, before it's removed from the pool",
+		},
+		"drain_timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      300,
+			ValidateFunc: validatePositiveInteger,
+			Description:  "The maximum time, in seconds, to wait for a draining node's active connections to finish before removing it anyway",
+		},
+	}
+}
+
+// validatePositiveInteger : check integer is greater than zero
+func validatePositiveInteger(v interface{}, k string) (ws []string, errors []error) {
+	if v.(int) < 1 {
+		errors = append(errors, fmt.Errorf("[ERROR] %q must be greater than zero", k))
+	}
+	return
+}
+
+// getPoolNodeConnections : returns the number of active connections to a node of a pool on the local traffic manager
+func getPoolNodeConnections(statusClient *api.Client, pool, node string) (int, error) {
+	nodeStatistics, err := getPoolNodeStatistics(statusClient, pool, node)
+	if err != nil {
+		if statusClient.StatusCode == http.StatusNotFound {
+			// No statistics are held for a node which has never handled a connection
+			return 0, nil
+		}
+		return 0, err
+	}
+	currentConnections, ok := nodeStatistics["current_conn"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("[ERROR] PulseVTM statistics for node %s in pool %s don't include current_conn", node, pool)
+	}
+	return int(currentConnections), nil
+}
+
+// drainPoolNodes : sets the given nodes of a pool to draining and waits for their active connections to reach zero.
+// Once the timeout has passed the nodes are left draining and the caller is free to remove them.
+func drainPoolNodes(client, statusClient *api.Client, pool string, nodes []string, timeout time.Duration) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	draining := make(map[string]bool)
+	for _, node := range nodes {
+		draining[node] = true
+	}
+
+	modify := func(nodesTable []interface{}) []interface{} {
+		updatedNodesTable := make([]interface{}, 0)
+		for _, item := range nodesTable {
+			entry := item.(map[string]interface{})
+			if draining[entry["node"].(string)] {
+				updatedEntry := make(map[string]interface{})
+				for key, value := range entry {
+					updatedEntry[key] = value
+				}
+				updatedEntry["state"] = "draining"
+				entry = updatedEntry
+			}
+			updatedNodesTable = append(updatedNodesTable, entry)
+		}
+		return updatedNodesTable
+	}
+	applied := func(nodesTable []interface{}) bool {
+		for _, item := range nodesTable {
+			entry := item.(map[string]interface{})
+			if draining[entry["node"].(string)] && entry["state"] != "draining" {
+				return false
+			}
+		}
+		return true
+	}
+
+	err := modifyPoolNodesTable(client, pool, modify, applied)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM Pool error whilst setting nodes %v of pool %s to draining: %v", nodes, pool, err)
+	}
+
+	log.Printf("[INFO] PulseVTM waiting up to %s for nodes %v of pool %s to drain", timeout, nodes, pool)
+	stateConf := &resource.StateChangeConf{
+		Pending:      []string{"draining"},
+		Target:       []string{"drained"},
+		Timeout:      timeout,
+		PollInterval: poolNodeDrainPollInterval,
+		Refresh: func() (interface{}, string, error) {
+			for _, node := range nodes {
+				connections, err := getPoolNodeConnections(statusClient, pool, node)
+				if err != nil {
+					return nil, "", err
+				}
+				if connections > 0 {
+					log.Printf("[DEBUG] PulseVTM node %s of pool %s still has %d active connections", node, pool, connections)
+					return connections, "draining", nil
+				}
+			}
+			return 0, "drained", nil
+		},
+	}
+	_, err = stateConf.WaitForState()
+	if err != nil {
+		if _, ok := err.(*resource.TimeoutError); ok {
+			log.Printf("[WARN] PulseVTM nodes %v of pool %s didn't drain within %s, removing them anyway", nodes, pool, timeout)
+			return nil
+		}
+		return fmt.Errorf("[ERROR] PulseVTM Pool error whilst waiting for nodes %v of pool %s to drain: %v", nodes, pool, err)
+	}
+	return nil
+}
+
+// poolNodeNames : returns the node names held in a nodes table or list
+func poolNodeNames(nodes interface{}) map[string]bool {
+	names := make(map[string]bool)
+	for _, item := range nodes.(*schema.Set).List() {
+		if entry, ok := item.(map[string]interface{}); ok {
+			names[entry["node"].(string)] = true
+		} else {
+			names[item.(string)] = true
+		}
+	}
+	return names
+}
+
+// removedPoolNodes : returns the nodes present in the old nodes table or list of a pool but not in the new one
+func removedPoolNodes(d *schema.ResourceData) []string {
+	oldNodes := make(map[string]bool)
+	newNodes := make(map[string]bool)
+
+	for _, attribute := range []string{"nodes_table", "nodes_list"} {
+		if !d.HasChange(attribute) {
+			continue
+		}
+		oldValue, newValue := d.GetChange(attribute)
+		for node := range poolNodeNames(oldValue) {
+			oldNodes[node] = true
+		}
+		for node := range poolNodeNames(newValue) {
+			newNodes[node] = true
+		}
+	}
+
+	removed := make([]string, 0)
+	for node := range oldNodes {
+		if !newNodes[node] {
+			removed = append(removed, node)
+		}
+	}
+	return removed
+}
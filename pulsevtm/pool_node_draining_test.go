@@ -0,0 +1,127 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const testPoolNodeStatisticsPath = "/api/tm/5.1/status/local_tm/statistics/nodes/per_pool_node/web-10.0.0.2:80"
+
+func testPoolNodeDrainingServer(t *testing.T, connections func(call int) int) (*testVTMServer, *[]string) {
+	server := newTestVTMServer(t)
+	server.setResource("pools/web", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{
+				"nodes_table": []interface{}{
+					map[string]interface{}{"node": "10.0.0.1:80", "priority": 1, "state": "active", "weight": 1},
+					map[string]interface{}{"node": "10.0.0.2:80", "priority": 1, "state": "active", "weight": 1},
+				},
+			},
+		},
+	})
+
+	// Records the state of the draining node each time its statistics are polled
+	observedStates := make([]string, 0)
+	server.handlers[testPoolNodeStatisticsPath] = func(w http.ResponseWriter, r *http.Request) {
+		nodesTable := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+		entry, _ := findPoolNode(nodesTable, "10.0.0.2:80")
+		observedStates = append(observedStates, fmt.Sprint(entry["state"]))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"statistics":{"current_conn":%d,"state":"alive"}}`, connections(len(observedStates)))
+	}
+	return server, &observedStates
+}
+
+func TestPoolNodeDeleteDrainsBeforeRemoval(t *testing.T) {
+	defer func(interval time.Duration) { poolNodeDrainPollInterval = interval }(poolNodeDrainPollInterval)
+	poolNodeDrainPollInterval = 10 * time.Millisecond
+
+	server, observedStates := testPoolNodeDrainingServer(t, func(call int) int {
+		if call < 3 {
+			return 4
+		}
+		return 0
+	})
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourcePoolNode().Schema, map[string]interface{}{
+		"pool":             "web",
+		"node":             "10.0.0.2:80",
+		"drain_on_removal": true,
+	})
+	d.SetId("web/10.0.0.2:80")
+	err := resourcePoolNodeDelete(d, server.meta(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(*observedStates) != 3 {
+		t.Errorf("expected statistics to be polled until connections reached zero, polled %d times", len(*observedStates))
+	}
+	for _, state := range *observedStates {
+		if state != "draining" {
+			t.Errorf("expected node to be draining whilst being polled, was %s", state)
+		}
+	}
+	nodesTable := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+	if _, ok := findPoolNode(nodesTable, "10.0.0.2:80"); ok {
+		t.Errorf("expected node 10.0.0.2:80 to be removed, got %+v", nodesTable)
+	}
+	if _, ok := findPoolNode(nodesTable, "10.0.0.1:80"); !ok {
+		t.Errorf("expected node 10.0.0.1:80 to remain, got %+v", nodesTable)
+	}
+}
+
+func TestPoolNodeDeleteRemovesNodeAfterDrainTimeout(t *testing.T) {
+	defer func(interval time.Duration) { poolNodeDrainPollInterval = interval }(poolNodeDrainPollInterval)
+	poolNodeDrainPollInterval = 100 * time.Millisecond
+
+	server, observedStates := testPoolNodeDrainingServer(t, func(call int) int {
+		return 10
+	})
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourcePoolNode().Schema, map[string]interface{}{
+		"pool":             "web",
+		"node":             "10.0.0.2:80",
+		"drain_on_removal": true,
+		"drain_timeout":    1,
+	})
+	d.SetId("web/10.0.0.2:80")
+	err := resourcePoolNodeDelete(d, server.meta(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(*observedStates) == 0 {
+		t.Errorf("expected statistics to be polled")
+	}
+	nodesTable := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+	if _, ok := findPoolNode(nodesTable, "10.0.0.2:80"); ok {
+		t.Errorf("expected node 10.0.0.2:80 to be removed after the drain timeout, got %+v", nodesTable)
+	}
+}
+
+func TestPoolNodeDeleteWithoutDrainingDoesNotPoll(t *testing.T) {
+	server, observedStates := testPoolNodeDrainingServer(t, func(call int) int {
+		return 10
+	})
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourcePoolNode().Schema, map[string]interface{}{
+		"pool": "web",
+		"node": "10.0.0.2:80",
+	})
+	d.SetId("web/10.0.0.2:80")
+	err := resourcePoolNodeDelete(d, server.meta(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*observedStates) != 0 {
+		t.Errorf("expected statistics not to be polled, polled %d times", len(*observedStates))
+	}
+}
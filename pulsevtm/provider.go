@@ -1,19 +1,21 @@
 package pulsevtm
 
 import (
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
 // Provider is a basic structure that describes a provider: the configuration
 // keys it takes, the resources it supports, a callback to configure, etc.
 func Provider() terraform.ResourceProvider {
 	// The actual provider
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"client_debug": {
 				Type:        schema.TypeBool,
@@ -27,58 +29,149 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_ALLOW_UNVERIFIED_SSL", false),
 				Description: "If set, PulseVTM client will permit unverifiable SSL certificates.",
 			},
+			"ca_cert_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_CA_CERT_FILE", ""),
+				Description: "Path of a file holding PEM encoded CA certificates to trust, in addition to the system's, when verifying the REST API server",
+			},
+			"ca_cert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_CA_CERT_PEM", ""),
+				Description: "PEM encoded CA certificates to trust, in addition to the system's, when verifying the REST API server",
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_CLIENT_CERT", ""),
+				Description: "PEM encoded client certificate, or the path of a file holding it, to present to the REST API server",
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_CLIENT_KEY", ""),
+				Description: "PEM encoded private key of client_cert, or the path of a file holding it",
+			},
 			"vtm_user": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_USERNAME", nil),
-				Description: "User to authenticate with PulseVTM appliance",
+				Description: "User to authenticate with PulseVTM appliance. The default for clusters which don't set their own",
 			},
 			"vtm_password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_PASSWORD", nil),
-				Description: "Password to authenticate with PulseVTM appliance",
+				Description: "Password to authenticate with PulseVTM appliance. The default for clusters which don't set their own",
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_CREDENTIALS_FILE", ""),
+				Description: "Path of a netrc-style file holding credentials per server, used when vtm_user or vtm_password aren't set",
+			},
+			"credential_process": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_CREDENTIAL_PROCESS", ""),
+				Description: "Command printing a JSON object with the username and password for the server in PULSEVTM_SERVER, used when vtm_user or vtm_password aren't set. Takes precedence over credentials_file",
 			},
 			"vtm_server": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_SERVER", nil),
-				Description: "Server to authenticate with PulseVTM appliance",
+				Description: "Server to authenticate with PulseVTM appliance. Required unless clusters are configured",
+			},
+			"clusters":      clustersSchema(),
+			"safety_backup": safetyBackupSchema(),
+			"max_idle_connections": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PULSEVTM_MAX_IDLE_CONNECTIONS", 10),
+				ValidateFunc: util.ValidateUnsignedInteger,
+				Description:  "Maximum number of idle connections to the REST API server kept open for reuse",
+			},
+			"max_connections_per_host": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PULSEVTM_MAX_CONNECTIONS_PER_HOST", 0),
+				ValidateFunc: util.ValidateUnsignedInteger,
+				Description:  "Maximum number of connections to the REST API server, including those in use. 0 means no limit",
+			},
+			"idle_connection_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PULSEVTM_IDLE_CONNECTION_TIMEOUT", 30),
+				ValidateFunc: util.ValidateUnsignedInteger,
+				Description:  "Time, in seconds, an idle connection to the REST API server is kept open for reuse",
+			},
+			"proxy_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PULSEVTM_PROXY_URL", ""),
+				ValidateFunc: validateProxyURL,
+				Description:  "URL of an HTTP or SOCKS5 proxy to reach the REST API server through, e.g. socks5://jump.example.com:1080. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables",
 			},
 			"api_version": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_API_VERSION", "5.1"),
-				Description: "PulsevTM REST API Server version",
+				DefaultFunc: schema.EnvDefaultFunc("PULSEVTM_API_VERSION", ""),
+				Description: "PulsevTM REST API Server version, 3.8 or later. Negotiated with the server when not set",
+			},
+			"api_version_constraint": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PULSEVTM_API_VERSION_CONSTRAINT", ""),
+				ValidateFunc: validateAPIVersionConstraint,
+				Description:  "Constraint on the PulsevTM REST API Server versions to negotiate, e.g. \">= 4.0, < 7.0\". The highest version the server supports within the constraint is used",
 			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"pulsevtm_appliance_nat":      resourceApplianceNat(),
-			"pulsevtm_aptimizer_profile":  resourceAptimizerProfile(),
-			"pulsevtm_bandwidth":          resourceBandwidth(),
-			"pulsevtm_cloud_credentials":  resourceCloudCredentials(),
-			"pulsevtm_dns_zone":           resourceDNSZone(),
-			"pulsevtm_global_settings":    resourceGlobalSettings(),
-			"pulsevtm_dns_zone_file":      resourceDNSZoneFile(),
-			"pulsevtm_glb":                resourceGLB(),
-			"pulsevtm_location":           resourceLocation(),
-			"pulsevtm_monitor":            resourceMonitor(),
-			"pulsevtm_persistence":        resourcePersistence(),
-			"pulsevtm_pool":               resourcePool(),
-			"pulsevtm_rule":               resourceRule(),
-			"pulsevtm_ssl_cas_file":       resourceSSLCasFile(),
-			"pulsevtm_ssl_client_key":     resourceSSLClientKey(),
-			"pulsevtm_ssl_server_key":     resourceSSLServerKey(),
-			"pulsevtm_ssl_ticket_key":     resourceSSLTicketKey(),
-			"pulsevtm_traffic_manager":    resourceTrafficManager(),
-			"pulsevtm_traffic_ip_group":   resourceTrafficIPGroup(),
-			"pulsevtm_user_authenticator": resourceUserAuthenticator(),
-			"pulsevtm_user_group":         resourceUserGroup(),
-			"pulsevtm_virtual_server":     resourceVirtualServer(),
+			"pulsevtm_appliance_nat":             resourceApplianceNat(),
+			"pulsevtm_aptimizer_profile":         resourceAptimizerProfile(),
+			"pulsevtm_backup":                    resourceBackup(),
+			"pulsevtm_bandwidth":                 resourceBandwidth(),
+			"pulsevtm_cloud_credentials":         resourceCloudCredentials(),
+			"pulsevtm_dns_record":                resourceDNSRecord(),
+			"pulsevtm_dns_zone":                  resourceDNSZone(),
+			"pulsevtm_global_settings":           resourceGlobalSettings(),
+			"pulsevtm_dns_zone_file":             resourceDNSZoneFile(),
+			"pulsevtm_glb":                       resourceGLB(),
+			"pulsevtm_location":                  resourceLocation(),
+			"pulsevtm_monitor":                   resourceMonitor(),
+			"pulsevtm_persistence":               resourcePersistence(),
+			"pulsevtm_pool":                      resourcePool(),
+			"pulsevtm_pool_node":                 resourcePoolNode(),
+			"pulsevtm_rule":                      resourceRule(),
+			"pulsevtm_ssl_cas_file":              resourceSSLCasFile(),
+			"pulsevtm_ssl_client_key":            resourceSSLClientKey(),
+			"pulsevtm_ssl_server_key":            resourceSSLServerKey(),
+			"pulsevtm_ssl_ticket_key":            resourceSSLTicketKey(),
+			"pulsevtm_traffic_manager":           resourceTrafficManager(),
+			"pulsevtm_traffic_manager_host":      resourceTrafficManagerHost(),
+			"pulsevtm_traffic_manager_interface": resourceTrafficManagerInterface(),
+			"pulsevtm_traffic_manager_ip":        resourceTrafficManagerIP(),
+			"pulsevtm_traffic_manager_route":     resourceTrafficManagerRoute(),
+			"pulsevtm_traffic_ip_group":          resourceTrafficIPGroup(),
+			"pulsevtm_user_authenticator":        resourceUserAuthenticator(),
+			"pulsevtm_user_group":                resourceUserGroup(),
+			"pulsevtm_virtual_server":            resourceVirtualServer(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"pulsevtm_api_version": dataSourceAPIVersion(),
+			"pulsevtm_backups":     dataSourceBackups(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
+
+	for name, resource := range provider.ResourcesMap {
+		attributeErrorResource(resource, resourceSectionNames[name])
+		clusterAwareResource(resource)
+	}
+	return &pulseVTMProvider{Provider: provider}
 }
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
@@ -89,36 +182,114 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	vtmPassword := d.Get("vtm_password").(string)
 	vtmServer := d.Get("vtm_server").(string)
 	apiVersion := d.Get("api_version").(string)
+	apiVersionConstraint := d.Get("api_version_constraint").(string)
 	var timeout time.Duration = 30
+	credentials := credentialSources{
+		file:    d.Get("credentials_file").(string),
+		process: d.Get("credential_process").(string),
+	}
 
-	config := make(map[string]interface{})
+	tlsConfig, err := buildTLSConfig(
+		allowUnverifiedSSL,
+		d.Get("ca_cert_file").(string),
+		d.Get("ca_cert_pem").(string),
+		d.Get("client_cert").(string),
+		d.Get("client_key").(string),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	octetHeaders := make(map[string]string)
-	octetHeaders["Content-Type"] = "application/octet-stream"
-	octetHeaders["Content-Transfer-Encoding"] = "text"
+	proxy, err := restProxy(d.Get("proxy_url").(string))
+	if err != nil {
+		return nil, err
+	}
 
-	jsonConfig := api.Params{
+	// One transport is shared by every client of the provider so connections are reused across requests
+	transport := newRESTTransport(
+		tlsConfig,
+		allowUnverifiedSSL,
+		proxy,
+		d.Get("max_idle_connections").(int),
+		d.Get("max_connections_per_host").(int),
+		time.Duration(d.Get("idle_connection_timeout").(int))*time.Second,
+	)
+
+	params := api.Params{
 		APIVersion: apiVersion,
 		Debug:      clientDebug,
 		IgnoreSSL:  allowUnverifiedSSL,
 		Username:   vtmUser,
 		Password:   vtmPassword,
 		Server:     vtmServer,
-		Headers:    map[string]string{"Content-Type": "application/json"},
 		Timeout:    timeout,
+		TLSConfig:  tlsConfig,
+		Transport:  transport,
 	}
 
-	octetConfig := api.Params{
-		APIVersion: apiVersion,
-		Debug:      clientDebug,
-		IgnoreSSL:  allowUnverifiedSSL,
-		Username:   vtmUser,
-		Password:   vtmPassword,
-		Server:     vtmServer,
-		Headers:    octetHeaders,
-		Timeout:    timeout,
+	var config map[string]interface{}
+	clusters := d.Get("clusters").([]interface{})
+	if len(clusters) == 0 {
+		config, err = connectServer(params, apiVersionConstraint, credentials)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if vtmServer != "" {
+			log.Printf("[WARN] PulseVTM vtm_server %s is ignored as clusters are configured", vtmServer)
+		}
+		config, err = connectClusters(clusters, params, apiVersionConstraint, credentials)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if safetyBackups := d.Get("safety_backup").([]interface{}); len(safetyBackups) > 0 {
+		config["safetyBackup"] = newSafetyBackup(safetyBackups[0].(map[string]interface{}))
+	}
+	return config, nil
+}
+
+// connectServer : connects the JSON, octet-stream and status clients to a traffic manager, negotiating the REST API version
+// unless one is given, and returns them in the form resources expect as their meta. Credentials which aren't set are
+// looked up in the credential sources.
+func connectServer(params api.Params, apiVersionConstraint string, credentials credentialSources) (map[string]interface{}, error) {
+	params, err := credentials.resolve(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Server == "" || params.Username == "" || params.Password == "" {
+		return nil, fmt.Errorf("[ERROR] PulseVTM provider vtm_server, vtm_user and vtm_password must be set unless clusters are configured or the credentials are found in credential_process or credentials_file")
 	}
 
+	apiVersion := params.APIVersion
+	if apiVersion == "" {
+		apiVersion, err = negotiateAPIVersion(params, apiVersionConstraint)
+		if err != nil {
+			log.Println("Error negotiating Pulse REST Server API version: ", err)
+			return nil, err
+		}
+	} else if apiVersionConstraint != "" {
+		return nil, fmt.Errorf("[ERROR] PulseVTM provider api_version and api_version_constraint can't both be set")
+	}
+	err = validateAPIVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] Working with PulseVTM REST API version %s on %s", apiVersion, params.Server)
+
+	octetHeaders := make(map[string]string)
+	octetHeaders["Content-Type"] = "application/octet-stream"
+	octetHeaders["Content-Transfer-Encoding"] = "text"
+
+	jsonConfig := params
+	jsonConfig.APIVersion = apiVersion
+	jsonConfig.Headers = map[string]string{"Content-Type": "application/json"}
+
+	octetConfig := params
+	octetConfig.APIVersion = apiVersion
+	octetConfig.Headers = octetHeaders
+
 	jsonClient, err := api.Connect(jsonConfig)
 	if err != nil {
 		log.Println("Error connecting to Pulse REST Server: ", err)
@@ -129,8 +300,21 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		log.Println("Error connecting to Pulse REST Server: ", err)
 		return nil, err
 	}
+	// Status resources are read with a client of their own, which is never switched to configuration resources, so
+	// reading them never changes the root path of the clients other resources are using
+	statusClient, err := api.Connect(jsonConfig)
+	if err != nil {
+		log.Println("Error connecting to Pulse REST Server: ", err)
+		return nil, err
+	}
+	statusClient.WorkWithStatus()
 
+	config := make(map[string]interface{})
 	config["jsonClient"] = jsonClient
 	config["octetClient"] = octetClient
+	config["statusClient"] = statusClient
+	config["apiVersion"] = apiVersion
+	config["server"] = params.Server
+	config["username"] = params.Username
 	return config, nil
 }
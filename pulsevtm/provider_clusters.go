@@ -0,0 +1,299 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"log"
+	"reflect"
+	"strings"
+)
+
+// vtmCluster : an independent traffic manager cluster the provider manages configuration on
+type vtmCluster struct {
+	name string
+	// meta holds the clients connected to the cluster in the form resources expect as their meta
+	meta map[string]interface{}
+}
+
+// clustersSchema : returns the schema of the provider's list of clusters
+func clustersSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Independent traffic manager clusters to manage configuration on. When set vtm_server is ignored",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Name resources use to select the cluster",
+				},
+				"vtm_server": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Server to authenticate with PulseVTM appliance",
+				},
+				"vtm_user": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "User to authenticate with PulseVTM appliance. Defaults to the provider's vtm_user",
+				},
+				"vtm_password": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Password to authenticate with PulseVTM appliance. Defaults to the provider's vtm_password",
+				},
+				"api_version": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "PulsevTM REST API Server version, 3.8 or later. Defaults to the provider's api_version",
+				},
+			},
+		},
+	}
+}
+
+// connectClusters : connects to every configured cluster and returns the provider's meta. The meta of the first
+// cluster is also held at the top level, so it's used by anything unaware of clusters.
+func connectClusters(clusters []interface{}, params api.Params, apiVersionConstraint string, credentials credentialSources) (map[string]interface{}, error) {
+	connected := make([]*vtmCluster, 0)
+	names := make(map[string]bool)
+	for _, item := range clusters {
+		cluster := item.(map[string]interface{})
+		name := cluster["name"].(string)
+		if names[name] {
+			return nil, fmt.Errorf("[ERROR] PulseVTM cluster %s is configured more than once", name)
+		}
+		names[name] = true
+
+		clusterParams := params
+		clusterParams.Server = cluster["vtm_server"].(string)
+		if vtmUser := cluster["vtm_user"].(string); vtmUser != "" {
+			clusterParams.Username = vtmUser
+		}
+		if vtmPassword := cluster["vtm_password"].(string); vtmPassword != "" {
+			clusterParams.Password = vtmPassword
+		}
+		if apiVersion := cluster["api_version"].(string); apiVersion != "" {
+			clusterParams.APIVersion = apiVersion
+		}
+
+		meta, err := connectServer(clusterParams, apiVersionConstraint, credentials)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] PulseVTM error whilst connecting to cluster %s: %v", name, err)
+		}
+		connected = append(connected, &vtmCluster{name: name, meta: meta})
+	}
+
+	config := make(map[string]interface{})
+	for key, value := range connected[0].meta {
+		config[key] = value
+	}
+	config["clusters"] = connected
+	return config, nil
+}
+
+// clusterMetas : returns the meta of every cluster the provider is configured with
+func clusterMetas(m interface{}) []interface{} {
+	config, ok := m.(map[string]interface{})
+	if !ok {
+		return []interface{}{m}
+	}
+	clusters, ok := config["clusters"].([]*vtmCluster)
+	if !ok {
+		return []interface{}{m}
+	}
+	metas := make([]interface{}, 0)
+	for _, cluster := range clusters {
+		metas = append(metas, cluster.meta)
+	}
+	return metas
+}
+
+// targetClusters : returns the clusters a resource is managed on, either the one it selects or all of them. nil is
+// returned when the provider is configured with a single vtm_server.
+func targetClusters(d *schema.ResourceData, m interface{}) ([]*vtmCluster, error) {
+	return selectClusters(d.Get("cluster").(string), m)
+}
+
+// selectClusters : returns the cluster selected by name, or every cluster when none is, or nil when the provider is
+// configured with a single vtm_server
+func selectClusters(selected string, m interface{}) ([]*vtmCluster, error) {
+	clusters, ok := m.(map[string]interface{})["clusters"].([]*vtmCluster)
+	if !ok {
+		if selected != "" {
+			return nil, fmt.Errorf("[ERROR] PulseVTM cluster %s is selected but the provider isn't configured with clusters", selected)
+		}
+		return nil, nil
+	}
+	if selected == "" {
+		return clusters, nil
+	}
+	for _, cluster := range clusters {
+		if cluster.name == selected {
+			return []*vtmCluster{cluster}, nil
+		}
+	}
+	return nil, fmt.Errorf("[ERROR] PulseVTM cluster %s is not configured in the provider", selected)
+}
+
+// withoutClusterStatus : returns the attributes of a state other than the status of each cluster
+func withoutClusterStatus(attributes map[string]string) map[string]string {
+	filtered := make(map[string]string)
+	for key, value := range attributes {
+		if !strings.HasPrefix(key, "cluster_status.") {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// setClusterStatus : reads the resource from every cluster but the first, whose state d already holds, recording
+// whether it's in sync with the first, has drifted or is missing
+func setClusterStatus(resource *schema.Resource, read schema.ReadFunc, d *schema.ResourceData, clusters []*vtmCluster) error {
+	status := map[string]interface{}{clusters[0].name: "in_sync"}
+	primaryState := d.State()
+	for _, cluster := range clusters[1:] {
+		clusterData := resource.Data(primaryState)
+		err := read(clusterData, cluster.meta)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst reading %s from cluster %s: %v", d.Id(), cluster.name, err)
+		}
+		switch {
+		case clusterData.Id() == "":
+			log.Printf("[WARN] PulseVTM %s is missing from cluster %s", d.Id(), cluster.name)
+			status[cluster.name] = "missing"
+		case !reflect.DeepEqual(withoutClusterStatus(clusterData.State().Attributes), withoutClusterStatus(primaryState.Attributes)):
+			log.Printf("[WARN] PulseVTM %s on cluster %s has drifted from cluster %s", d.Id(), cluster.name, clusters[0].name)
+			status[cluster.name] = "drifted"
+		default:
+			status[cluster.name] = "in_sync"
+		}
+	}
+	return d.Set("cluster_status", status)
+}
+
+// plannedCluster : returns the cluster selected by a resource, from its planned change or else from its state
+func plannedCluster(s *terraform.InstanceState, d *terraform.InstanceDiff) string {
+	if d != nil {
+		if attribute, ok := d.Attributes["cluster"]; ok && !attribute.NewRemoved {
+			return attribute.New
+		}
+	}
+	if s != nil {
+		return s.Attributes["cluster"]
+	}
+	return ""
+}
+
+// copyInstanceState : returns a deep copy of a state, which may be nil
+func copyInstanceState(s *terraform.InstanceState) *terraform.InstanceState {
+	if s == nil {
+		return nil
+	}
+	return s.DeepCopy()
+}
+
+// applyToClusters : applies the planned change of a resource to each cluster it's managed on, each from its own copy
+// of the state and the planned change, so the values read back from one cluster are never written to the next. A
+// resource being destroyed is deleted from every cluster even when deleting it from one fails; otherwise the change
+// stops at the first cluster it fails on. The error names the clusters the change was and wasn't applied to. Nothing
+// is rolled back: the state returned is that of the first cluster, with the status of each cluster, so a change
+// applied to the first cluster but not to the others isn't planned again. Refreshing reports those clusters as
+// drifted or missing in cluster_status.
+func applyToClusters(resource *schema.Resource, s *terraform.InstanceState, d *terraform.InstanceDiff, m interface{}) (*terraform.InstanceState, error) {
+	clusters, err := selectClusters(plannedCluster(s, d), m)
+	if err != nil {
+		return s, err
+	}
+	if clusters == nil {
+		return resource.Apply(s, d, m)
+	}
+
+	destroy := d.Destroy && !d.RequiresNew()
+	state := s
+	applied, failed, failures := make([]string, 0), make([]string, 0), make([]string, 0)
+	for i, cluster := range clusters {
+		if len(failed) > 0 && !destroy {
+			break
+		}
+		clusterState, err := resource.Apply(copyInstanceState(s), d.DeepCopy(), cluster.meta)
+		if i == 0 {
+			state = clusterState
+		}
+		if err != nil {
+			failed = append(failed, cluster.name)
+			failures = append(failures, fmt.Sprintf("[ERROR] PulseVTM error whilst applying to cluster %s: %v", cluster.name, err))
+			continue
+		}
+		applied = append(applied, cluster.name)
+	}
+
+	if len(failed) > 0 {
+		notApplied := failed
+		for _, cluster := range clusters[len(applied)+len(failed):] {
+			notApplied = append(notApplied, cluster.name)
+		}
+		if len(applied) > 0 {
+			failures = append(failures, fmt.Sprintf("Applied to clusters %s", strings.Join(applied, ", ")))
+		}
+		failures = append(failures, fmt.Sprintf("Not applied to clusters %s", strings.Join(notApplied, ", ")))
+		if destroy || state == nil {
+			// The resource is still managed on the clusters it wasn't deleted from
+			state = s
+		}
+		return state, fmt.Errorf("%s", strings.Join(failures, "\n"))
+	}
+	if destroy || state == nil {
+		return nil, nil
+	}
+
+	data := resource.Data(state)
+	err = resource.Read(data, m)
+	if err != nil {
+		return state, err
+	}
+	return data.State(), nil
+}
+
+// clusterAwareResource : adds the cluster selector and the status of each cluster to a resource, and wraps its read
+// so it's read from the selected cluster, or the first cluster the provider is configured with, recording the status
+// of each cluster. Only the state read from the first cluster is planned against, so a cluster which has drifted or is
+// missing is reported in cluster_status without changing the plan. Changes are applied to each cluster by
+// applyToClusters.
+func clusterAwareResource(resource *schema.Resource) {
+	resource.Schema["cluster"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Name of the provider cluster to manage the resource on. Managed on every cluster when not set",
+	}
+	resource.Schema["cluster_status"] = &schema.Schema{
+		Type:        schema.TypeMap,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Status of the resource on each cluster it's managed on, compared with the first, one of in_sync, drifted or missing. Only the first cluster is planned against",
+	}
+
+	read := resource.Read
+	resource.Read = func(d *schema.ResourceData, m interface{}) error {
+		clusters, err := targetClusters(d, m)
+		if err != nil {
+			return err
+		}
+		if clusters == nil {
+			return read(d, m)
+		}
+		err = read(d, clusters[0].meta)
+		if err != nil {
+			return err
+		}
+		if d.Id() == "" {
+			return nil
+		}
+		return setClusterStatus(resource, read, d, clusters)
+	}
+}
@@ -0,0 +1,252 @@
+package pulsevtm
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+// testClustersProvider : returns a provider configured with a cluster per test server, each holding an empty pool
+func testClustersProvider(t *testing.T, servers map[string]*testVTMServer) *pulseVTMProvider {
+	clusters := make([]interface{}, 0)
+	for _, name := range []string{"primary", "secondary"} {
+		server := servers[name]
+		server.setResource("pools/web", map[string]interface{}{
+			"properties": map[string]interface{}{
+				"basic": map[string]interface{}{"nodes_table": []interface{}{}},
+			},
+		})
+		clusters = append(clusters, map[string]interface{}{
+			"name":        name,
+			"vtm_server":  server.URL,
+			"api_version": "5.1",
+		})
+	}
+
+	provider := Provider().(*pulseVTMProvider)
+	err := provider.Configure(testResourceConfig(t, map[string]interface{}{
+		"vtm_user":             "admin",
+		"vtm_password":         "password",
+		"allow_unverified_ssl": true,
+		"clusters":             clusters,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error configuring provider: %v", err)
+	}
+	return provider
+}
+
+func testClusterPoolNodeWeight(server *testVTMServer, node string) (interface{}, bool) {
+	nodesTable := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+	entry, ok := findPoolNode(nodesTable, node)
+	if !ok {
+		return nil, false
+	}
+	return entry["weight"], true
+}
+
+// testProviderApply : plans and applies a resource through the provider, destroying it when no configuration is given
+func testProviderApply(t *testing.T, provider *pulseVTMProvider, resourceType string, state *terraform.InstanceState, config map[string]interface{}) (*terraform.InstanceState, error) {
+	info := &terraform.InstanceInfo{Type: resourceType}
+	if config == nil {
+		return provider.Apply(info, state, &terraform.InstanceDiff{Destroy: true})
+	}
+	diff, err := provider.Diff(info, state, testResourceConfig(t, config))
+	if err != nil {
+		t.Fatalf("unexpected error planning %s: %v", resourceType, err)
+	}
+	return provider.Apply(info, state, diff)
+}
+
+func TestClusterAwareResourceFansOutToEveryCluster(t *testing.T) {
+	servers := map[string]*testVTMServer{"primary": newTestVTMServer(t), "secondary": newTestVTMServer(t)}
+	for _, server := range servers {
+		defer server.Close()
+	}
+	provider := testClustersProvider(t, servers)
+	info := &terraform.InstanceInfo{Type: "pulsevtm_pool_node"}
+
+	state, err := testProviderApply(t, provider, "pulsevtm_pool_node", nil, map[string]interface{}{
+		"pool":   "web",
+		"node":   "10.0.0.1:80",
+		"weight": 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, server := range servers {
+		if weight, ok := testClusterPoolNodeWeight(server, "10.0.0.1:80"); !ok || weight != float64(5) {
+			t.Errorf("expected node with weight 5 on cluster %s, got %v", name, weight)
+		}
+	}
+	if state.Attributes["cluster_status.%"] != "2" || state.Attributes["cluster_status.primary"] != "in_sync" || state.Attributes["cluster_status.secondary"] != "in_sync" {
+		t.Errorf("expected both clusters to be in sync, got %v", state.Attributes)
+	}
+
+	// The node is changed on the secondary cluster outside of Terraform
+	err = setPoolNodesTable(servers["secondary"].meta(t)["jsonClient"].(*api.Client), "web", []interface{}{
+		map[string]interface{}{"node": "10.0.0.1:80", "priority": 1, "state": "active", "weight": 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err = provider.Refresh(info, state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Attributes["cluster_status.secondary"] != "drifted" {
+		t.Errorf("expected the secondary cluster to have drifted, got %v", state.Attributes)
+	}
+	if state.Attributes["weight"] != "5" {
+		t.Errorf("expected state to be read from the primary cluster, got weight %s", state.Attributes["weight"])
+	}
+
+	// The node is removed from the secondary cluster outside of Terraform
+	err = setPoolNodesTable(servers["secondary"].meta(t)["jsonClient"].(*api.Client), "web", []interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err = provider.Refresh(info, state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Attributes["cluster_status.secondary"] != "missing" {
+		t.Errorf("expected the node to be missing from the secondary cluster, got %v", state.Attributes)
+	}
+
+	state, err = testProviderApply(t, provider, "pulsevtm_pool_node", state, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != nil {
+		t.Errorf("expected the node to be removed from state, got %v", state)
+	}
+	for name, server := range servers {
+		if _, ok := testClusterPoolNodeWeight(server, "10.0.0.1:80"); ok {
+			t.Errorf("expected node to be removed from cluster %s", name)
+		}
+	}
+}
+
+func TestClusterAwareResourceAppliesPlannedValuesToEveryCluster(t *testing.T) {
+	servers := map[string]*testVTMServer{"primary": newTestVTMServer(t), "secondary": newTestVTMServer(t)}
+	for _, server := range servers {
+		defer server.Close()
+	}
+	provider := testClustersProvider(t, servers)
+	// The primary cluster reads back a weight other than the one written to it
+	primary := servers["primary"]
+	primary.beforeGet = func(path string) {
+		if path != "pools/web" {
+			return
+		}
+		if weight, ok := testClusterPoolNodeWeight(primary, "10.0.0.1:80"); ok && weight == float64(5) {
+			primary.setResource("pools/web", map[string]interface{}{"properties": map[string]interface{}{
+				"basic": map[string]interface{}{"nodes_table": []interface{}{
+					map[string]interface{}{"node": "10.0.0.1:80", "priority": 1, "state": "active", "weight": 7},
+				}},
+			}})
+		}
+	}
+
+	state, err := testProviderApply(t, provider, "pulsevtm_pool_node", nil, map[string]interface{}{
+		"pool":   "web",
+		"node":   "10.0.0.1:80",
+		"weight": 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if weight, ok := testClusterPoolNodeWeight(servers["secondary"], "10.0.0.1:80"); !ok || weight != float64(5) {
+		t.Errorf("expected the planned weight 5 on the secondary cluster, got %v", weight)
+	}
+	if state.Attributes["weight"] != "7" {
+		t.Errorf("expected state to be read from the primary cluster, got weight %s", state.Attributes["weight"])
+	}
+}
+
+func TestClusterAwareResourceReportsPartialDelete(t *testing.T) {
+	servers := map[string]*testVTMServer{"primary": newTestVTMServer(t), "secondary": newTestVTMServer(t)}
+	for _, server := range servers {
+		defer server.Close()
+	}
+	provider := testClustersProvider(t, servers)
+
+	state, err := testProviderApply(t, provider, "pulsevtm_pool_node", nil, map[string]interface{}{
+		"pool": "web",
+		"node": "10.0.0.1:80",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The primary cluster fails every request
+	poolPath := testVTMConfigPath + "pools/web"
+	servers["primary"].handlers[poolPath] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error_id":"server.internal","error_text":"Internal error"}`))
+	}
+	failedState, err := testProviderApply(t, provider, "pulsevtm_pool_node", state, nil)
+	if err == nil || !strings.Contains(err.Error(), "applying to cluster primary") || !strings.Contains(err.Error(), "Applied to clusters secondary") || !strings.Contains(err.Error(), "Not applied to clusters primary") {
+		t.Fatalf("expected an error naming the clusters the node was and wasn't deleted from, got %v", err)
+	}
+	if failedState == nil || failedState.ID != state.ID {
+		t.Fatalf("expected the node to be kept in state, got %v", failedState)
+	}
+	if _, ok := testClusterPoolNodeWeight(servers["secondary"], "10.0.0.1:80"); ok {
+		t.Errorf("expected the node to be deleted from the secondary cluster")
+	}
+
+	// Once the primary cluster recovers, deleting the node again completes
+	delete(servers["primary"].handlers, poolPath)
+	state, err = testProviderApply(t, provider, "pulsevtm_pool_node", failedState, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != nil {
+		t.Errorf("expected the node to be removed from state, got %v", state)
+	}
+	if _, ok := testClusterPoolNodeWeight(servers["primary"], "10.0.0.1:80"); ok {
+		t.Errorf("expected the node to be deleted from the primary cluster")
+	}
+}
+
+func TestClusterAwareResourceSelectsCluster(t *testing.T) {
+	servers := map[string]*testVTMServer{"primary": newTestVTMServer(t), "secondary": newTestVTMServer(t)}
+	for _, server := range servers {
+		defer server.Close()
+	}
+	provider := testClustersProvider(t, servers)
+
+	state, err := testProviderApply(t, provider, "pulsevtm_pool_node", nil, map[string]interface{}{
+		"pool":    "web",
+		"node":    "10.0.0.1:80",
+		"cluster": "secondary",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := testClusterPoolNodeWeight(servers["secondary"], "10.0.0.1:80"); !ok {
+		t.Errorf("expected node on the selected cluster")
+	}
+	if _, ok := testClusterPoolNodeWeight(servers["primary"], "10.0.0.1:80"); ok {
+		t.Errorf("expected node not to be pushed to the primary cluster")
+	}
+	if state.Attributes["cluster_status.%"] != "1" || state.Attributes["cluster_status.secondary"] != "in_sync" {
+		t.Errorf("expected status of only the selected cluster, got %v", state.Attributes)
+	}
+
+	_, err = testProviderApply(t, provider, "pulsevtm_pool_node", nil, map[string]interface{}{
+		"pool":    "web",
+		"node":    "10.0.0.2:80",
+		"cluster": "tertiary",
+	})
+	if err == nil || !regexp.MustCompile(`cluster tertiary is not configured`).MatchString(err.Error()) {
+		t.Errorf("expected an error selecting an unknown cluster, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package pulsevtm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+// credentialProcessTimeout : how long credential_process is given to print the credentials
+const credentialProcessTimeout = 30 * time.Second
+
+// credentialSources : the sources vtm_user and vtm_password are looked up in when they aren't set
+type credentialSources struct {
+	// file is the path of a netrc-style credentials file
+	file string
+	// process is a command printing the credentials as JSON
+	process string
+}
+
+// credentialsProfile : an entry of a credentials file
+type credentialsProfile struct {
+	// machine is the server the profile is used for, empty for the default profile
+	machine  string
+	login    string
+	password string
+}
+
+// processCredentials : the JSON credential_process prints
+type processCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// resolve : fills in the username and password of params which aren't set, first from credential_process and then
+// from the credentials file. Credentials set in the provider configuration always take precedence.
+func (sources credentialSources) resolve(params api.Params) (api.Params, error) {
+	if params.Username != "" && params.Password != "" {
+		return params, nil
+	}
+
+	if sources.process != "" {
+		credentials, err := runCredentialProcess(sources.process, params.Server)
+		if err != nil {
+			return params, err
+		}
+		if params.Username == "" {
+			params.Username = credentials.Username
+		}
+		if params.Password == "" {
+			params.Password = credentials.Password
+		}
+		log.Printf("[DEBUG] PulseVTM credentials for %s read from credential_process", params.Server)
+	}
+
+	if sources.file != "" && (params.Username == "" || params.Password == "") {
+		profiles, err := readCredentialsFile(sources.file)
+		if err != nil {
+			return params, err
+		}
+		profile, ok := findCredentialsProfile(profiles, params.Server, params.Username)
+		if ok {
+			if params.Username == "" {
+				params.Username = profile.login
+			}
+			if params.Password == "" {
+				params.Password = profile.password
+			}
+			log.Printf("[DEBUG] PulseVTM credentials for %s read from credentials file %s", params.Server, sources.file)
+		} else {
+			log.Printf("[WARN] PulseVTM credentials file %s has no profile for %s", sources.file, params.Server)
+		}
+	}
+	return params, nil
+}
+
+// readCredentialsFile : reads the profiles of a netrc-style credentials file, e.g.
+//
+//	machine vtm1.example.com:9070
+//	  login admin
+//	  password secret
+//	default login readonly password other
+func readCredentialsFile(path string) ([]credentialsProfile, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] PulseVTM error whilst reading credentials file %s: %v", path, err)
+	}
+	if info, err := os.Stat(path); err == nil && credentialsFileReadableByOthers(info.Mode()) {
+		log.Printf("[WARN] PulseVTM credentials file %s can be read by other users (mode %s), restrict it with: chmod 600 %s", path, info.Mode().Perm(), path)
+	}
+	profiles, err := parseCredentials(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] PulseVTM error whilst parsing credentials file %s: %v", path, err)
+	}
+	return profiles, nil
+}
+
+// credentialsFileReadableByOthers : whether a credentials file can be read by its group or by everyone. Windows
+// doesn't have these permissions, so files there are never reported.
+func credentialsFileReadableByOthers(mode os.FileMode) bool {
+	return runtime.GOOS != "windows" && mode.Perm()&0044 != 0
+}
+
+// parseCredentials : parses the profiles of a netrc-style credentials file. Comments start with a # at the start of a
+// line or after whitespace, and run to the end of the line. A # in a server, login or password is part of its value.
+func parseCredentials(content string) ([]credentialsProfile, error) {
+	tokens := make([]string, 0)
+	expectingValue := false
+	for _, line := range strings.Split(content, "\n") {
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "#") && !expectingValue {
+				break
+			}
+			tokens = append(tokens, field)
+			expectingValue = !expectingValue && (field == "machine" || field == "login" || field == "password")
+		}
+	}
+
+	profiles := make([]credentialsProfile, 0)
+	var profile *credentialsProfile
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine", "default":
+			if profile != nil {
+				profiles = append(profiles, *profile)
+			}
+			profile = &credentialsProfile{}
+			if tokens[i] == "machine" {
+				if i+1 >= len(tokens) {
+					return nil, fmt.Errorf("machine is missing its server")
+				}
+				i++
+				profile.machine = tokens[i]
+			}
+		case "login", "password":
+			if profile == nil {
+				return nil, fmt.Errorf("%s must follow a machine or default", tokens[i])
+			}
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("%s is missing its value", tokens[i])
+			}
+			if tokens[i] == "login" {
+				profile.login = tokens[i+1]
+			} else {
+				profile.password = tokens[i+1]
+			}
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected token %q", tokens[i])
+		}
+	}
+	if profile != nil {
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
+
+// machineMatchesServer : whether the machine of a profile names the server, either as its URL, host and port or host
+func machineMatchesServer(machine, server string) bool {
+	if machine == server {
+		return true
+	}
+	parsed, err := url.Parse(server)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return machine == parsed.Host || machine == parsed.Hostname()
+}
+
+// findCredentialsProfile : returns the first profile for the server, falling back to the default profile. When the
+// username is known only profiles with that login are considered.
+func findCredentialsProfile(profiles []credentialsProfile, server, username string) (credentialsProfile, bool) {
+	var fallback *credentialsProfile
+	for i, profile := range profiles {
+		if username != "" && profile.login != "" && profile.login != username {
+			continue
+		}
+		if profile.machine == "" {
+			if fallback == nil {
+				fallback = &profiles[i]
+			}
+			continue
+		}
+		if machineMatchesServer(profile.machine, server) {
+			return profile, true
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return credentialsProfile{}, false
+}
+
+// runCredentialProcess : runs the credential_process command through the shell, with PULSEVTM_SERVER set to the
+// server the credentials are for, and parses the JSON it prints
+func runCredentialProcess(command, server string) (processCredentials, error) {
+	credentials := processCredentials{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialProcessTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "PULSEVTM_SERVER="+server)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return credentials, fmt.Errorf("[ERROR] PulseVTM credential_process failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	err = json.Unmarshal(output, &credentials)
+	if err != nil {
+		return credentials, fmt.Errorf("[ERROR] PulseVTM credential_process must print a JSON object with username and password: %v", err)
+	}
+	if credentials.Username == "" && credentials.Password == "" {
+		return credentials, fmt.Errorf("[ERROR] PulseVTM credential_process printed neither a username nor a password")
+	}
+	return credentials, nil
+}
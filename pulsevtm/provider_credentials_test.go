@@ -0,0 +1,182 @@
+package pulsevtm
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+const testCredentialsFile = `
+# Production traffic managers
+machine vtm1.example.com:9070
+  login admin
+  password prod-secret
+
+machine https://vtm2.example.com:9070 login operator password vtm2-secret
+machine vtm3.example.com login admin password vtm3-admin
+machine vtm3.example.com login readonly password vtm3-readonly
+
+default login fallback password fallback-secret
+`
+
+// writeTestFile : writes content to a file in a temporary directory, returning its path
+func writeTestFile(t *testing.T, name, content string, mode os.FileMode) string {
+	directory, err := ioutil.TempDir("", "pulsevtm-credentials")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	path := filepath.Join(directory, name)
+	err = ioutil.WriteFile(path, []byte(content), mode)
+	if err != nil {
+		t.Fatalf("unable to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestCredentialsFileProfiles(t *testing.T) {
+	path := writeTestFile(t, "credentials", testCredentialsFile, 0600)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	cases := map[string]struct {
+		Server           string
+		Username         string
+		ExpectedUsername string
+		ExpectedPassword string
+	}{
+		"host and port":       {"https://vtm1.example.com:9070", "", "admin", "prod-secret"},
+		"full URL":            {"https://vtm2.example.com:9070", "", "operator", "vtm2-secret"},
+		"host alone":          {"https://vtm3.example.com:9070", "", "admin", "vtm3-admin"},
+		"login selects entry": {"https://vtm3.example.com:9070", "readonly", "readonly", "vtm3-readonly"},
+		"default":             {"https://vtm4.example.com:9070", "", "fallback", "fallback-secret"},
+	}
+	for name, tc := range cases {
+		params, err := credentialSources{file: path}.resolve(api.Params{Server: tc.Server, Username: tc.Username})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if params.Username != tc.ExpectedUsername || params.Password != tc.ExpectedPassword {
+			t.Errorf("%s: expected %s/%s, got %s/%s", name, tc.ExpectedUsername, tc.ExpectedPassword, params.Username, params.Password)
+		}
+	}
+}
+
+func TestCredentialsFileErrors(t *testing.T) {
+	cases := map[string]string{
+		"login password":          "must follow a machine or default",
+		"machine":                 "machine is missing its server",
+		"machine vtm login":       "login is missing its value",
+		"machine vtm account foo": "unexpected token \"account\"",
+	}
+	for content, expected := range cases {
+		_, err := parseCredentials(content)
+		if err == nil || !regexp.MustCompile(expected).MatchString(err.Error()) {
+			t.Errorf("%q: expected an error matching %q, got %v", content, expected, err)
+		}
+	}
+
+	_, err := credentialSources{file: "/nonexistent/credentials"}.resolve(api.Params{Server: "https://vtm1.example.com:9070"})
+	if err == nil || !regexp.MustCompile(`error whilst reading credentials file`).MatchString(err.Error()) {
+		t.Errorf("expected an error reading a missing credentials file, got %v", err)
+	}
+}
+
+func TestCredentialsFileComments(t *testing.T) {
+	profiles, err := parseCredentials(`# Traffic managers
+machine vtm1.example.com # the primary
+  login admin#1
+  password #secret#   # not part of the password
+	# indented comment
+default login fallback password fall#back`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []credentialsProfile{
+		{machine: "vtm1.example.com", login: "admin#1", password: "#secret#"},
+		{login: "fallback", password: "fall#back"},
+	}
+	if !reflect.DeepEqual(profiles, expected) {
+		t.Errorf("expected %+v, got %+v", expected, profiles)
+	}
+}
+
+func TestCredentialsFileReadableByOthers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions aren't checked on Windows")
+	}
+	cases := map[os.FileMode]bool{0600: false, 0400: false, 0700: false, 0640: true, 0604: true, 0644: true}
+	for mode, expected := range cases {
+		if credentialsFileReadableByOthers(mode) != expected {
+			t.Errorf("%s: expected readable by others to be %t", mode, expected)
+		}
+	}
+}
+
+func TestCredentialProcess(t *testing.T) {
+	script := writeTestFile(t, "credentials.sh", "#!/bin/sh\necho \"{\\\"username\\\": \\\"process\\\", \\\"password\\\": \\\"$PULSEVTM_SERVER\\\"}\"\n", 0700)
+	defer os.RemoveAll(filepath.Dir(script))
+	path := writeTestFile(t, "credentials", testCredentialsFile, 0600)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	params, err := credentialSources{file: path, process: script}.resolve(api.Params{Server: "https://vtm1.example.com:9070"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.Username != "process" || params.Password != "https://vtm1.example.com:9070" {
+		t.Errorf("expected the credentials printed by credential_process to take precedence, got %s/%s", params.Username, params.Password)
+	}
+
+	params, err = credentialSources{process: script}.resolve(api.Params{Server: "https://vtm1.example.com:9070", Username: "admin", Password: "configured"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.Username != "admin" || params.Password != "configured" {
+		t.Errorf("expected configured credentials to take precedence, got %s/%s", params.Username, params.Password)
+	}
+
+	cases := map[string]string{
+		"echo not json":            "must print a JSON object",
+		"echo '{}'":                "printed neither a username nor a password",
+		"echo oops >&2; exit 1":    "credential_process failed: .*oops",
+		"/nonexistent/credentials": "credential_process failed",
+	}
+	for command, expected := range cases {
+		_, err := credentialSources{process: command}.resolve(api.Params{Server: "https://vtm1.example.com:9070"})
+		if err == nil || !regexp.MustCompile(expected).MatchString(err.Error()) {
+			t.Errorf("%q: expected an error matching %q, got %v", command, expected, err)
+		}
+	}
+}
+
+func TestProviderAuthenticatesWithCredentialsFile(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.handlers["/api/tm"] = func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if username != "admin" || password != "from-file" {
+			server.writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error_id": "auth.invalid"})
+			return
+		}
+		server.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"children": []interface{}{map[string]interface{}{"name": "5.1", "href": "/api/tm/5.1/"}},
+		})
+	}
+	path := writeTestFile(t, "credentials", "machine "+server.Listener.Addr().String()+" login admin password from-file\n", 0600)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	err := Provider().(*pulseVTMProvider).Configure(testResourceConfig(t, map[string]interface{}{
+		"vtm_server":           server.URL,
+		"allow_unverified_ssl": true,
+		"credentials_file":     path,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxySchemes : the schemes of the proxies the REST API can be reached through
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// validateProxyURL : validates proxy_url is the URL of an HTTP or SOCKS5 proxy
+func validateProxyURL(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+	_, err := parseProxyURL(value)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q %v", k, err))
+	}
+	return
+}
+
+// parseProxyURL : parses the URL of an HTTP or SOCKS5 proxy
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("must be a valid URL: %v", err)
+	}
+	if !proxySchemes[parsed.Scheme] || parsed.Host == "" {
+		return nil, fmt.Errorf("must be a URL of the form http://, https://, socks5:// or socks5h:// followed by the proxy's host and port, got %s", proxyURL)
+	}
+	return parsed, nil
+}
+
+// restProxy : returns the function choosing the proxy requests to the REST API are made through. Without a proxy_url
+// the standard HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.
+func restProxy(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	parsed, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] PulseVTM proxy_url %v", err)
+	}
+	return http.ProxyURL(parsed), nil
+}
@@ -0,0 +1,204 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
+)
+
+// safetyBackup : a full backup taken before the first write of an apply, so a failed apply can be rolled back
+type safetyBackup struct {
+	trafficManager   string
+	namePrefix       string
+	keep             int
+	restoreOnFailure bool
+
+	mu sync.Mutex
+	// name is the name of the backup once it's been taken
+	name string
+	// err is the error taking the backup failed with, or the backup having been restored, which every write then fails
+	// with
+	err error
+	// restored is whether the backup has been restored following a failed write
+	restored bool
+}
+
+// safetyBackupSchema : returns the schema of the provider's safety backup settings
+func safetyBackupSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Take a full backup before the first change of an apply, to roll back to should a change fail",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"traffic_manager": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "local_tm",
+					Description: "The traffic manager the backup is taken on",
+				},
+				"name_prefix": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "terraform-safety",
+					Description: "Prefix of the names of safety backups, which are followed by the time they're taken",
+				},
+				"keep": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      5,
+					ValidateFunc: util.ValidateUnsignedInteger,
+					Description:  "Number of safety backups to keep, older ones being deleted. 0 keeps them all",
+				},
+				"restore_on_failure": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Restore the backup when a change fails, after which no further changes are made and state must be refreshed. Otherwise the command to restore it is given in the error",
+				},
+			},
+		},
+	}
+}
+
+// newSafetyBackup : returns the safety backup of an apply from the provider's settings
+func newSafetyBackup(settings map[string]interface{}) *safetyBackup {
+	return &safetyBackup{
+		trafficManager:   settings["traffic_manager"].(string),
+		namePrefix:       settings["name_prefix"].(string),
+		keep:             settings["keep"].(int),
+		restoreOnFailure: settings["restore_on_failure"].(bool),
+	}
+}
+
+// take : takes the backup on every cluster unless it's already been taken, then prunes old safety backups. Once the
+// backup has been restored it fails, so no write is made after the restore.
+func (backup *safetyBackup) take(m interface{}) error {
+	backup.mu.Lock()
+	defer backup.mu.Unlock()
+	if backup.name != "" || backup.err != nil {
+		return backup.err
+	}
+
+	name := backup.namePrefix + "-" + time.Now().UTC().Format("20060102T150405Z")
+	for _, meta := range clusterMetas(m) {
+		client := meta.(map[string]interface{})["statusClient"].(*api.Client)
+		_, err := client.CreateBackup(backup.trafficManager, name, "Taken by Terraform before applying changes")
+		if err != nil {
+			backup.err = fmt.Errorf("[ERROR] PulseVTM error whilst taking safety backup %s on %s, no changes have been made: %v", name, safetyBackupServer(meta), err)
+			return backup.err
+		}
+		log.Printf("[INFO] PulseVTM safety backup %s taken on %s", name, safetyBackupServer(meta))
+
+		err = backup.prune(client)
+		if err != nil {
+			log.Printf("[WARN] PulseVTM error whilst deleting old safety backups on %s: %v", safetyBackupServer(meta), err)
+		}
+	}
+	backup.name = name
+	return nil
+}
+
+// prune : deletes the oldest safety backups on a traffic manager, keeping the configured number
+func (backup *safetyBackup) prune(client *api.Client) error {
+	if backup.keep == 0 {
+		return nil
+	}
+	backups, err := listBackups(client, backup.trafficManager)
+	if err != nil {
+		return err
+	}
+	safetyBackups := make([]string, 0)
+	for _, existing := range backups {
+		if name := existing["name"].(string); strings.HasPrefix(name, backup.namePrefix+"-") {
+			safetyBackups = append(safetyBackups, name)
+		}
+	}
+	for len(safetyBackups) > backup.keep {
+		err := client.DeleteBackup(backup.trafficManager, safetyBackups[0])
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] PulseVTM old safety backup %s deleted", safetyBackups[0])
+		safetyBackups = safetyBackups[1:]
+	}
+	return nil
+}
+
+// onFailure : restores the backup following a failed write when configured to, otherwise adds the commands to
+// restore it to the write's error
+func (backup *safetyBackup) onFailure(m interface{}, writeErr error) error {
+	backup.mu.Lock()
+	defer backup.mu.Unlock()
+	if backup.name == "" {
+		return writeErr
+	}
+	if backup.restored {
+		return wrapErrorf(writeErr, "%v\nThe configuration has already been restored from safety backup %s", writeErr, backup.name)
+	}
+
+	commands := make([]string, 0)
+	for _, meta := range clusterMetas(m) {
+		commands = append(commands, backup.restoreCommand(meta))
+	}
+	if !backup.restoreOnFailure {
+		return wrapErrorf(writeErr, "%v\nThe configuration from before the apply can be restored from safety backup %s with:\n\t%s", writeErr, backup.name, strings.Join(commands, "\n\t"))
+	}
+
+	for i, meta := range clusterMetas(m) {
+		client := meta.(map[string]interface{})["statusClient"].(*api.Client)
+		_, err := client.RestoreBackup(backup.trafficManager, backup.name)
+		if err != nil {
+			return wrapErrorf(writeErr, "%v\nRestoring safety backup %s on %s failed: %v\nIt can be restored with:\n\t%s", writeErr, backup.name, safetyBackupServer(meta), err, commands[i])
+		}
+		log.Printf("[INFO] PulseVTM safety backup %s restored on %s", backup.name, safetyBackupServer(meta))
+	}
+	backup.restored = true
+	// Changes made after the restore would be applied to a configuration Terraform's state no longer describes
+	backup.err = fmt.Errorf("[ERROR] PulseVTM configuration has been restored from safety backup %s after a change failed, "+
+		"so no further changes are made. Refresh state with terraform refresh before planning again", backup.name)
+	return wrapErrorf(writeErr, "%v\nThe configuration has been restored from safety backup %s. Refresh state with terraform refresh before planning again", writeErr, backup.name)
+}
+
+// restoreCommand : returns the command restoring the backup on the traffic manager a meta is connected to
+func (backup *safetyBackup) restoreCommand(meta interface{}) string {
+	config := meta.(map[string]interface{})
+	return fmt.Sprintf(
+		"curl -k -u %s -X PUT -H 'Content-Type: application/json' -d '{\"properties\":{}}' '%s/api/tm/%s/status/%s/backups/full/%s?restore'",
+		config["username"], strings.TrimSuffix(safetyBackupServer(meta), "/"), config["apiVersion"], backup.trafficManager, backup.name,
+	)
+}
+
+// safetyBackupServer : returns the server a meta is connected to
+func safetyBackupServer(meta interface{}) string {
+	server, _ := meta.(map[string]interface{})["server"].(string)
+	return server
+}
+
+// applyWithSafetyBackup : applies the planned change of a resource, which changes configuration unless it's a backup,
+// taking the safety backup before the first change of an apply when the provider is configured to take one, and rolling
+// back to it should the change fail
+func applyWithSafetyBackup(resourceType string, s *terraform.InstanceState, m interface{}, apply func() (*terraform.InstanceState, error)) (*terraform.InstanceState, error) {
+	config, _ := m.(map[string]interface{})
+	backup, ok := config["safetyBackup"].(*safetyBackup)
+	if !ok || resourceType == "pulsevtm_backup" {
+		return apply()
+	}
+	err := backup.take(m)
+	if err != nil {
+		return s, err
+	}
+	state, err := apply()
+	if err != nil {
+		return state, backup.onFailure(m, err)
+	}
+	return state, nil
+}
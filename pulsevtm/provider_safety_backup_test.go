@@ -0,0 +1,179 @@
+package pulsevtm
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+// testSafetyBackupProvider : returns a provider taking safety backups configured against the test server, which
+// holds an empty pool
+func testSafetyBackupProvider(t *testing.T, server *testVTMServer, settings map[string]interface{}) *pulseVTMProvider {
+	server.setResource("pools/web", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{"nodes_table": []interface{}{}},
+		},
+	})
+	provider := Provider().(*pulseVTMProvider)
+	err := provider.Configure(testResourceConfig(t, map[string]interface{}{
+		"vtm_user":             "admin",
+		"vtm_password":         "password",
+		"vtm_server":           server.URL,
+		"api_version":          "5.1",
+		"allow_unverified_ssl": true,
+		"safety_backup":        []interface{}{settings},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error configuring provider: %v", err)
+	}
+	return provider
+}
+
+// testSafetyBackups : returns the names of the safety backups on the test server
+func testSafetyBackups(server *testVTMServer) []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	names := make([]string, 0)
+	for key := range server.backups {
+		if strings.HasPrefix(key, "local_tm/terraform-safety-") {
+			names = append(names, strings.TrimPrefix(key, "local_tm/"))
+		}
+	}
+	return names
+}
+
+func testCreatePoolNode(t *testing.T, provider *pulseVTMProvider, node string) error {
+	_, err := testProviderApply(t, provider, "pulsevtm_pool_node", nil, map[string]interface{}{"pool": "web", "node": node})
+	return err
+}
+
+func TestSafetyBackupTakenOnceBeforeFirstWrite(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	provider := testSafetyBackupProvider(t, server, map[string]interface{}{})
+
+	if backups := testSafetyBackups(server); len(backups) != 0 {
+		t.Fatalf("expected no backup before the first write, got %v", backups)
+	}
+	for _, node := range []string{"10.0.0.1:80", "10.0.0.2:80"} {
+		err := testCreatePoolNode(t, provider, node)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	backups := testSafetyBackups(server)
+	if len(backups) != 1 || !regexp.MustCompile(`^terraform-safety-\d{8}T\d{6}Z$`).MatchString(backups[0]) {
+		t.Errorf("expected a single safety backup for the apply, got %v", backups)
+	}
+	// Backups are taken with the status client, leaving the configuration client working with configuration resources
+	if client := provider.Meta().(map[string]interface{})["jsonClient"].(*api.Client); strings.Contains(client.RootPath, "/status") {
+		t.Errorf("expected the configuration client to be left alone, got root path %s", client.RootPath)
+	}
+}
+
+func TestSafetyBackupPrunesOldBackups(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	for i, name := range []string{"terraform-safety-1", "terraform-safety-2", "terraform-safety-3", "before-upgrade"} {
+		server.backups["local_tm/"+name] = map[string]interface{}{"properties": map[string]interface{}{
+			"backup": map[string]interface{}{"time_stamp": float64(1400000000 + i)},
+		}}
+	}
+	provider := testSafetyBackupProvider(t, server, map[string]interface{}{"keep": 2})
+
+	err := testCreatePoolNode(t, provider, "10.0.0.1:80")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	backups := testSafetyBackups(server)
+	if len(backups) != 2 {
+		t.Errorf("expected 2 safety backups to be kept, got %v", backups)
+	}
+	for _, deleted := range []string{"terraform-safety-1", "terraform-safety-2"} {
+		if _, exists := server.backups["local_tm/"+deleted]; exists {
+			t.Errorf("expected old safety backup %s to be deleted", deleted)
+		}
+	}
+	for _, kept := range []string{"terraform-safety-3", "before-upgrade"} {
+		if _, exists := server.backups["local_tm/"+kept]; !exists {
+			t.Errorf("expected backup %s to be kept", kept)
+		}
+	}
+}
+
+func TestSafetyBackupOnFailedWrite(t *testing.T) {
+	for _, restoreOnFailure := range []bool{false, true} {
+		server := newTestVTMServer(t)
+		provider := testSafetyBackupProvider(t, server, map[string]interface{}{"restore_on_failure": restoreOnFailure})
+		server.handlers[testVTMConfigPath+"pools/web"] = func(w http.ResponseWriter, r *http.Request) {
+			server.writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"error_id":   "resource.internal_error",
+				"error_text": "Failed to save the configuration",
+			})
+		}
+
+		err := testCreatePoolNode(t, provider, "10.0.0.1:80")
+		if err == nil {
+			t.Fatalf("expected the write to fail")
+		}
+		backups := testSafetyBackups(server)
+		if len(backups) != 1 {
+			t.Fatalf("expected a safety backup, got %v", backups)
+		}
+
+		if restoreOnFailure {
+			if len(server.restored) != 1 || server.restored[0] != "local_tm/"+backups[0] {
+				t.Errorf("expected safety backup %s to be restored, got %v", backups[0], server.restored)
+			}
+			if !regexp.MustCompile(`has been restored from safety backup ` + backups[0]).MatchString(err.Error()) {
+				t.Errorf("expected the error to say the backup was restored, got %v", err)
+			}
+			if !regexp.MustCompile(`Refresh state`).MatchString(err.Error()) {
+				t.Errorf("expected the error to say state must be refreshed, got %v", err)
+			}
+
+			// No further change is made once the backup has been restored, even one which would succeed
+			delete(server.handlers, testVTMConfigPath+"pools/web")
+			err = testCreatePoolNode(t, provider, "10.0.0.2:80")
+			if err == nil || !regexp.MustCompile(`no further changes are made`).MatchString(err.Error()) {
+				t.Errorf("expected further changes to be refused, got %v", err)
+			}
+			if _, ok := testClusterPoolNodeWeight(server, "10.0.0.2:80"); ok {
+				t.Errorf("expected the node not to be added after the restore")
+			}
+			if len(server.restored) != 1 {
+				t.Errorf("expected the backup to be restored only once, got %v", server.restored)
+			}
+		} else {
+			if len(server.restored) != 0 {
+				t.Errorf("expected no restore, got %v", server.restored)
+			}
+			command := regexp.QuoteMeta("curl -k -u admin -X PUT -H 'Content-Type: application/json' -d '{\"properties\":{}}' '" +
+				server.URL + "/api/tm/5.1/status/local_tm/backups/full/" + backups[0] + "?restore'")
+			if !regexp.MustCompile(command).MatchString(err.Error()) {
+				t.Errorf("expected the error to give the restore command, got %v", err)
+			}
+		}
+		server.Close()
+	}
+}
+
+func TestSafetyBackupFailurePreventsWrites(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	provider := testSafetyBackupProvider(t, server, map[string]interface{}{})
+	server.backupsUnavailable = true
+
+	for _, node := range []string{"10.0.0.1:80", "10.0.0.2:80"} {
+		err := testCreatePoolNode(t, provider, node)
+		if err == nil || !regexp.MustCompile(`error whilst taking safety backup .*no changes have been made`).MatchString(err.Error()) {
+			t.Errorf("expected the write to be refused without a safety backup, got %v", err)
+		}
+	}
+	if nodes := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{}); len(nodes) != 0 {
+		t.Errorf("expected no changes to be made, got nodes %v", nodes)
+	}
+}
@@ -11,14 +11,15 @@ var testAccProviders map[string]terraform.ResourceProvider
 var testAccProvider *schema.Provider
 
 func init() {
-	testAccProvider = Provider().(*schema.Provider)
+	provider := Provider().(*pulseVTMProvider)
+	testAccProvider = provider.Provider
 	testAccProviders = map[string]terraform.ResourceProvider{
-		"pulsevtm": testAccProvider,
+		"pulsevtm": provider,
 	}
 }
 
 func TestProvider(t *testing.T) {
-	if err := Provider().(*schema.Provider).InternalValidate(); err != nil {
+	if err := Provider().(*pulseVTMProvider).InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
 	}
 
@@ -0,0 +1,71 @@
+package pulsevtm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// readPEMOrFile : returns PEM encoded data given either the data itself or the path of a file holding it
+func readPEMOrFile(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(value)
+}
+
+// buildTLSConfig : returns the TLS configuration for connections to the REST API, trusting the given CA certificates
+// in addition to the system's and presenting a client certificate when one is given. nil is returned when none of
+// the TLS options are set, leaving the client to build its configuration from allow_unverified_ssl.
+func buildTLSConfig(allowUnverifiedSSL bool, caCertFile, caCertPEM, clientCert, clientKey string) (*tls.Config, error) {
+	if caCertFile == "" && caCertPEM == "" && clientCert == "" && clientKey == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: allowUnverifiedSSL}
+
+	if caCertFile != "" || caCertPEM != "" {
+		caCerts := []byte(caCertPEM)
+		if caCertFile != "" {
+			fileCACerts, err := ioutil.ReadFile(caCertFile)
+			if err != nil {
+				return nil, fmt.Errorf("[ERROR] PulseVTM error whilst reading ca_cert_file %s: %v", caCertFile, err)
+			}
+			caCerts = append(append(caCerts, '\n'), fileCACerts...)
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			log.Printf("[WARN] PulseVTM unable to load the system certificate pool, only the configured CA certificates will be trusted: %v", err)
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caCerts) {
+			return nil, fmt.Errorf("[ERROR] PulseVTM no certificates could be parsed from ca_cert_file or ca_cert_pem")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return nil, fmt.Errorf("[ERROR] PulseVTM client_cert and client_key must be set together")
+		}
+		certPEM, err := readPEMOrFile(clientCert)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] PulseVTM error whilst reading client_cert: %v", err)
+		}
+		keyPEM, err := readPEMOrFile(clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] PulseVTM error whilst reading client_key: %v", err)
+		}
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] PulseVTM client_cert and client_key don't form a valid key pair: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,77 @@
+package pulsevtm
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// newRESTTransport : returns the transport shared by every REST API client of a provider instance, keeping
+// connections alive so each request doesn't cost a TLS handshake. Requests are made through the proxy chosen by proxy,
+// which may be nil for none. When maxConnectionsPerHost isn't 0 the requests in progress to each host, and so the
+// connections open to it, are limited to that number.
+func newRESTTransport(tlsConfig *tls.Config, allowUnverifiedSSL bool, proxy func(*http.Request) (*url.URL, error), maxIdleConnections, maxConnectionsPerHost int, idleConnectionTimeout time.Duration) http.RoundTripper {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: allowUnverifiedSSL}
+	}
+	transport := &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		Proxy:               proxy,
+		MaxIdleConns:        maxIdleConnections,
+		MaxIdleConnsPerHost: maxIdleConnections,
+		IdleConnTimeout:     idleConnectionTimeout,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	if maxConnectionsPerHost == 0 {
+		return transport
+	}
+	return &hostLimitedTransport{transport: transport, limit: maxConnectionsPerHost, hosts: make(map[string]chan struct{})}
+}
+
+// hostLimitedTransport : a transport limiting the requests in progress to each host. A request is in progress until
+// its response body is closed, so the connections open to a host are limited too, as http.Transport can't limit them
+// itself in the Go versions the provider is built with.
+type hostLimitedTransport struct {
+	transport *http.Transport
+	limit     int
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// RoundTrip : makes the request once fewer than the limit of requests to its host are in progress
+func (limited *hostLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limited.mu.Lock()
+	slots, ok := limited.hosts[req.URL.Host]
+	if !ok {
+		slots = make(chan struct{}, limited.limit)
+		limited.hosts[req.URL.Host] = slots
+	}
+	limited.mu.Unlock()
+
+	slots <- struct{}{}
+	res, err := limited.transport.RoundTrip(req)
+	if err != nil {
+		<-slots
+		return nil, err
+	}
+	res.Body = &releasingBody{ReadCloser: res.Body, release: func() { <-slots }}
+	return res, nil
+}
+
+// releasingBody : a response body freeing the slot of its request when it's closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close : closes the body and frees the slot of its request
+func (body *releasingBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
@@ -0,0 +1,149 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+// testPlanResources is the number of resources read by the simulated plan
+const testPlanResources = 50
+
+// newHandshakeCountingServer : starts a REST API stand-in which counts the TLS connections made to it, and so the
+// handshakes clients have to perform
+func newHandshakeCountingServer() (*httptest.Server, *int64) {
+	var connections int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"properties":{"basic":{"port":80,"pool":"web"}}}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&connections, 1)
+		}
+	}
+	server.StartTLS()
+	return server, &connections
+}
+
+// simulatePlanReads : reads a virtual server per resource of a plan, as refreshing its state would
+func simulatePlanReads(server *httptest.Server, transport http.RoundTripper) error {
+	client, err := api.Connect(api.Params{
+		APIVersion: "5.1",
+		Server:     server.URL,
+		IgnoreSSL:  true,
+		Timeout:    5,
+		Transport:  transport,
+	})
+	if err != nil {
+		return err
+	}
+	client.WorkWithConfigurationResources()
+	for i := 0; i < testPlanResources; i++ {
+		virtualServer := make(map[string]interface{})
+		err := client.GetByName("virtual_servers", fmt.Sprintf("vs-%d", i), &virtualServer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSharedTransportReusesConnections(t *testing.T) {
+	server, connections := newHandshakeCountingServer()
+	defer server.Close()
+
+	err := simulatePlanReads(server, newRESTTransport(nil, true, nil, 10, 0, 30*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handshakes := atomic.LoadInt64(connections); handshakes != 1 {
+		t.Errorf("expected a single handshake for %d reads over a shared transport, got %d", testPlanResources, handshakes)
+	}
+}
+
+func TestPerRequestTransportHandshakesEveryRequest(t *testing.T) {
+	server, connections := newHandshakeCountingServer()
+	defer server.Close()
+
+	err := simulatePlanReads(server, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handshakes := atomic.LoadInt64(connections); handshakes != testPlanResources {
+		t.Errorf("expected %d handshakes without a shared transport, got %d", testPlanResources, handshakes)
+	}
+}
+
+// BenchmarkPlanReads compares the handshakes, logged per operation, and time taken by the reads of a plan with
+// a transport per request and with a transport shared by the provider
+func BenchmarkPlanReads(b *testing.B) {
+	benchmarks := map[string]func() http.RoundTripper{
+		"PerRequestTransport": func() http.RoundTripper { return nil },
+		"SharedTransport": func() http.RoundTripper {
+			return newRESTTransport(nil, true, nil, 10, 0, 30*time.Second)
+		},
+	}
+	for name, transport := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			server, connections := newHandshakeCountingServer()
+			defer server.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := simulatePlanReads(server, transport())
+				if err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+			b.StopTimer()
+			b.Logf("%.2f handshakes/op", float64(atomic.LoadInt64(connections))/float64(b.N))
+		})
+	}
+}
+
+func TestTransportLimitsConnectionsPerHost(t *testing.T) {
+	var inProgress, maxInProgress int64
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt64(&inProgress, 1)
+		defer atomic.AddInt64(&inProgress, -1)
+		for {
+			seen := atomic.LoadInt64(&maxInProgress)
+			if current <= seen || atomic.CompareAndSwapInt64(&maxInProgress, seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"properties":{}}`))
+	}))
+	defer server.Close()
+	transport := newRESTTransport(nil, true, nil, 10, 2, 30*time.Second)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- simulatePlanReads(server, transport)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if maxInProgress > 2 {
+		t.Errorf("expected at most 2 requests in progress, got %d", maxInProgress)
+	}
+}
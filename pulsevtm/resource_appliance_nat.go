@@ -5,7 +5,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
@@ -167,7 +167,7 @@ func resourceApplianceNatCreate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("appliance/nat", "", natResource, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Appliance/Nat error whilst creating: %s", err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Appliance/Nat error whilst creating: %v", err)
 	}
 	d.SetId("appliance_nat")
 	return resourceApplianceNatRead(d, m)
@@ -217,7 +217,7 @@ func resourceApplianceNatUpdate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("appliance/nat", "", natResource, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM ApplianceNat error whilst creating: %s", err)
+		return wrapErrorf(err, "[ERROR] PulseVTM ApplianceNat error whilst creating: %v", err)
 	}
 	return resourceApplianceNatRead(d, m)
 }
@@ -242,7 +242,7 @@ func resourceApplianceNatDelete(d *schema.ResourceData, m interface{}) error {
 	natResource["properties"] = properties
 	err := client.Set("appliance/nat", "", natResource, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM ApplianceNat error whilst deleting all NAT rules: %s", err)
+		return wrapErrorf(err, "[ERROR] PulseVTM ApplianceNat error whilst deleting all NAT rules: %v", err)
 	}
 	d.SetId("")
 	return nil
@@ -3,7 +3,7 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"net/http"
 )
@@ -85,7 +85,7 @@ func resourceAptimizerProfileCreate(d *schema.ResourceData, m interface{}) error
 
 	err := client.Set("aptimizer/profiles", name, aptimizerProfileConfig, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst creating Aptimizer Profile %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst creating Aptimizer Profile %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceAptimizerProfileRead(d, m)
@@ -145,7 +145,7 @@ func resourceAptimizerProfileUpdate(d *schema.ResourceData, m interface{}) error
 
 	err := client.Set("aptimizer/profiles", d.Id(), aptimizerProfileConfig, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst updating Aptimizer Profile %s: %v", d.Id(), err)
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst updating Aptimizer Profile %s: %v", d.Id(), err)
 	}
 
 	return resourceAptimizerProfileRead(d, m)
@@ -0,0 +1,249 @@
+package pulsevtm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func resourceBackup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceBackupCreate,
+		Read:   resourceBackupRead,
+		Update: resourceBackupUpdate,
+		Delete: resourceBackupDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the full backup",
+			},
+			"traffic_manager": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "local_tm",
+				Description: "The traffic manager the backup is taken on",
+			},
+			"description": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"source_path"},
+				Description:   "A description of the backup. Backups uploaded from source_path keep the description they were taken with",
+			},
+			"source_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Path of a local tar archive of a full backup to upload as the backup, rather than taking one",
+			},
+			"download_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Local path to download the backup to as a tar archive",
+			},
+			"archive_sha256": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SHA-256 checksum of the tar archive of the backup last downloaded to download_path, or uploaded from source_path",
+			},
+			"time_stamp": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The time the backup was taken, in seconds since the epoch",
+			},
+			"time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The time the backup was taken, in RFC 3339 format",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the traffic manager the backup was taken on",
+			},
+		},
+	}
+}
+
+// backupID : returns the ID of a backup, made up of the traffic manager it's taken on and its name
+func backupID(trafficManager, name string) string {
+	return trafficManager + "/" + name
+}
+
+// parseBackupID : returns the traffic manager and name of a backup from its ID
+func parseBackupID(id string) (string, string, error) {
+	idParts := strings.SplitN(id, "/", 2)
+	if len(idParts) != 2 {
+		return "", "", fmt.Errorf("[ERROR] PulseVTM backup ID %s is not in the format <traffic_manager>/<name>", id)
+	}
+	return idParts[0], idParts[1], nil
+}
+
+// backupProperties : returns the metadata of a backup in the form it's held in state
+func backupProperties(backup map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{"description": "", "time_stamp": 0, "time": "", "version": ""}
+	backupProperties, _ := backup["properties"].(map[string]interface{})
+	backupInfo, _ := backupProperties["backup"].(map[string]interface{})
+
+	if description, ok := backupInfo["description"].(string); ok {
+		properties["description"] = description
+	}
+	if version, ok := backupInfo["version"].(string); ok {
+		properties["version"] = version
+	}
+	if timeStamp, ok := backupInfo["time_stamp"].(float64); ok {
+		properties["time_stamp"] = int(timeStamp)
+		properties["time"] = time.Unix(int64(timeStamp), 0).UTC().Format(time.RFC3339)
+	}
+	return properties
+}
+
+func resourceBackupCreate(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["statusClient"].(*api.Client)
+
+	trafficManager := d.Get("traffic_manager").(string)
+	name := d.Get("name").(string)
+
+	if sourcePath, ok := d.GetOk("source_path"); ok {
+		checksum, err := uploadBackup(client, trafficManager, name, sourcePath.(string))
+		if err != nil {
+			return err
+		}
+		d.Set("archive_sha256", checksum)
+	} else {
+		_, err := client.CreateBackup(trafficManager, name, d.Get("description").(string))
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst creating backup %s on %s: %v", name, trafficManager, err)
+		}
+	}
+	d.SetId(backupID(trafficManager, name))
+
+	if downloadPath, ok := d.GetOk("download_path"); ok {
+		checksum, err := downloadBackup(client, trafficManager, name, downloadPath.(string))
+		if err != nil {
+			return err
+		}
+		d.Set("archive_sha256", checksum)
+	}
+	return resourceBackupRead(d, m)
+}
+
+func resourceBackupUpdate(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["statusClient"].(*api.Client)
+
+	if downloadPath, ok := d.GetOk("download_path"); ok && d.HasChange("download_path") {
+		trafficManager, name, err := parseBackupID(d.Id())
+		if err != nil {
+			return err
+		}
+		checksum, err := downloadBackup(client, trafficManager, name, downloadPath.(string))
+		if err != nil {
+			return err
+		}
+		d.Set("archive_sha256", checksum)
+	}
+	return resourceBackupRead(d, m)
+}
+
+// downloadBackup : downloads a backup to a local tar archive, returning its SHA-256 checksum. The archive is only
+// moved into place once it's been downloaded in full.
+func downloadBackup(client *api.Client, trafficManager, name, path string) (string, error) {
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM error whilst creating %s to download backup %s to: %v", path, name, err)
+	}
+	defer os.Remove(file.Name())
+
+	checksum := sha256.New()
+	err = client.DownloadBackup(trafficManager, name, io.MultiWriter(file, checksum))
+	closeErr := file.Close()
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM error whilst downloading backup %s on %s: %v", name, trafficManager, err)
+	}
+	if closeErr != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM error whilst writing backup %s to %s: %v", name, path, closeErr)
+	}
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM error whilst writing backup %s to %s: %v", name, path, err)
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+// uploadBackup : uploads a local tar archive as a backup, returning the archive's SHA-256 checksum
+func uploadBackup(client *api.Client, trafficManager, name, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM error whilst opening backup archive %s: %v", path, err)
+	}
+	defer file.Close()
+
+	checksum := sha256.New()
+	err = client.UploadBackup(trafficManager, name, io.TeeReader(file, checksum))
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM error whilst uploading %s as backup %s on %s: %v", path, name, trafficManager, err)
+	}
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+func resourceBackupRead(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["statusClient"].(*api.Client)
+
+	trafficManager, name, err := parseBackupID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	backup, err := client.GetBackup(trafficManager, name)
+	if client.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst retrieving backup %s on %s: %v", name, trafficManager, err)
+	}
+
+	d.Set("name", name)
+	d.Set("traffic_manager", trafficManager)
+	for key, value := range backupProperties(backup) {
+		err := d.Set(key, value)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM backup error whilst setting attribute %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func resourceBackupDelete(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["statusClient"].(*api.Client)
+
+	trafficManager, name, err := parseBackupID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = client.DeleteBackup(trafficManager, name)
+	if err != nil && client.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst deleting backup %s on %s: %v", name, trafficManager, err)
+	}
+	d.SetId("")
+	return nil
+}
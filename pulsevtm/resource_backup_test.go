@@ -0,0 +1,260 @@
+package pulsevtm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAccPulseVTMBackupBasic(t *testing.T) {
+
+	backupName := acctest.RandomWithPrefix("acctest_pulsevtm_backup")
+	resourceName := "pulsevtm_backup.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMBackupCheckDestroy(state, backupName)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPulseVTMBackupTemplate(backupName, "taken by acceptance tests"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMBackupExists(backupName, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", backupName),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", "local_tm"),
+					resource.TestCheckResourceAttr(resourceName, "description", "taken by acceptance tests"),
+					resource.TestCheckResourceAttrSet(resourceName, "time_stamp"),
+					resource.TestMatchResourceAttr(resourceName, "time", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)),
+					resource.TestCheckResourceAttrSet(resourceName, "version"),
+					resource.TestMatchResourceAttr("data.pulsevtm_backups.acctest", "backups.#", regexp.MustCompile(`^[1-9]`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccPulseVTMBackupCheckDestroy(state *terraform.State, name string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_backup" {
+			continue
+		}
+		_, err := client.GetBackup("local_tm", name)
+		client.WorkWithConfigurationResources()
+		if client.StatusCode == http.StatusOK {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: backup %s still exists", name)
+		}
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: backup %+v ", err)
+	}
+	return nil
+}
+
+func testAccPulseVTMBackupExists(name, resourceName string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("[ERROR] Not found: %s", resourceName)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("[ERROR] No ID is set")
+		}
+
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+		_, err := client.GetBackup("local_tm", name)
+		client.WorkWithConfigurationResources()
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving backup: %+v", err)
+		}
+		return nil
+	}
+}
+
+func testAccPulseVTMBackupTemplate(name, description string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_backup" "acctest" {
+  name = "%s"
+  description = "%s"
+}
+
+data "pulsevtm_backups" "acctest" {
+  traffic_manager = "${pulsevtm_backup.acctest.traffic_manager}"
+}
+`, name, description)
+}
+
+func TestBackupLifecycle(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	backup := resourceBackup()
+
+	d := schema.TestResourceDataRaw(t, backup.Schema, map[string]interface{}{
+		"name":        "before-upgrade",
+		"description": "taken before upgrading",
+	})
+	err := backup.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Id() != "local_tm/before-upgrade" {
+		t.Errorf("unexpected ID %s", d.Id())
+	}
+	expected := map[string]interface{}{
+		"description": "taken before upgrading",
+		"time_stamp":  1500000000,
+		"time":        "2017-07-14T02:40:00Z",
+		"version":     "17.2",
+	}
+	for key, value := range expected {
+		if d.Get(key) != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, d.Get(key))
+		}
+	}
+	// Backups are status resources, handled by the status client so the configuration client is never switched to them
+	if rootPath := m["jsonClient"].(*api.Client).RootPath; strings.Contains(rootPath, "/status") {
+		t.Errorf("expected the configuration client to be left alone, got root path %s", rootPath)
+	}
+
+	// A second backup, and one on another traffic manager which isn't listed
+	for _, raw := range []map[string]interface{}{
+		{"name": "nightly"},
+		{"name": "other", "traffic_manager": "vtm2"},
+	} {
+		err := backup.Create(schema.TestResourceDataRaw(t, backup.Schema, raw), m)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	backups := dataSourceBackups()
+	list := schema.TestResourceDataRaw(t, backups.Schema, map[string]interface{}{})
+	err = backups.Read(list, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list.Get("backups.#").(int) != 2 || list.Get("backups.0.name") != "before-upgrade" || list.Get("backups.1.name") != "nightly" {
+		t.Errorf("expected the backups on local_tm oldest first, got %v", list.Get("backups"))
+	}
+
+	err = backup.Delete(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, exists := server.backups["local_tm/before-upgrade"]; exists {
+		t.Errorf("expected the backup to be deleted")
+	}
+
+	// A backup deleted outside of Terraform is removed from state
+	d.SetId("local_tm/before-upgrade")
+	err = backup.Read(d, m)
+	if err != nil || d.Id() != "" {
+		t.Errorf("expected a missing backup to be removed from state, got ID %q: %v", d.Id(), err)
+	}
+}
+
+func TestBackupDownloadAndUploadBetweenClusters(t *testing.T) {
+	source := newTestVTMServer(t)
+	defer source.Close()
+	destination := newTestVTMServer(t)
+	defer destination.Close()
+	directory, err := ioutil.TempDir("", "pulsevtm-backup")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+	archivePath := filepath.Join(directory, "nightly.tar")
+	backup := resourceBackup()
+
+	d := schema.TestResourceDataRaw(t, backup.Schema, map[string]interface{}{
+		"name":          "nightly",
+		"download_path": archivePath,
+	})
+	err = backup.Create(d, source.meta(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	archive, err := ioutil.ReadFile(archivePath)
+	if err != nil {
+		t.Fatalf("expected the backup to be downloaded: %v", err)
+	}
+	if !bytes.Equal(archive, source.backupArchives["local_tm/nightly"]) {
+		t.Errorf("expected the downloaded archive to match the backup")
+	}
+	checksum := sha256.Sum256(archive)
+	if d.Get("archive_sha256") != hex.EncodeToString(checksum[:]) {
+		t.Errorf("expected archive_sha256 %x, got %s", checksum, d.Get("archive_sha256"))
+	}
+	if files, _ := ioutil.ReadDir(directory); len(files) != 1 {
+		t.Errorf("expected only the archive to be left in %s, got %d files", directory, len(files))
+	}
+
+	uploaded := schema.TestResourceDataRaw(t, backup.Schema, map[string]interface{}{
+		"name":        "migrated",
+		"source_path": archivePath,
+	})
+	err = backup.Create(uploaded, destination.meta(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(destination.backupArchives["local_tm/migrated"], archive) {
+		t.Errorf("expected the archive to be uploaded as backup migrated")
+	}
+	if uploaded.Get("archive_sha256") != d.Get("archive_sha256") {
+		t.Errorf("expected the checksum of the uploaded archive to match the downloaded one, got %s", uploaded.Get("archive_sha256"))
+	}
+	if uploaded.Get("description") != "uploaded" || uploaded.Get("time_stamp").(int) == 0 {
+		t.Errorf("expected the metadata of the uploaded backup to be read, got description %q", uploaded.Get("description"))
+	}
+}
+
+func TestBackupArchiveErrors(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	directory, err := ioutil.TempDir("", "pulsevtm-backup")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+	notAnArchive := filepath.Join(directory, "not-an-archive.tar")
+	ioutil.WriteFile(notAnArchive, []byte("not a tar archive"), 0600)
+
+	_, err = uploadBackup(m["jsonClient"].(*api.Client), "local_tm", "invalid", notAnArchive)
+	if err == nil || !regexp.MustCompile(`error whilst uploading .*not-an-archive.tar as backup invalid.*The backup archive is invalid`).MatchString(err.Error()) {
+		t.Errorf("expected an error uploading an invalid archive, got %v", err)
+	}
+	_, err = uploadBackup(m["jsonClient"].(*api.Client), "local_tm", "missing", filepath.Join(directory, "missing.tar"))
+	if err == nil || !regexp.MustCompile(`error whilst opening backup archive`).MatchString(err.Error()) {
+		t.Errorf("expected an error uploading a missing archive, got %v", err)
+	}
+
+	archivePath := filepath.Join(directory, "missing.tar")
+	_, err = downloadBackup(m["jsonClient"].(*api.Client), "local_tm", "missing", archivePath)
+	if err == nil || !regexp.MustCompile(`error whilst downloading backup missing on local_tm: .*Backup does not exist`).MatchString(err.Error()) {
+		t.Errorf("expected an error downloading a missing backup, got %v", err)
+	}
+	if files, _ := ioutil.ReadDir(directory); len(files) != 1 {
+		t.Errorf("expected no partial download to be left in %s, got %d files", directory, len(files))
+	}
+}
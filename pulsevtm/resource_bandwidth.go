@@ -3,7 +3,7 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"net/http"
 	"regexp"
 )
@@ -80,7 +80,7 @@ func resourceBandwidthCreate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("bandwidth", name, &bandwidthConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Bandwidth error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Bandwidth error whilst creating %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceBandwidthRead(d, m)
@@ -149,7 +149,7 @@ func resourceBandwidthUpdate(d *schema.ResourceData, m interface{}) error {
 		client := config["jsonClient"].(*api.Client)
 		err := client.Set("bandwidth", name, &bandwidthConfiguration, nil)
 		if err != nil {
-			return fmt.Errorf("[ERROR] PulseVTM Bandwidth error whilst creating %s: %v", name, err)
+			return wrapErrorf(err, "[ERROR] PulseVTM Bandwidth error whilst creating %s: %v", name, err)
 		}
 	}
 	d.SetId(name)
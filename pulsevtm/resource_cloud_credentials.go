@@ -3,7 +3,7 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"net/http"
 )
@@ -103,7 +103,7 @@ func resourceCloudCredentialsCreate(d *schema.ResourceData, m interface{}) error
 
 	err := client.Set("cloud_api_credentials", name, &cloudCredentialsConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst creating Cloud API Credentials %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst creating Cloud API Credentials %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceCloudCredentialsRead(d, m)
@@ -144,7 +144,7 @@ func resourceCloudCredentialsUpdate(d *schema.ResourceData, m interface{}) error
 
 	err := client.Set("cloud_api_credentials", name, &cloudCredentialsConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst updating Cloud API Credentials %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst updating Cloud API Credentials %s: %v", name, err)
 	}
 
 	return resourceCloudCredentialsRead(d, m)
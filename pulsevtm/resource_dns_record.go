@@ -0,0 +1,304 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/mutexkv"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// dnsZoneFileMutexKV serialises changes made to the records of the same zone file by this provider
+var dnsZoneFileMutexKV = mutexkv.NewMutexKV()
+
+// dnsZoneSerialClock : returns the time serials in the date format are moved on to
+var dnsZoneSerialClock = time.Now
+
+func resourceDNSRecord() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceDNSRecordCreate,
+		Read:   resourceDNSRecordRead,
+		Update: resourceDNSRecordUpdate,
+		Delete: resourceDNSRecordDelete,
+
+		Schema: map[string]*schema.Schema{
+			"zone_file": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the existing DNS zone file the record is in. The zone file resource should ignore changes to its dns_zone_config",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateDNSRecordName,
+				Description:  "The name of the record, relative to the origin at the end of the zone file unless it ends with a dot. @ is the origin itself",
+			},
+			"type": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDNSRecordType,
+				DiffSuppressFunc: suppressCaseDiff,
+				Description:      "The type of the record, such as A, CNAME or MX",
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntBetween(0, 2147483647),
+				Description:  "The TTL of the record in seconds. Defaults to 0, leaving the record with the default TTL of the zone file",
+			},
+			"rdata": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDNSRecordData,
+				DiffSuppressFunc: suppressDNSRecordDataDiff,
+				Description:      "The data of the record as it's written in a zone file, such as 10 mail.example.com. for an MX record",
+			},
+		},
+	}
+}
+
+// validateDNSRecordName : check the name of a record is a single word of a zone file
+func validateDNSRecordName(v interface{}, k string) (ws []string, errors []error) {
+	name := v.(string)
+	if name == "" || strings.ContainsAny(name, " \t\r\n;()\"") || strings.HasPrefix(name, "$") {
+		errors = append(errors, fmt.Errorf("[ERROR] %q must be a domain name, relative or ending with a dot, or @", k))
+	}
+	return
+}
+
+// validateDNSRecordType : check the type of a record is valid, and isn't SOA as its serial is managed by the provider
+func validateDNSRecordType(v interface{}, k string) (ws []string, errors []error) {
+	ws, errors = util.ValidateZoneRecordType(v, k)
+	if strings.EqualFold(v.(string), "SOA") {
+		errors = append(errors, fmt.Errorf("[ERROR] %q can't be SOA, the SOA record belongs to the zone file", k))
+	}
+	return
+}
+
+// validateDNSRecordData : check the data of a record can be written in a zone file
+func validateDNSRecordData(v interface{}, k string) (ws []string, errors []error) {
+	_, err := util.ParseZoneRecordData(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not valid record data: %v", k, err))
+	}
+	return
+}
+
+// checkDNSRecordConfig : checks when planning that the data of a record is valid for its type
+func checkDNSRecordConfig(s *terraform.InstanceState, c *terraform.ResourceConfig) error {
+	recordType, typeKnown := plannedString(c, "type")
+	rdata, rdataKnown := plannedString(c, "rdata")
+	if !typeKnown || !rdataKnown {
+		return nil
+	}
+	// Malformed values are reported by the attributes' own validation
+	data, err := util.ParseZoneRecordData(rdata)
+	if _, errors := validateDNSRecordType(recordType, "type"); err != nil || len(errors) > 0 {
+		return nil
+	}
+	err = util.CheckZoneRecordData(recordType, data)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM DNS %s record %v %v", strings.ToUpper(recordType), c.Config["name"], err)
+	}
+	return nil
+}
+
+// suppressCaseDiff : suppresses the diff of a value differing only in case
+func suppressCaseDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+// suppressDNSRecordDataDiff : suppresses the diff of record data differing only in whitespace
+func suppressDNSRecordDataDiff(k, old, new string, d *schema.ResourceData) bool {
+	oldData, err := util.ParseZoneRecordData(old)
+	if err != nil {
+		return false
+	}
+	newData, err := util.ParseZoneRecordData(new)
+	return err == nil && strings.Join(oldData, " ") == strings.Join(newData, " ")
+}
+
+// getDNSZoneFile : retrieves and parses a zone file
+func getDNSZoneFile(client *api.Client, zoneFile string) (*util.Zone, string, error) {
+	zoneConfig := new([]byte)
+	client.WorkWithConfigurationResources()
+	err := client.GetByName("dns_server/zone_files", zoneFile, zoneConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	zone, err := util.ParseZone(string(*zoneConfig), "")
+	if err != nil {
+		return nil, "", fmt.Errorf("[ERROR] PulseVTM DNS zone file %s can't be parsed: %v", zoneFile, err)
+	}
+	return zone, string(*zoneConfig), nil
+}
+
+// modifyDNSZoneFile : applies a change to the records of a zone file, and bumps the serial of its SOA record when
+// it's changed. The REST API has no conditional writes, so the zone file is read and written whilst holding its lock,
+// which every writer of zone files in this provider takes. This only orders the writes made by this provider: changes
+// made to the zone file outside of it, or by another Terraform run, between the read and the write are lost.
+func modifyDNSZoneFile(client *api.Client, zoneFile string, modify func(*util.Zone) bool) error {
+	dnsZoneFileMutexKV.Lock(zoneFile)
+	defer dnsZoneFileMutexKV.Unlock(zoneFile)
+
+	zone, _, err := getDNSZoneFile(client, zoneFile)
+	if err != nil {
+		return err
+	}
+	if !modify(zone) {
+		return nil
+	}
+	serial, ok, err := zone.BumpSerial(dnsZoneSerialClock())
+	if err != nil {
+		return err
+	}
+	if ok {
+		log.Printf("[DEBUG] PulseVTM DNS zone file %s serial bumped to %d", zoneFile, serial)
+	}
+	return client.Set("dns_server/zone_files", zoneFile, []byte(zone.String()), nil)
+}
+
+// dnsRecordData : returns the data of a record as the words it's written with
+func dnsRecordData(d *schema.ResourceData) []string {
+	data, _ := util.ParseZoneRecordData(d.Get("rdata").(string))
+	return data
+}
+
+// dnsRecordTTL : returns the TTL of a record, which is zero when it has the default TTL of the zone file
+func dnsRecordTTL(record *util.ZoneRecord) int {
+	if !record.ExplicitTTL {
+		return 0
+	}
+	return record.TTL
+}
+
+func resourceDNSRecordCreate(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["octetClient"].(*api.Client)
+
+	zoneFile := d.Get("zone_file").(string)
+	name := d.Get("name").(string)
+	recordType := strings.ToUpper(d.Get("type").(string))
+	ttl := d.Get("ttl").(int)
+	data := dnsRecordData(d)
+
+	err := modifyDNSZoneFile(client, zoneFile, func(zone *util.Zone) bool {
+		record := zone.FindRecord(name, recordType, data)
+		if record == nil {
+			zone.AddRecord(name, ttl, recordType, data)
+			return true
+		}
+		// A record already in the zone file is adopted as it is, apart from its TTL
+		if dnsRecordTTL(record) != ttl {
+			zone.SetRecordTTL(record, ttl)
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM DNS zone file error whilst adding %s record %s to %s: %v", recordType, name, zoneFile, err)
+	}
+	d.SetId(strings.Join([]string{zoneFile, name, recordType, strings.Join(data, " ")}, "/"))
+
+	return resourceDNSRecordRead(d, m)
+}
+
+func resourceDNSRecordRead(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["octetClient"].(*api.Client)
+
+	zoneFile := d.Get("zone_file").(string)
+	name := d.Get("name").(string)
+	recordType := d.Get("type").(string)
+
+	zone, _, err := getDNSZoneFile(client, zoneFile)
+	if client.StatusCode == http.StatusNotFound {
+		log.Printf("[WARN] PulseVTM DNS zone file %s not found", zoneFile)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM DNS zone file error whilst reading %s: %v", zoneFile, err)
+	}
+
+	record := zone.FindRecord(name, recordType, dnsRecordData(d))
+	if record == nil {
+		log.Printf("[WARN] PulseVTM %s record %s not found in DNS zone file %s", recordType, name, zoneFile)
+		d.SetId("")
+		return nil
+	}
+	err = d.Set("ttl", dnsRecordTTL(record))
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM DNS record error whilst setting attribute ttl: %v", err)
+	}
+	return nil
+}
+
+func resourceDNSRecordUpdate(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["octetClient"].(*api.Client)
+
+	zoneFile := d.Get("zone_file").(string)
+	name := d.Get("name").(string)
+	recordType := d.Get("type").(string)
+	ttl := d.Get("ttl").(int)
+	data := dnsRecordData(d)
+
+	if d.HasChange("ttl") {
+		err := modifyDNSZoneFile(client, zoneFile, func(zone *util.Zone) bool {
+			record := zone.FindRecord(name, recordType, data)
+			if record == nil {
+				zone.AddRecord(name, ttl, recordType, data)
+				return true
+			}
+			if dnsRecordTTL(record) == ttl {
+				return false
+			}
+			zone.SetRecordTTL(record, ttl)
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM DNS zone file error whilst updating %s record %s in %s: %v", recordType, name, zoneFile, err)
+		}
+	}
+	return resourceDNSRecordRead(d, m)
+}
+
+func resourceDNSRecordDelete(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["octetClient"].(*api.Client)
+
+	zoneFile := d.Get("zone_file").(string)
+	name := d.Get("name").(string)
+	recordType := d.Get("type").(string)
+	data := dnsRecordData(d)
+
+	err := modifyDNSZoneFile(client, zoneFile, func(zone *util.Zone) bool {
+		record := zone.FindRecord(name, recordType, data)
+		if record == nil {
+			return false
+		}
+		zone.RemoveRecord(record)
+		return true
+	})
+	if err != nil {
+		if client.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("[ERROR] PulseVTM DNS zone file error whilst removing %s record %s from %s: %v", recordType, name, zoneFile, err)
+	}
+	d.SetId("")
+	return nil
+}
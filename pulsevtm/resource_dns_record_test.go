@@ -0,0 +1,324 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testDNSZoneConfig = `$ORIGIN example.com.
+$TTL 3600
+@                       30  IN  SOA example.com. hostmaster.isp.example.com. (
+                                    2017092901 ; serial
+                                    3600       ; refresh after 1 hour
+                                    300        ; retry after 5 minutes
+                                    1209600    ; expire after 2 weeks
+                                    30 )       ; minimum TTL of 30 seconds
+;
+; Services - Each service in a location has a unique IP address. Two locations = two IPs.
+;
+example-service         60  IN  A   10.100.10.5
+                        60  IN  A   10.100.20.5
+another-example-service             60  IN  A   10.100.10.6
+`
+
+func TestAccPulseVTMDNSRecordBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+	dnsZoneFileName := fmt.Sprintf("acctest_pulsevtm_dns_record-%d", randomInt)
+	resourceName := "pulsevtm_dns_record.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMDNSRecordCheckDestroy(state, dnsZoneFileName)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPulseVTMDNSRecordInvalidTemplate(dnsZoneFileName),
+				ExpectError: regexp.MustCompile(`can't be SOA`),
+			},
+			{
+				Config: testAccPulseVTMDNSRecordTemplate(dnsZoneFileName, 0),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMDNSRecordExists(dnsZoneFileName, "www", "10.100.30.5"),
+					resource.TestCheckResourceAttr(resourceName, "name", "www"),
+					resource.TestCheckResourceAttr(resourceName, "type", "a"),
+					resource.TestCheckResourceAttr(resourceName, "ttl", "0"),
+				),
+			},
+			{
+				Config: testAccPulseVTMDNSRecordTemplate(dnsZoneFileName, 120),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMDNSRecordExists(dnsZoneFileName, "www", "10.100.30.5"),
+					resource.TestCheckResourceAttr(resourceName, "ttl", "120"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPulseVTMDNSRecordCheckDestroy(state *terraform.State, zoneFile string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["octetClient"].(*api.Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_dns_record" {
+			continue
+		}
+		zone, _, err := getDNSZoneFile(client, zoneFile)
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: %+v", err)
+		}
+		if zone.FindRecord(rs.Primary.Attributes["name"], rs.Primary.Attributes["type"], []string{rs.Primary.Attributes["rdata"]}) != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: DNS record %s still exists", rs.Primary.ID)
+		}
+	}
+	return nil
+}
+
+func testAccPulseVTMDNSRecordExists(zoneFile, name, data string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["octetClient"].(*api.Client)
+
+		zone, _, err := getDNSZoneFile(client, zoneFile)
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving DNS zone file %s: %+v", zoneFile, err)
+		}
+		if zone.FindRecord(name, "A", []string{data}) == nil {
+			return fmt.Errorf("[ERROR] Pulse vTM DNS record %s not found in zone file %s", name, zoneFile)
+		}
+		return nil
+	}
+}
+
+func testAccPulseVTMDNSRecordInvalidTemplate(zoneFile string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_dns_record" "acctest" {
+  zone_file = "%s"
+  name = "@"
+  type = "SOA"
+  rdata = "example.com. hostmaster.example.com. 1 3600 300 1209600 30"
+}
+`, zoneFile)
+}
+
+func testAccPulseVTMDNSRecordTemplate(zoneFile string, ttl int) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_dns_zone_file" "acctest" {
+  name = "%s"
+  dns_zone_config = <<DNS_ZONE_CONFIG
+%sDNS_ZONE_CONFIG
+
+  lifecycle {
+    ignore_changes = ["dns_zone_config"]
+  }
+}
+
+resource "pulsevtm_dns_record" "acctest" {
+  zone_file = "${pulsevtm_dns_zone_file.acctest.name}"
+  name = "www"
+  type = "a"
+  ttl = %d
+  rdata = "10.100.30.5"
+}
+`, zoneFile, testDNSZoneConfig, ttl)
+}
+
+func TestDNSRecordLifecycle(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	defer func(clock func() time.Time) { dnsZoneSerialClock = clock }(dnsZoneSerialClock)
+	dnsZoneSerialClock = func() time.Time { return time.Date(2017, 9, 29, 12, 0, 0, 0, time.UTC) }
+	server.files["dns_server/zone_files/example.com.db"] = []byte(testDNSZoneConfig)
+	dnsRecord := resourceDNSRecord()
+
+	d := schema.TestResourceDataRaw(t, dnsRecord.Schema, map[string]interface{}{
+		"zone_file": "example.com.db",
+		"name":      "www",
+		"type":      "cname",
+		"rdata":     "example-service",
+	})
+	err := dnsRecord.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zoneConfig := string(server.files["dns_server/zone_files/example.com.db"])
+	expected := strings.Replace(testDNSZoneConfig, "2017092901 ; serial", "2017092902 ; serial", 1) + "www.example.com.\tCNAME\texample-service\n"
+	if zoneConfig != expected {
+		t.Errorf("expected the record to be added and the serial bumped, leaving the rest as it was, got\n%s", zoneConfig)
+	}
+	if d.Id() != "example.com.db/www/CNAME/example-service" || d.Get("ttl").(int) != 0 {
+		t.Errorf("unexpected ID %s and TTL %d", d.Id(), d.Get("ttl").(int))
+	}
+
+	// The serial moves on to the date once it's later
+	dnsZoneSerialClock = func() time.Time { return time.Date(2018, 1, 2, 12, 0, 0, 0, time.UTC) }
+	updated := schema.TestResourceDataRaw(t, dnsRecord.Schema, map[string]interface{}{
+		"zone_file": "example.com.db",
+		"name":      "www",
+		"type":      "cname",
+		"ttl":       300,
+		"rdata":     "example-service",
+	})
+	updated.SetId(d.Id())
+	err = dnsRecord.Update(updated, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zoneConfig = string(server.files["dns_server/zone_files/example.com.db"])
+	if !strings.Contains(zoneConfig, "www.example.com.\t300\tCNAME\texample-service\n") || !strings.Contains(zoneConfig, "2018010200 ; serial") {
+		t.Errorf("expected the TTL to be changed and the serial bumped to the date, got\n%s", zoneConfig)
+	}
+
+	// A record removed outside of Terraform is removed from state
+	server.files["dns_server/zone_files/example.com.db"] = []byte(testDNSZoneConfig)
+	err = dnsRecord.Read(updated, m)
+	if err != nil || updated.Id() != "" {
+		t.Errorf("expected the record to be removed from state, got %q: %v", updated.Id(), err)
+	}
+
+	// Removing the first of several records of a name leaves the rest with their owner
+	existing := schema.TestResourceDataRaw(t, dnsRecord.Schema, map[string]interface{}{
+		"zone_file": "example.com.db",
+		"name":      "example-service.example.com.",
+		"type":      "A",
+		"ttl":       60,
+		"rdata":     "10.100.10.5",
+	})
+	err = dnsRecord.Create(existing, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(server.files["dns_server/zone_files/example.com.db"]) != testDNSZoneConfig {
+		t.Errorf("expected a record already in the zone file to be adopted, got\n%s", server.files["dns_server/zone_files/example.com.db"])
+	}
+	err = dnsRecord.Delete(existing, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zoneConfig = string(server.files["dns_server/zone_files/example.com.db"])
+	if strings.Contains(zoneConfig, "10.100.10.5") || !strings.Contains(zoneConfig, "example-service.example.com.\t60\tIN\tA\t10.100.20.5\n") {
+		t.Errorf("expected the record to be removed and the next given its owner, got\n%s", zoneConfig)
+	}
+}
+
+func TestDNSRecordSetConcurrently(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.files["dns_server/zone_files/example.com.db"] = []byte(testDNSZoneConfig)
+	m := server.meta(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, resourceDNSRecord().Schema, map[string]interface{}{
+				"zone_file": "example.com.db",
+				"name":      fmt.Sprintf("host%d", i),
+				"type":      "A",
+				"rdata":     fmt.Sprintf("10.100.30.%d", i),
+			})
+			errs <- resourceDNSRecordCreate(d, m)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	server.mu.Lock()
+	zoneConfig := string(server.files["dns_server/zone_files/example.com.db"])
+	server.mu.Unlock()
+	for i := 0; i < 10; i++ {
+		if !strings.Contains(zoneConfig, fmt.Sprintf("10.100.30.%d\n", i)) {
+			t.Errorf("expected every record to be kept, host%d is missing from\n%s", i, zoneConfig)
+		}
+	}
+}
+
+func TestDNSZoneFileSetWaitsForDNSRecordChanges(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	zoneFile := func() string {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return string(server.files["dns_server/zone_files/example.com.db"])
+	}
+	d := schema.TestResourceDataRaw(t, resourceDNSZoneFile().Schema, map[string]interface{}{
+		"name":            "example.com.db",
+		"dns_zone_config": testDNSZoneConfig,
+	})
+
+	// The zone file isn't written whilst one of its records is being changed
+	dnsZoneFileMutexKV.Lock("example.com.db")
+	done := make(chan error, 1)
+	go func() {
+		done <- resourceDNSZoneFileCreate(d, m)
+	}()
+	select {
+	case err := <-done:
+		dnsZoneFileMutexKV.Unlock("example.com.db")
+		t.Fatalf("expected the zone file to wait for the zone file lock, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if zoneFile() != "" {
+		t.Errorf("expected the zone file not to be written whilst locked, got\n%s", zoneFile())
+	}
+	dnsZoneFileMutexKV.Unlock("example.com.db")
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if zoneFile() != testDNSZoneConfig {
+		t.Errorf("expected the zone file to be written once unlocked, got\n%s", zoneFile())
+	}
+}
+
+func TestDNSRecordValidation(t *testing.T) {
+	for _, recordType := range []string{"SOA", "IN", "1A", ""} {
+		if _, errs := validateDNSRecordType(recordType, "type"); len(errs) == 0 {
+			t.Errorf("expected an error for record type %q", recordType)
+		}
+	}
+	for _, name := range []string{"", "two words", "$ORIGIN"} {
+		if _, errs := validateDNSRecordName(name, "name"); len(errs) == 0 {
+			t.Errorf("expected an error for name %q", name)
+		}
+	}
+	if _, errs := validateDNSRecordData(`"unterminated`, "rdata"); len(errs) == 0 {
+		t.Errorf("expected an error for an unterminated quoted string")
+	}
+	_, err := Provider().(*pulseVTMProvider).Diff(&terraform.InstanceInfo{Type: "pulsevtm_dns_record"}, nil,
+		testResourceConfig(t, map[string]interface{}{"zone_file": "example.com.db", "name": "@", "type": "mx", "rdata": "mail"}))
+	if err == nil || !strings.Contains(err.Error(), "MX record @ has 1 fields of data rather than 2") {
+		t.Errorf("expected an error planning an MX record without a preference, got %v", err)
+	}
+	if _, errs := validateDNSRecordType("WKS", "type"); len(errs) == 0 {
+		t.Errorf("expected an error for an unknown record type")
+	}
+	if !suppressDNSRecordDataDiff("rdata", "10 mail", " 10\tmail ", nil) {
+		t.Errorf("expected data differing only in whitespace to show no diff")
+	}
+}
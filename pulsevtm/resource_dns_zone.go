@@ -2,10 +2,13 @@ package pulsevtm
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
 func resourceDNSZone() *schema.Resource {
@@ -36,6 +39,39 @@ func resourceDNSZone() *schema.Resource {
 	}
 }
 
+// checkDNSZoneOrigin : checks when planning that the zone file a zone names on the traffic manager is for its origin.
+// A zone referring to a zone file resource isn't checked, as that zone file may be replaced in this run; its records
+// are validated with it.
+func checkDNSZoneOrigin(c *terraform.ResourceConfig, m interface{}) error {
+	origin, originKnown := plannedString(c, "origin")
+	zoneFile, zoneFileKnown := plannedString(c, "zone_file")
+	if !originKnown || !zoneFileKnown || origin == "" || zoneFile == "" || plannedReference(c, "zone_file", "pulsevtm_dns_zone_file") {
+		return nil
+	}
+
+	config, ok := m.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	client, ok := config["octetClient"].(*api.Client)
+	if !ok {
+		return nil
+	}
+	zoneFileConfig := new([]byte)
+	client.WorkWithConfigurationResources()
+	err := client.GetByName("dns_server/zone_files", zoneFile, zoneFileConfig)
+	if err != nil {
+		log.Printf("[DEBUG] PulseVTM DNS zone file %s isn't checked against the origin %s: %v", zoneFile, origin, err)
+		return nil
+	}
+	err = util.CheckZoneOrigin(string(*zoneFileConfig), origin)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM DNS zone %v origin %s doesn't match zone file %s on the traffic manager, which "+
+			"is checked as the zone doesn't refer to a pulsevtm_dns_zone_file resource: %v", c.Config["name"], origin, zoneFile, err)
+	}
+	return nil
+}
+
 func resourceDNSZoneCreate(d *schema.ResourceData, m interface{}) error {
 
 	var name string
@@ -60,7 +96,7 @@ func resourceDNSZoneCreate(d *schema.ResourceData, m interface{}) error {
 	res["properties"] = prop
 	err := client.Set("dns_server/zones", name, res, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM DNS zone error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM DNS zone error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
@@ -108,10 +144,11 @@ func resourceDNSZoneUpdate(d *schema.ResourceData, m interface{}) error {
 	props := make(map[string]interface{})
 	basic := make(map[string]interface{})
 
-	for _, key := range []string{"origin", "zone_file"} {
-		if d.HasChange(key) {
-			basic[key] = d.Get(key).(string)
-		}
+	if d.HasChange("origin") {
+		basic["origin"] = d.Get("origin").(string)
+	}
+	if d.HasChange("zone_file") {
+		basic["zonefile"] = d.Get("zone_file").(string)
 	}
 
 	props["basic"] = basic
@@ -121,7 +158,7 @@ func resourceDNSZoneUpdate(d *schema.ResourceData, m interface{}) error {
 	client := config["jsonClient"].(*api.Client)
 	err := client.Set("dns_server/zones", name, &res, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM DNS zone error whilst updating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM DNS zone error whilst updating %s: %v", name, err)
 	}
 
 	return resourceDNSZoneRead(d, m)
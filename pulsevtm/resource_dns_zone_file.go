@@ -3,7 +3,8 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"log"
 	"net/http"
 )
@@ -23,9 +24,11 @@ func resourceDNSZoneFile() *schema.Resource {
 				ForceNew:    true,
 			},
 			"dns_zone_config": {
-				Type:        schema.TypeString,
-				Description: "DNS zone configuration section",
-				Required:    true,
+				Type:             schema.TypeString,
+				Description:      "DNS zone configuration section, in the BIND format. The serial of the SOA record is bumped when it's changed without the serial being moved on",
+				Required:         true,
+				ValidateFunc:     util.ValidateZoneFile,
+				DiffSuppressFunc: util.SuppressZoneSerialDiff,
 			},
 		},
 	}
@@ -45,9 +48,12 @@ func resourceDNSZoneFileCreate(d *schema.ResourceData, m interface{}) error {
 		dnsZoneConfig = v.(string)
 	}
 
+	// The records of the zone file may be changed by the DNS record resources, which write it under the same lock
+	dnsZoneFileMutexKV.Lock(name)
 	err := client.Set("dns_server/zone_files", name, []byte(dnsZoneConfig), nil)
+	dnsZoneFileMutexKV.Unlock(name)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM DNS Zone File error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM DNS Zone File error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
@@ -78,6 +84,38 @@ func resourceDNSZoneFileRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
+// bumpDNSZoneFileSerial : moves the serial of the SOA record of a changed zone file on from the serial it had, unless
+// it has been already
+func bumpDNSZoneFileSerial(oldZoneConfig, newZoneConfig string) (string, error) {
+	oldZone, err := util.ParseZone(oldZoneConfig, "")
+	if err != nil {
+		return newZoneConfig, nil
+	}
+	oldSerial, ok, err := oldZone.Serial()
+	if !ok || err != nil {
+		return newZoneConfig, nil
+	}
+	newZone, err := util.ParseZone(newZoneConfig, "")
+	if err != nil {
+		return "", err
+	}
+	newSerial, ok, err := newZone.Serial()
+	if err != nil {
+		return "", err
+	}
+	// Serials are compared modulo 2^32, so one moved on is up to 2^31 after the other
+	if !ok || (newSerial != oldSerial && newSerial-oldSerial < 1<<31) {
+		return newZoneConfig, nil
+	}
+	serial := util.NextZoneSerial(oldSerial, dnsZoneSerialClock())
+	err = newZone.SetSerial(serial)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[INFO] PulseVTM DNS zone file serial bumped from %d to %d", oldSerial, serial)
+	return newZone.String(), nil
+}
+
 func resourceDNSZoneFileUpdate(d *schema.ResourceData, m interface{}) error {
 
 	config := m.(map[string]interface{})
@@ -88,16 +126,22 @@ func resourceDNSZoneFileUpdate(d *schema.ResourceData, m interface{}) error {
 	var zoneConfig string
 
 	if d.HasChange("dns_zone_config") {
-		if v, ok := d.GetOk("dns_zone_config"); ok {
-			zoneConfig = v.(string)
+		oldZoneConfig, newZoneConfig := d.GetChange("dns_zone_config")
+		zoneConfig = newZoneConfig.(string)
+		bumpedZoneConfig, err := bumpDNSZoneFileSerial(oldZoneConfig.(string), zoneConfig)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM DNS Zone File error whilst updating %s: %v", name, err)
 		}
+		zoneConfig = bumpedZoneConfig
 		hasChanges = true
 	}
 
 	if hasChanges {
+		dnsZoneFileMutexKV.Lock(name)
 		err := client.Set("dns_server/zone_files", name, []byte(zoneConfig), nil)
+		dnsZoneFileMutexKV.Unlock(name)
 		if err != nil {
-			return fmt.Errorf("[ERROR] PulseVTM DNS Zone File error whilst updating %s: %v", name, err)
+			return wrapErrorf(err, "[ERROR] PulseVTM DNS Zone File error whilst updating %s: %v", name, err)
 		}
 		err = d.Set("dns_zone_config", zoneConfig)
 		if err != nil {
@@ -5,10 +5,13 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"log"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAccPulseVTMDNSZoneFileBasic(t *testing.T) {
@@ -139,3 +142,167 @@ DNS_ZONE_CONFIG
 }
 `, name)
 }
+
+func TestDNSZoneFileParse(t *testing.T) {
+	zone, err := util.ParseZone(testDNSZoneConfig, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if zone.String() != testDNSZoneConfig {
+		t.Errorf("expected the zone file to be written as it was read, got\n%s", zone.String())
+	}
+	if zone.Origin() != "example.com." || zone.DefaultTTL != 3600 {
+		t.Errorf("unexpected origin %q and default TTL %d", zone.Origin(), zone.DefaultTTL)
+	}
+	records := zone.Records()
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(records))
+	}
+	soa := records[0]
+	if soa.Owner != "example.com." || soa.Type != "SOA" || soa.TTL != 30 || soa.Class != "IN" || len(soa.Data) != 7 || soa.Line != 3 {
+		t.Errorf("unexpected SOA record %+v", soa)
+	}
+	if records[2].Owner != "example-service.example.com." || records[2].Data[0] != "10.100.20.5" || records[2].Line != 13 {
+		t.Errorf("expected the record to have the owner of the one before, got %+v", records[2])
+	}
+	if serial, ok, err := zone.Serial(); serial != 2017092901 || !ok || err != nil {
+		t.Errorf("unexpected serial %d: %v", serial, err)
+	}
+
+	txt, err := util.ParseZone("txt 1h30m TXT \"v=spf1 ; -all\" (\n  \"second\" )\n", "example.com.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record := txt.Records()[0]; record.Owner != "txt.example.com." || record.TTL != 5400 || len(record.Data) != 2 || record.Data[0] != `"v=spf1 ; -all"` {
+		t.Errorf("unexpected TXT record %+v", record)
+	}
+
+	for zoneConfig, expected := range map[string]string{
+		"www A 10.0.0.1\nmail A (\n10.0.0.2\n":    "line 2: parenthesis is never closed",
+		"www A 10.0.0.1 )\n":                      "line 1: unbalanced parenthesis",
+		"\n\nwww TXT \"unterminated\n":            "line 3: unterminated quoted string",
+		"  A 10.0.0.1\n":                          "line 1: record has no owner",
+		"www 3600\n":                              "line 1: record for www has no type",
+		"www IN\n":                                "line 1: record for www has no type",
+		"www A\n":                                 "line 1: A record for www has no data",
+		"www 3600 IN 60 A 10.0.0.1\n":             "line 1: record is given a TTL twice",
+		"$INCLUDE other.db\n":                     "line 1: $INCLUDE is not supported",
+		"$TTL 1x\n":                               "line 1: \"1x\" is not a valid TTL",
+		"$ORIGIN\n":                               "line 1: $ORIGIN takes a single domain name",
+		"www 9999999999 A 10.0.0.1\n":             "is longer than the largest TTL",
+		"$ORIGIN example.com.\n$ORIGN example.\n": "line 2: unknown directive $ORIGN",
+	} {
+		_, errs := util.ValidateZoneFile(zoneConfig, "dns_zone_config")
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), expected) {
+			t.Errorf("expected an error containing %q for %q, got %v", expected, zoneConfig, errs)
+		}
+	}
+}
+
+func TestDNSZoneFileSerial(t *testing.T) {
+	now := time.Date(2017, 9, 29, 12, 0, 0, 0, time.UTC)
+	for serial, expected := range map[uint32]uint32{
+		2017092901: 2017092902,
+		2017092899: 2017092900,
+		2016010105: 2017092900,
+		41:         42,
+		4294967295: 0,
+	} {
+		if next := util.NextZoneSerial(serial, now); next != expected {
+			t.Errorf("expected serial %d to be followed by %d, got %d", serial, expected, next)
+		}
+	}
+
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	defer func(clock func() time.Time) { dnsZoneSerialClock = clock }(dnsZoneSerialClock)
+	dnsZoneSerialClock = func() time.Time { return now }
+	server.files["dns_server/zone_files/example.com.db"] = []byte(testDNSZoneConfig)
+	dnsZoneFile := resourceDNSZoneFile()
+	state := &terraform.InstanceState{ID: "example.com.db", Attributes: map[string]string{
+		"name": "example.com.db", "dns_zone_config": testDNSZoneConfig,
+	}}
+
+	// A change made without moving the serial on has the serial bumped
+	changed := strings.Replace(testDNSZoneConfig, "10.100.10.6", "10.100.10.7", 1)
+	config := testResourceConfig(t, map[string]interface{}{"name": "example.com.db", "dns_zone_config": changed})
+	diff, err := dnsZoneFile.Diff(state, config)
+	if err != nil || diff == nil {
+		t.Fatalf("expected a diff, got %v: %v", diff, err)
+	}
+	state, err = dnsZoneFile.Apply(state, diff, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := strings.Replace(changed, "2017092901 ; serial", "2017092902 ; serial", 1)
+	if string(server.files["dns_server/zone_files/example.com.db"]) != expected || state.Attributes["dns_zone_config"] != expected {
+		t.Errorf("expected the serial to be bumped, got\n%s", server.files["dns_server/zone_files/example.com.db"])
+	}
+	diff, err = dnsZoneFile.Diff(state, config)
+	if err != nil || (diff != nil && !diff.Empty()) {
+		t.Errorf("expected the bumped serial to show no diff, got %v: %v", diff, err)
+	}
+
+	// A change moving the serial on itself is left as it is
+	changed = strings.Replace(changed, "2017092901 ; serial", "2017092910 ; serial", 1)
+	changed = strings.Replace(changed, "10.100.10.7", "10.100.10.8", 1)
+	config = testResourceConfig(t, map[string]interface{}{"name": "example.com.db", "dns_zone_config": changed})
+	diff, _ = dnsZoneFile.Diff(state, config)
+	_, err = dnsZoneFile.Apply(state, diff, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(server.files["dns_server/zone_files/example.com.db"]) != changed {
+		t.Errorf("expected the zone file to be uploaded as it is, got\n%s", server.files["dns_server/zone_files/example.com.db"])
+	}
+}
+
+func TestDNSZoneFileValidation(t *testing.T) {
+	ws, errs := util.ValidateZoneFile(testDNSZoneConfig, "dns_zone_config")
+	if len(ws) != 0 || len(errs) != 0 {
+		t.Errorf("expected a valid zone file, got %v %v", ws, errs)
+	}
+
+	for record, expected := range map[string]string{
+		"www A 10.0.0.256":                             `A record for www.example.com. has "10.0.0.256" rather than an IPv4 address`,
+		"www AAAA 10.0.0.1":                            `AAAA record for www.example.com. has "10.0.0.1" rather than an IPv6 address`,
+		"www A 10.0.0.1 10.0.0.2":                      "has 2 fields of data rather than 1: address",
+		"@ MX mail":                                    "has 1 fields of data rather than 2: preference, exchange",
+		"@ MX 70000 mail":                              `has a preference "70000" which isn't a number from 0 to 65535`,
+		"www CNAME a..example.com.":                    `has a target "a..example.com." with an empty label`,
+		"_sip._tcp SRV 10 5 5060":                      "has 3 fields of data rather than 4",
+		"www WKS 10.0.0.1 6 80":                        "WKS record for www.example.com. has an unknown type WKS",
+		"www TYPE65 \\# 2 abcd":                        "",
+		"www TYPE65 \\# 3 abcd":                        "has data which isn't 3 bytes in hexadecimal",
+		"@ CAA 0 issue \"ca.example.net\"":             "",
+		"www TXT \"" + strings.Repeat("x", 256) + "\"": "has a string longer than 255 characters",
+		"www CNAME example-service\nwww A 10.0.0.1":    "line 16: A record for www.example.com. is alongside the CNAME record on line 15",
+		"@ SOA ns hostmaster 1 3600 300 1209600 30":    "line 15: SOA record for example.com. follows the SOA record on line 3",
+	} {
+		_, errs := util.ValidateZoneFile(testDNSZoneConfig+record+"\n", "dns_zone_config")
+		if expected == "" {
+			if len(errs) != 0 {
+				t.Errorf("expected %q to be valid, got %v", record, errs)
+			}
+			continue
+		}
+		// The records follow the 14 lines of the zone file
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), expected) || !regexp.MustCompile(`line 1[56]: `).MatchString(errs[0].Error()) {
+			t.Errorf("expected an error containing %q for %q, got %v", expected, record, errs)
+		}
+	}
+
+	// Each invalid record is reported, and a zone file without an SOA record is warned of
+	ws, errs = util.ValidateZoneFile("$ORIGIN example.com.\nwww A 10.0.0.1\nmail A mail\nftp A ftp\n", "dns_zone_config")
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "line 3:") || !strings.Contains(errs[1].Error(), "line 4:") {
+		t.Errorf("expected an error for lines 3 and 4, got %v", errs)
+	}
+	if len(ws) != 1 || !strings.Contains(ws[0], "no SOA record") {
+		t.Errorf("expected a warning of the missing SOA record, got %v", ws)
+	}
+	_, errs = util.ValidateZoneFile("$ORIGIN example..com.\n", "dns_zone_config")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "line 1: $ORIGIN example..com. is not a valid domain name") {
+		t.Errorf("expected an error for the $ORIGIN directive, got %v", errs)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -161,3 +163,76 @@ resource "pulsevtm_dns_zone" "acctest" {
 %s
 `, name, testAccPulseVTMDNSZonePrepare(dnsZoneFileName))
 }
+
+func TestDNSZoneOriginCheck(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	server.files["dns_server/zone_files/existing.db"] = []byte("@ 30 IN SOA ns.example.com. hostmaster.example.com. 1 3600 300 1209600 30\n" +
+		"www.example.org. A 10.0.0.1\n")
+
+	// The zone file on the traffic manager is checked, when it exists
+	config := testResourceConfig(t, map[string]interface{}{"name": "example", "origin": "example.com", "zone_file": "existing.db"})
+	err := checkDNSZoneOrigin(config, m)
+	if err == nil || !strings.Contains(err.Error(), "doesn't match zone file existing.db on the traffic manager") ||
+		!strings.Contains(err.Error(), "line 2: A record for www.example.org. is outside of the origin of the zone, example.com.") {
+		t.Errorf("expected an error for a record outside the zone, got %v", err)
+	}
+	config = testResourceConfig(t, map[string]interface{}{"name": "example", "origin": "example.com", "zone_file": "missing.db"})
+	if err := checkDNSZoneOrigin(config, m); err != nil {
+		t.Errorf("expected a zone file which doesn't exist yet not to be checked, got %v", err)
+	}
+
+	// A zone referring to a zone file resource isn't checked, as the zone file may be replaced in this run
+	config = testResourceConfig(t, map[string]interface{}{"name": "example", "origin": "example.com", "zone_file": "existing.db"})
+	config.Raw["zone_file"] = "${pulsevtm_dns_zone_file.existing.name}"
+	if err := checkDNSZoneOrigin(config, m); err != nil {
+		t.Errorf("expected a zone referring to a zone file resource not to be checked, got %v", err)
+	}
+
+	err = util.CheckZoneOrigin(testDNSZoneConfig, "example.org")
+	if err == nil || !strings.Contains(err.Error(), "line 1: $ORIGIN example.com. doesn't match the origin of the zone, example.org.") {
+		t.Errorf("expected an error for a zone file with another $ORIGIN, got %v", err)
+	}
+	err = util.CheckZoneOrigin("$TTL 60\nexample.org. 30 IN SOA ns.example.com. hostmaster.example.com. 1 3600 300 1209600 30\n", "example.com.")
+	if err == nil || !strings.Contains(err.Error(), "line 2: SOA record is for example.org. rather than the origin of the zone, example.com.") {
+		t.Errorf("expected an error for an SOA record for another origin, got %v", err)
+	}
+}
+
+func TestDNSZoneUpdateSendsZoneFile(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	zone := resourceDNSZone()
+
+	config := map[string]interface{}{"name": "example", "origin": "example.com", "zone_file": "example.com.db"}
+	diff, err := zone.Diff(nil, testResourceConfig(t, config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err := zone.Apply(nil, diff, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config["zone_file"] = "updated.example.com.db"
+	diff, err = zone.Diff(state, testResourceConfig(t, config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err = zone.Apply(state, diff, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	basic := server.getResource("dns_server/zones/example")["properties"].(map[string]interface{})["basic"].(map[string]interface{})
+	if basic["zonefile"] != "updated.example.com.db" {
+		t.Errorf("expected the zone file to be updated, got %v", basic)
+	}
+	if _, ok := basic["zone_file"]; ok {
+		t.Errorf("expected the zone file to be sent as zonefile, got %v", basic)
+	}
+	if state.Attributes["zone_file"] != "updated.example.com.db" {
+		t.Errorf("expected the updated zone file to be read, got %v", state.Attributes)
+	}
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
@@ -284,7 +284,7 @@ func resourceGLBSet(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("glb_services", name, res, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM GLB error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM GLB error whilst creating %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceGLBRead(d, m)
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
@@ -137,6 +137,1421 @@ func resourceGlobalSettings() *schema.Resource {
 					},
 				},
 			},
+			"admin": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cipher_suites": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The SSL ciphers to use for admin server connections. Leaving this empty will use the default cipher list.",
+						},
+						"honor_fallback_scsv": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not the admin server should honour the TLS_FALLBACK_SCSV cipher suite value.",
+						},
+						"ssl3_allow_rehandshake": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "rfc5746",
+							ValidateFunc: validation.StringInSlice([]string{"always", "never", "rfc5746", "safe"}, false),
+							Description:  "Whether or not SSL/TLS re-handshakes should be supported for admin server connections.",
+						},
+						"ssl3_diffie_hellman_key_length": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "dh_2048",
+							ValidateFunc: validation.StringInSlice([]string{"dh_1024", "dh_2048", "dh_3072", "dh_4096"}, false),
+							Description:  "The length in bits of the Diffie-Hellman key for ciphers that use Diffie-Hellman key agreement.",
+						},
+						"ssl_elliptic_curves": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The SSL elliptic curve preference list for admin server connections.",
+						},
+						"ssl_insert_extra_fragment": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSL3 and TLS1 connections to the admin server should send an empty fragment before the first data fragment.",
+						},
+						"ssl_max_handshake_message_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10240,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum size, in bytes, of a handshake message accepted by the admin server.",
+						},
+						"ssl_prevent_timing_side_channels": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the admin server should take extra care to prevent timing side channel attacks.",
+						},
+						"ssl_signature_algorithms": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The SSL signature algorithm preference list for admin server connections.",
+						},
+						"support_ssl2": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSLv2 is enabled for admin server connections.",
+						},
+						"support_ssl3": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSLv3 is enabled for admin server connections.",
+						},
+						"support_tls1": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not TLSv1.0 is enabled for admin server connections.",
+						},
+						"support_tls1_1": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not TLSv1.1 is enabled for admin server connections.",
+						},
+						"support_tls1_2": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not TLSv1.2 is enabled for admin server connections.",
+						},
+					},
+				},
+			},
+			"appliance": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"bootloader_password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The password used to protect the bootloader. An empty string means there will be no protection.",
+						},
+						"manage_ncipher": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the nCipher support software should be installed and managed by the appliance.",
+						},
+						"nethsm_esn": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The electronic serial number of the nCipher netHSM.",
+						},
+						"nethsm_hash": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The hash of the nCipher netHSM's public key.",
+						},
+						"nethsm_ip": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The IP address of the nCipher netHSM.",
+						},
+						"nethsm_ncipher_rfs": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The IP address of the nCipher Remote File System.",
+						},
+						"return_path_routing_enabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the appliance should use return path routing.",
+						},
+					},
+				},
+			},
+			"aptimizer": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cache_entry_lifetime": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3600,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, an Aptimizer cache entry should be kept.",
+						},
+						"cache_entry_limit": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of cache entries for Aptimizer.",
+						},
+						"default_profile": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "node_based_profile",
+							Description: "The Aptimizer profile used when none is specified.",
+						},
+						"default_scope": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "any_hostname",
+							Description: "The Aptimizer scope used when none is specified.",
+						},
+						"dependent_fetch_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, to wait for a dependent resource to be fetched.",
+						},
+						"enable_state_dump": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the Aptimizer state should be dumped on a fatal error.",
+						},
+						"expire_time": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, an idle Aptimizer worker should wait before exiting.",
+						},
+						"max_concurrent_jobs": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of concurrent Aptimizer jobs. A value of 0 (zero) means use the number of CPUs.",
+						},
+						"max_dependent_fetch_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "2MB",
+							Description: "The maximum size of a dependent resource that can be fetched.",
+						},
+						"max_original_content_buffer_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "2MB",
+							Description: "The maximum size of the original content buffered by Aptimizer.",
+						},
+						"queue_buffer_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5242880,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The size, in bytes, of the buffer used to queue Aptimizer jobs.",
+						},
+						"resource_lifetime": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, an optimized resource should be cached.",
+						},
+						"resource_memory_limit": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2048,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum memory, in MB, used to store optimized resources.",
+						},
+						"watchdog_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      300,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How often, in seconds, the Aptimizer watchdog should check workers.",
+						},
+						"watchdog_limit": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How many times the watchdog will restart a worker before giving up.",
+						},
+					},
+				},
+			},
+			"auditlog": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"via_eventd": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not configuration changes should be reported to the event log.",
+						},
+						"via_syslog": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not configuration changes should be reported to syslog.",
+						},
+					},
+				},
+			},
+			"auto_scaler": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"verbose": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the auto scaler should log detailed messages.",
+						},
+					},
+				},
+			},
+			"cluster_comms": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"allow_update_default": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "The default value of allow_update for new cluster members.",
+						},
+						"allowed_update_hosts": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Computed:    true,
+							Description: "The hosts that can contact the internal administration port on each traffic manager.",
+						},
+						"state_sync_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How often, in seconds, to propagate the session persistence and bandwidth information.",
+						},
+						"state_sync_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      6,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum amount of time, in seconds, to wait whilst propagating state information.",
+						},
+					},
+				},
+			},
+			"global_connection": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"idle_connections_max": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of unused HTTP keepalive connections with back-end nodes. A value of 0 (zero) means no limit.",
+						},
+						"idle_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, an unused HTTP keepalive connection should be kept before it is discarded.",
+						},
+						"listen_queue_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The listen queue size for managing incoming connections. A value of 0 (zero) means use the OS default.",
+						},
+						"max_accepting": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of child processes that listen for new connections at once. A value of 0 (zero) means all of them.",
+						},
+						"multiple_accept": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not child processes should accept multiple connections at a time.",
+						},
+					},
+				},
+			},
+			"dns": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_ttl": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      86400,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum time-to-live, in seconds, for a cached DNS response.",
+						},
+						"min_ttl": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      86400,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The minimum time-to-live, in seconds, for a cached DNS response.",
+						},
+						"negative_expiry": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      60,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, a failed DNS lookup should be cached for.",
+						},
+						"size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10867,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the DNS cache.",
+						},
+						"timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      12,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, to wait for a response from a DNS server.",
+						},
+					},
+				},
+			},
+			"ec2": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"access_key_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The Amazon EC2 access key ID used to interact with EC2.",
+						},
+						"awstool_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, the traffic manager should wait for the AWS tool to complete.",
+						},
+						"metadata_server": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "169.254.169.254",
+							Description: "The IP address of the EC2 metadata server.",
+						},
+						"query_server": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "ec2.amazonaws.com",
+							Description: "The hostname of the EC2 query server.",
+						},
+						"request_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, to wait for a response from the EC2 query server.",
+						},
+						"secret_access_key": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The Amazon EC2 secret access key used to interact with EC2.",
+						},
+						"verify_query_server_cert": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the certificate of the EC2 query server should be verified.",
+						},
+					},
+				},
+			},
+			"eventing": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"mail_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The minimum time, in seconds, between sending emails for an event.",
+						},
+						"max_attempts": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of times to attempt to send an alert before giving up.",
+						},
+					},
+				},
+			},
+			"fault_tolerance": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"arp_count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of ARP packets a traffic manager should send when an IP address is raised.",
+						},
+						"auto_failback": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not traffic IP addresses should automatically move back to their preferred traffic manager.",
+						},
+						"child_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, the traffic manager should wait for a child process to respond before considering it failed.",
+						},
+						"frontend_check_ips": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Computed:    true,
+							Description: "The IP addresses used to check front-end connectivity.",
+						},
+						"heartbeat_method": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "unicast",
+							ValidateFunc: validation.StringInSlice([]string{"multicast", "unicast"}, false),
+							Description:  "The method traffic managers should use to exchange cluster heartbeat messages.",
+						},
+						"igmp_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How often, in seconds, an IGMP report should be sent for multicast traffic IP groups.",
+						},
+						"l4accel_child_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, the traffic manager should wait for the L4Accel child process to respond.",
+						},
+						"l4accel_sync_port": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10240,
+							ValidateFunc: util.ValidateTCPPort,
+							Description:  "The port on which cluster members will transfer L4Accel state information.",
+						},
+						"monitor_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      500,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How often, in milliseconds, each traffic manager child process should report that it is working.",
+						},
+						"monitor_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, each traffic manager should wait for a response from its connectivity tests or from other traffic managers.",
+						},
+						"multicast_address": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "239.100.1.1:9090",
+							Description: "The multicast address and port to use to exchange cluster heartbeat messages.",
+						},
+						"unicast_port": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      9090,
+							ValidateFunc: util.ValidateTCPPort,
+							Description:  "The unicast UDP port to use to exchange cluster heartbeat messages.",
+						},
+						"use_bind_ip": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not cluster heartbeat messages should only be sent and received over the management network.",
+						},
+						"verbose": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not fault tolerance messages should be logged verbosely.",
+						},
+					},
+				},
+			},
+			"ip": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"appliance_returnpath": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Computed:    true,
+							Description: "The MAC to IP address mappings used by the appliance for return path routing",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"mac": {
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "The MAC address of the gateway",
+									},
+									"ipv4": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: util.ValidateIP,
+										Description:  "The IPv4 address of the gateway",
+									},
+									"ipv6": {
+										Type:        schema.TypeString,
+										Optional:    true,
+										Description: "The IPv6 address of the gateway",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"java": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"classpath": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "CLASSPATH to use when starting the Java runner.",
+						},
+						"command": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "java -server",
+							Description: "Java command to use when starting the Java runner, including any additional options.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not Java support should be enabled.",
+						},
+						"keepalive": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not the traffic manager should keep connections to the Java runner alive.",
+						},
+						"max_connections": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      256,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of Java threads that can be running at once.",
+						},
+						"session_age": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      86400,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "Default time, in seconds, to keep a Java session.",
+						},
+					},
+				},
+			},
+			"kerberos": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"verbose": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not Kerberos protocol transition messages should be logged verbosely.",
+						},
+					},
+				},
+			},
+			"l4accel": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_concurrent_connections": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      8192,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of concurrent connections, in thousands, that can be handled by each L4Accel child process.",
+						},
+					},
+				},
+			},
+			"ospfv2": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"area": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "0.0.0.1",
+							Description: "The OSPF area in which traffic managers will operate.",
+						},
+						"area_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "normal",
+							ValidateFunc: validation.StringInSlice([]string{"normal", "nssa", "stub"}, false),
+							Description:  "The type of OSPF area in which traffic managers will operate.",
+						},
+						"authentication_shared_secret": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The OSPFv2 authentication shared secret. An empty string means no authentication.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not OSPFv2 route health injection is enabled.",
+						},
+						"hello_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The interval, in seconds, between OSPF hello packets.",
+						},
+						"router_dead_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      40,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The time, in seconds, after which a neighbouring router is declared down if no hello packets are received.",
+						},
+					},
+				},
+			},
+			"remote_licensing": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"email_address": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The email address sent as a HTTP header when requesting a license from the license server.",
+						},
+						"message": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The message sent as a HTTP header when requesting a license from the license server.",
+						},
+					},
+				},
+			},
+			"rest_api": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"auth_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      120,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, the REST API authentication credentials should be cached.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the REST API is enabled.",
+						},
+						"http_max_header_length": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      4096,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum allowed length, in bytes, of a HTTP request's headers.",
+						},
+						"replicate_absolute": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      20,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum time, in seconds, to wait before replicating configuration changes.",
+						},
+						"replicate_lull": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, without configuration changes before they are replicated.",
+						},
+						"replicate_timeout": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, to wait for a replication to complete.",
+						},
+					},
+				},
+			},
+			"security": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"login_banner": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The banner displayed on the admin server and SSH login prompts.",
+						},
+						"login_banner_accept": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the login banner must be accepted before logging in.",
+						},
+						"login_delay": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The time, in seconds, to wait between unsuccessful login attempts.",
+						},
+						"max_login_attempts": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of unsuccessful login attempts before a user is suspended. A value of 0 (zero) disables suspension.",
+						},
+						"max_login_external": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the login attempts limit also applies to remotely authenticated users.",
+						},
+						"max_login_suspension_time": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      15,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The time, in minutes, a user is suspended for after too many unsuccessful login attempts.",
+						},
+						"track_unknown_users": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not failed login attempts for unknown users should be tracked.",
+						},
+						"ui_page_banner": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The banner displayed on every page of the admin server.",
+						},
+					},
+				},
+			},
+			"session": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"asp_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the ASP session cache.",
+						},
+						"ip_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the IP session cache.",
+						},
+						"j2ee_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the J2EE session cache.",
+						},
+						"ssl_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the SSL session persistence cache.",
+						},
+						"universal_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the universal session cache.",
+						},
+					},
+				},
+			},
+			"snmp": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"user_counters": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of user defined SNMP counters.",
+						},
+					},
+				},
+			},
+			"soap": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"idle_minutes": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of minutes the SOAP server should remain idle before exiting.",
+						},
+					},
+				},
+			},
+			"ssl": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"allow_rehandshake": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "safe",
+							ValidateFunc: validation.StringInSlice([]string{"always", "never", "rfc5746", "safe"}, false),
+							Description:  "Whether or not SSL/TLS re-handshakes should be supported.",
+						},
+						"cache_expiry": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1800,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, the SSL session IDs cache entries should be kept for.",
+						},
+						"cache_per_virtualserver": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not each virtual server uses its own SSL session cache.",
+						},
+						"cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      6151,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the SSL session cache.",
+						},
+						"cipher_suites": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The SSL/TLS cipher suites preference list for SSL/TLS connections.",
+						},
+						"client_cache_expiry": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      14400,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How long, in seconds, the SSL client session cache entries should be kept for.",
+						},
+						"client_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1024,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of entries in the SSL client session cache.",
+						},
+						"client_cache_tickets": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not session tickets may be requested and stored in the SSL client cache.",
+						},
+						"crl_mem_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "5MB",
+							Description: "The amount of memory used to store CRLs.",
+						},
+						"diffie_hellman_client_min_modulus_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1024,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The minimum size, in bits, of the Diffie-Hellman modulus accepted from a server.",
+						},
+						"diffie_hellman_modulus_size": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "dh_2048",
+							ValidateFunc: validation.StringInSlice([]string{"dh_1024", "dh_2048", "dh_3072", "dh_4096"}, false),
+							Description:  "The size of the Diffie-Hellman modulus used for key agreement.",
+						},
+						"elliptic_curves": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The SSL/TLS elliptic curve preference list.",
+						},
+						"honor_fallback_scsv": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not the TLS_FALLBACK_SCSV cipher suite value should be honoured.",
+						},
+						"insert_extra_fragment": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSL3 and TLS1 connections should send an empty fragment before the first data fragment.",
+						},
+						"max_handshake_message_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10240,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum size, in bytes, of a handshake message that will be accepted.",
+						},
+						"min_rehandshake_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The minimum time, in seconds, between client initiated re-handshakes.",
+						},
+						"obscure_alert_descriptions": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSL/TLS alert descriptions should be obscured.",
+						},
+						"ocsp_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2048,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of cached client certificate OCSP results.",
+						},
+						"ocsp_stapling_default_refresh_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      60,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How often, in minutes, OCSP responses should be refreshed when no expiry is given.",
+						},
+						"ocsp_stapling_maximum_refresh_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      864000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum time, in seconds, between OCSP response refreshes.",
+						},
+						"ocsp_stapling_mem_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "1MB",
+							Description: "The amount of memory used to store OCSP responses for stapling.",
+						},
+						"ocsp_stapling_time_tolerance": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "How many seconds of clock skew are tolerated when checking OCSP responses.",
+						},
+						"ocsp_stapling_verify_response": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not OCSP responses should be verified before being stapled.",
+						},
+						"prevent_timing_side_channels": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not extra care should be taken to prevent timing side channel attacks.",
+						},
+						"signature_algorithms": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The SSL/TLS signature algorithms preference list.",
+						},
+						"support_ssl2": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSLv2 is enabled.",
+						},
+						"support_ssl3": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not SSLv3 is enabled.",
+						},
+						"support_tls1": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not TLSv1.0 is enabled.",
+						},
+						"support_tls1_1": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not TLSv1.1 is enabled.",
+						},
+						"support_tls1_2": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not TLSv1.2 is enabled.",
+						},
+					},
+				},
+			},
+			"ssl_hardware": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"accel": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the SSL hardware is an accelerator.",
+						},
+						"driver_pkcs11_debug": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not to print PKCS#11 debug logging.",
+						},
+						"driver_pkcs11_lib": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The location of the PKCS#11 library.",
+						},
+						"driver_pkcs11_slot_desc_or_label": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The label of the SSL hardware slot or token to use.",
+						},
+						"driver_pkcs11_slot_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "operator",
+							ValidateFunc: validation.StringInSlice([]string{"module", "operator", "softcard"}, false),
+							Description:  "The type of SSL hardware slot to use.",
+						},
+						"driver_pkcs11_user_pin": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The user PIN for the PKCS#11 token.",
+						},
+						"failure_count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The number of consecutive failures from the SSL hardware that will be tolerated before it is marked as failed.",
+						},
+						"library": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "none",
+							ValidateFunc: validation.StringInSlice([]string{"none", "pkcs11"}, false),
+							Description:  "The type of SSL hardware to use.",
+						},
+						"nworkers": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of concurrent requests sent to the SSL hardware. A value of 0 (zero) means use the number of CPUs.",
+						},
+					},
+				},
+			},
+			"trafficscript": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"data_local_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "5%",
+							Description: "The amount of shared memory reserved for a TrafficScript data.local.set() call.",
+						},
+						"data_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "5%",
+							Description: "The amount of shared memory reserved for a TrafficScript data.set() call.",
+						},
+						"execution_time_warning": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      500,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The time, in milliseconds, a rule may take to execute before a warning is logged.",
+						},
+						"max_instr": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      100000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of instructions a rule will run before it is aborted.",
+						},
+						"memory_warning": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1048576,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The amount of memory, in bytes, a rule may use before a warning is logged.",
+						},
+						"regex_cache_size": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      57,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of regular expressions to cache in TrafficScript.",
+						},
+						"regex_match_limit": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of ways TrafficScript will attempt to match a regular expression.",
+						},
+						"regex_match_warn_percentage": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: validation.IntBetween(0, 100),
+							Description:  "The percentage of regex_match_limit at which a warning will be logged.",
+						},
+						"variable_pool_use": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not TrafficScript variables can be stored in the variable pool.",
+						},
+					},
+				},
+			},
+			"transaction_export": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"auto_recover": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not the transaction export connection should be automatically re-established.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not transaction metadata should be exported.",
+						},
+						"endpoint": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The hostname or IP address and port of the remote collector.",
+						},
+						"tls": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not the connection to the remote collector should be encrypted using TLS.",
+						},
+						"tls_verify": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not the certificate of the remote collector should be verified.",
+						},
+					},
+				},
+			},
+			"web_cache": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"avg_path_length": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      512,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The estimated average length of the path, including query string, for resources being cached.",
+						},
+						"disk": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not the web cache should be backed by a disk file.",
+						},
+						"disk_dir": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "%ZEUSHOME%/zxtm/internal",
+							Description: "The directory where the web cache disk file is stored.",
+						},
+						"max_file_num": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum number of files that can be stored in the web cache.",
+						},
+						"max_file_size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "2%",
+							Description: "The largest size of a single object in the web cache.",
+						},
+						"max_path_length": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2048,
+							ValidateFunc: util.ValidateUnsignedInteger,
+							Description:  "The maximum length of the path, including query string, for a resource to be cached.",
+						},
+						"normalize_query": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether or not query string parameters should be sorted before the web cache lookup.",
+						},
+						"size": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "20%",
+							Description: "The maximum size of the web cache.",
+						},
+						"verbose": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether or not web cache messages should be logged verbosely.",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -149,6 +1564,31 @@ func validateSocketOptimizations(v interface{}, k string) (ws []string, errors [
 	return
 }
 
+func globalSettingsSectionNames() []string {
+	return []string{
+		"basic", "admin", "appliance", "aptimizer", "auditlog", "auto_scaler", "cluster_comms",
+		"global_connection", "dns", "ec2", "eventing", "fault_tolerance", "ip", "java", "kerberos",
+		"l4accel", "ospfv2", "remote_licensing", "rest_api", "security", "session", "snmp", "soap",
+		"ssl", "ssl_hardware", "trafficscript", "transaction_export", "web_cache",
+	}
+}
+
+func globalSettingsSectionName(name string) string {
+	if name == "global_connection" {
+		return "connection"
+	}
+	if name == "connection" {
+		return "global_connection"
+	}
+	return name
+}
+
+func globalSettingsTables() map[string]string {
+	return map[string]string{
+		"appliance_returnpath": "mac",
+	}
+}
+
 func resourceGlobalSettingsRead(d *schema.ResourceData, m interface{}) error {
 
 	config := m.(map[string]interface{})
@@ -162,12 +1602,18 @@ func resourceGlobalSettingsRead(d *schema.ResourceData, m interface{}) error {
 		return fmt.Errorf("[ERROR] PulseVTM error whilst reading %s: %v", "", err)
 	}
 	properties := globalSettings["properties"].(map[string]interface{})
+	fromAPIVersionProperties(m, "pulsevtm_global_settings", properties)
 
-	basicSection := make([]map[string]interface{}, 0)
-	basicSection = append(basicSection, properties["basic"].(map[string]interface{}))
-	err = d.Set("basic", basicSection)
-	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst setting attribute basic: %v", err)
+	for _, section := range supportedAttributes(m, "pulsevtm_global_settings", globalSettingsSectionNames()) {
+		if _, ok := properties[globalSettingsSectionName(section)].(map[string]interface{}); !ok {
+			continue
+		}
+		sectionList := make([]map[string]interface{}, 0)
+		sectionList = append(sectionList, util.ReorderTablesInSection(properties, globalSettingsTables(), globalSettingsSectionName(section), d))
+		err = d.Set(section, sectionList)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst setting attribute %s: %v", section, err)
+		}
 	}
 	return nil
 }
@@ -179,16 +1625,27 @@ func resourceGlobalSettingsUpdate(d *schema.ResourceData, m interface{}) error {
 	globalSettings := make(map[string]interface{})
 	properties := make(map[string]interface{})
 
-	if d.HasChange("basic") {
-		basic := d.Get("basic").([]interface{})
-		properties["basic"] = basic[0]
+	for _, section := range supportedAttributes(m, "pulsevtm_global_settings", globalSettingsSectionNames()) {
+		if !d.HasChange(section) {
+			continue
+		}
+		// A block removed from the configuration, or configured empty, leaves the section as it is
+		sectionList := d.Get(section).([]interface{})
+		if len(sectionList) == 0 || sectionList[0] == nil {
+			continue
+		}
+		properties[globalSettingsSectionName(section)] = sectionList[0]
+	}
 
+	if len(properties) > 0 {
 		config := m.(map[string]interface{})
 		client := config["jsonClient"].(*api.Client)
+		toAPIVersionProperties(m, "pulsevtm_global_settings", properties)
 		globalSettings["properties"] = properties
+		util.TraverseMapTypes(globalSettings)
 		err := client.Set("global_settings", "", globalSettings, nil)
 		if err != nil {
-			return fmt.Errorf("[ERROR] PulseVTM Global settings error whilst creating/updating %s: %v", "", err)
+			return wrapErrorf(err, "[ERROR] PulseVTM Global settings error whilst creating/updating %s: %v", "", err)
 		}
 	}
 	d.SetId("global_settings")
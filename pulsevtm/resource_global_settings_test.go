@@ -4,11 +4,44 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"os"
 	"testing"
 )
 
+func TestGlobalSettingsWithEmptyBlocks(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	server.setResource("global_settings/", map[string]interface{}{"properties": map[string]interface{}{
+		"ssl": map[string]interface{}{"cache_size": 1000},
+	}})
+	globalSettings := resourceGlobalSettings()
+
+	// A section configured as an empty list is left as it is, while an empty block takes the defaults
+	state := &terraform.InstanceState{ID: "global_settings", Attributes: map[string]string{"ssl.#": "1", "ssl.0.cache_size": "1000"}}
+	for _, test := range []struct {
+		raw       map[string]interface{}
+		cacheSize float64
+	}{
+		{map[string]interface{}{"ssl": []interface{}{}}, 1000},
+		{map[string]interface{}{"ssl": []interface{}{map[string]interface{}{}}}, 6151},
+	} {
+		diff, err := globalSettings.Diff(state, testResourceConfig(t, test.raw))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = globalSettings.Apply(state, diff, m)
+		if err != nil {
+			t.Errorf("unexpected error applying %v: %v", test.raw, err)
+		}
+		ssl := server.getResource("global_settings/")["properties"].(map[string]interface{})["ssl"].(map[string]interface{})
+		if ssl["cache_size"] != test.cacheSize {
+			t.Errorf("expected cache_size %v applying %v, got %v", test.cacheSize, test.raw, ssl["cache_size"])
+		}
+	}
+}
+
 func TestAccPulseVTMResourceGlobalSettings(t *testing.T) {
 
 	resource.Test(t, resource.TestCase{
@@ -37,6 +70,11 @@ func TestAccPulseVTMResourceGlobalSettings(t *testing.T) {
 					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "basic.0.tip_class_limit", "10000"),
 					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "basic.0.data_plane_acceleration_cores", "two"),
 					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "basic.0.data_plane_acceleration_mode", "true"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "dns.0.negative_expiry", "60"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "global_connection.0.idle_timeout", "10"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "java.0.max_connections", "256"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "security.0.login_banner", "Authorised access only"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "ssl.0.support_tls1", "true"),
 				),
 			},
 			{
@@ -55,6 +93,11 @@ func TestAccPulseVTMResourceGlobalSettings(t *testing.T) {
 					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "basic.0.tip_class_limit", "10000"),
 					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "basic.0.data_plane_acceleration_cores", "four"),
 					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "basic.0.data_plane_acceleration_mode", "false"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "dns.0.negative_expiry", "120"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "global_connection.0.idle_timeout", "20"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "java.0.max_connections", "512"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "security.0.login_banner", "Authorised access only - activity is logged"),
+					resource.TestCheckResourceAttr("pulsevtm_global_settings.global_settings", "ssl.0.support_tls1", "false"),
 				),
 			},
 		},
@@ -127,6 +170,21 @@ func testAccPulseGlobalSettingsCreate() string {
     data_plane_acceleration_cores = "two"
     data_plane_acceleration_mode = true
    }
+   dns = {
+    negative_expiry = 60
+   }
+   global_connection = {
+    idle_timeout = 10
+   }
+   java = {
+    max_connections = 256
+   }
+   security = {
+    login_banner = "Authorised access only"
+   }
+   ssl = {
+    support_tls1 = true
+   }
 }`
 }
 
@@ -144,5 +202,20 @@ func testAccPulseGlobalSettingsUpdate() string {
     data_plane_acceleration_cores = "four"
     data_plane_acceleration_mode = false
    }
+   dns = {
+    negative_expiry = 120
+   }
+   global_connection = {
+    idle_timeout = 20
+   }
+   java = {
+    max_connections = 512
+   }
+   security = {
+    login_banner = "Authorised access only - activity is logged"
+   }
+   ssl = {
+    support_tls1 = false
+   }
 }`
 }
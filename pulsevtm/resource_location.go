@@ -3,7 +3,7 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"net/http"
 )
@@ -126,7 +126,7 @@ func resourceLocationCreate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("locations", name, locationConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Location error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Location error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
@@ -201,7 +201,7 @@ func resourceLocationUpdate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("locations", name, locationConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM locations error whilst updating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM locations error whilst updating %s: %v", name, err)
 	}
 
 	return resourceLocationRead(d, m)
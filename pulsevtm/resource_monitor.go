@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"net/http"
 )
@@ -322,7 +322,7 @@ func resourceMonitorSet(d *schema.ResourceData, m interface{}) error {
 	util.TraverseMapTypes(monitorRequest)
 	err := client.Set("monitors", name, monitorRequest, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Monitor error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Monitor error whilst creating %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceMonitorRead(d, m)
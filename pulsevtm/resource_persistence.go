@@ -3,7 +3,7 @@ package pulsevtm
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"net/http"
 	"regexp"
 )
@@ -133,7 +133,7 @@ func resourcePersistenceCreate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("persistence", name, &persistenceConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Persistence error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Persistence error whilst creating %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourcePersistenceRead(d, m)
@@ -221,7 +221,7 @@ func resourcePersistenceUpdate(d *schema.ResourceData, m interface{}) error {
 	client := config["jsonClient"].(*api.Client)
 	err := client.Set("persistence", name, &persistenceConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Persistence error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Persistence error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
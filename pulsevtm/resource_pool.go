@@ -4,15 +4,16 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"log"
 	"net/http"
 	"regexp"
+	"time"
 )
 
 func resourcePool() *schema.Resource {
-	return &schema.Resource{
+	poolResource := &schema.Resource{
 		Create: resourcePoolSet,
 		Read:   resourcePoolRead,
 		Update: resourcePoolSet,
@@ -160,6 +161,7 @@ func resourcePool() *schema.Resource {
 				Default:     false,
 				Description: "Whether or not connections to the back ends appears to originate from the source client IP",
 			},
+			"wait_for_healthy": waitForHealthySchema("When set, the apply waits for the pool to have a minimum number of healthy nodes and fails if that doesn't happen within the timeout"),
 
 			"auto_scaling": {
 				Type:     schema.TypeList,
@@ -700,6 +702,10 @@ func resourcePool() *schema.Resource {
 		},
 	}
 
+	for key, value := range poolNodeDrainingSchema() {
+		poolResource.Schema[key] = value
+	}
+	return poolResource
 }
 
 // validateAcceptFromMask : check the assigned accept from mask is valid
@@ -754,6 +760,14 @@ func basicPoolKeys() []string {
 	}
 }
 
+// poolSectionNames : returns the names of the sections of a pool, as they're named in the schema
+func poolSectionNames() []string {
+	return []string{
+		"auto_scaling", "dns_autoscale", "ftp", "http", "kerberos_protocol_transition", "load_balancing", "node",
+		"pool_connection", "smtp", "ssl", "tcp", "udp", "l4accel",
+	}
+}
+
 func poolSectionName(name string) string {
 	if name == "pool_connection" {
 		return "connection"
@@ -777,21 +791,7 @@ func resourcePoolSet(d *schema.ResourceData, m interface{}) error {
 
 	util.GetSection(d, "basic", poolProperties, basicPoolKeys())
 
-	for _, section := range []string{
-		"auto_scaling",
-		"dns_autoscale",
-		"ftp",
-		"http",
-		"kerberos_protocol_transition",
-		"load_balancing",
-		"node",
-		"pool_connection",
-		"smtp",
-		"ssl",
-		"tcp",
-		"udp",
-		"l4accel",
-	} {
+	for _, section := range supportedAttributes(m, "pulsevtm_pool", poolSectionNames()) {
 		if d.HasChange(section) {
 			poolProperties[poolSectionName(section)] = d.Get(section).([]interface{})[0]
 		}
@@ -817,14 +817,30 @@ func resourcePoolSet(d *schema.ResourceData, m interface{}) error {
 		return fmt.Errorf("[ERROR] creating/updating resource: one of nodes_list or nodes_table must be defined")
 	}
 
+	if !d.IsNewResource() && d.Get("drain_on_removal").(bool) {
+		err := drainPoolNodes(client, config["statusClient"].(*api.Client), name, removedPoolNodes(d), time.Duration(d.Get("drain_timeout").(int))*time.Second)
+		if err != nil {
+			return err
+		}
+	}
+
+	toAPIVersionProperties(m, "pulsevtm_pool", poolProperties)
 	poolRequest["properties"] = poolProperties
 	util.TraverseMapTypes(poolRequest)
+	// The nodes table is shared with the pool node resources, which write it under the same lock
+	poolNodeMutexKV.Lock(name)
 	err := client.Set("pools", name, poolRequest, nil)
+	poolNodeMutexKV.Unlock(name)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Pool error whilst creating/updating %s: %s", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Pool error whilst creating/updating %s: %v", name, err)
 	}
 	d.SetId(name)
 
+	err = waitForHealthyPool(client, config["statusClient"].(*api.Client), name, d)
+	if err != nil {
+		return err
+	}
+
 	return resourcePoolRead(d, m)
 }
 
@@ -846,6 +862,7 @@ func resourcePoolRead(d *schema.ResourceData, m interface{}) error {
 	}
 
 	poolsProperties := poolResponse["properties"].(map[string]interface{})
+	fromAPIVersionProperties(m, "pulsevtm_pool", poolsProperties)
 	poolsBasic := poolsProperties["basic"].(map[string]interface{})
 
 	for _, key := range basicPoolKeys() {
@@ -871,29 +888,15 @@ func resourcePoolRead(d *schema.ResourceData, m interface{}) error {
 		return fmt.Errorf("[ERROR] PulseVTM Pools error whilst setting attribute nodes_table in state")
 	}
 
-	for _, section := range []string{
-		"auto_scaling",
-		"dns_autoscale",
-		"ftp",
-		"http",
-		"kerberos_protocol_transition",
-		"load_balancing",
-		"node",
-		"connection",
-		"smtp",
-		"ssl",
-		"tcp",
-		"udp",
-		"l4accel",
-	} {
+	for _, section := range supportedAttributes(m, "pulsevtm_pool", poolSectionNames()) {
 		set := make([]map[string]interface{}, 0)
 		//set = append(set, poolsProperties[section].(map[string]interface{}))
-		readSectionMap, err := util.BuildReadMap(poolsProperties[section].(map[string]interface{}))
+		readSectionMap, err := util.BuildReadMap(poolsProperties[poolSectionName(section)].(map[string]interface{}))
 		if err != nil {
 			return err
 		}
 		set = append(set, readSectionMap)
-		err = d.Set(poolSectionName(section), set)
+		err = d.Set(section, set)
 		if err != nil {
 			return fmt.Errorf("[ERROR] PulseVTM Pools error whilst setting attribute %s in state", section)
 		}
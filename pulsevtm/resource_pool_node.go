@@ -0,0 +1,267 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/mutexkv"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// poolNodeMutexKV serialises nodes table updates made to the same pool by this provider
+var poolNodeMutexKV = mutexkv.NewMutexKV()
+
+func resourcePoolNode() *schema.Resource {
+	poolNodeResource := &schema.Resource{
+		Create: resourcePoolNodeSet,
+		Read:   resourcePoolNodeRead,
+		Update: resourcePoolNodeSet,
+		Delete: resourcePoolNodeDelete,
+
+		Schema: map[string]*schema.Schema{
+			"pool": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the existing pool the node is a member of. The pool resource should ignore changes to its nodes_list and nodes_table. Changes made to the nodes table outside of Terraform whilst the node is being set are lost",
+			},
+			"node": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateNode,
+				Description:  "A node. Combination of IP and port",
+			},
+			"priority": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: util.ValidateUnsignedInteger,
+				Description:  "Priority assigned to a node. Defaults to 1",
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "active",
+				ValidateFunc: validation.StringInSlice([]string{
+					"active",
+					"draining",
+					"disabled",
+				}, false),
+				Description: "State of the node in the pool",
+			},
+			"weight": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntBetween(1, 100),
+				Description:  "Weight assigned to the node. Valid values are between 1 and 100",
+			},
+		},
+	}
+
+	for key, value := range poolNodeDrainingSchema() {
+		poolNodeResource.Schema[key] = value
+	}
+	return poolNodeResource
+}
+
+// getPoolNodesTable : retrieves the nodes table of a pool
+func getPoolNodesTable(client *api.Client, pool string) ([]interface{}, error) {
+	poolResponse := make(map[string]interface{})
+	client.WorkWithConfigurationResources()
+	err := client.GetByName("pools", pool, &poolResponse)
+	if err != nil {
+		return nil, err
+	}
+	poolsProperties := poolResponse["properties"].(map[string]interface{})
+	poolsBasic := poolsProperties["basic"].(map[string]interface{})
+	nodesTable, ok := poolsBasic["nodes_table"].([]interface{})
+	if !ok {
+		return make([]interface{}, 0), nil
+	}
+	return nodesTable, nil
+}
+
+// setPoolNodesTable : replaces the nodes table of a pool leaving the rest of its configuration untouched
+func setPoolNodesTable(client *api.Client, pool string, nodesTable []interface{}) error {
+	poolRequest := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{
+				"nodes_table": nodesTable,
+			},
+		},
+	}
+	return client.Set("pools", pool, poolRequest, nil)
+}
+
+// findPoolNode : returns the entry for a node within a nodes table
+func findPoolNode(nodesTable []interface{}, node string) (map[string]interface{}, bool) {
+	for _, item := range nodesTable {
+		entry := item.(map[string]interface{})
+		if entry["node"] == node {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// modifyPoolNodesTable : applies a change to the nodes table of a pool. The REST API has no conditional writes, so
+// the table is read and written whilst holding the pool's lock, which every writer of nodes tables in this provider
+// takes. This only orders the writes made by this provider: changes made to the nodes table outside of it, or by
+// another Terraform run, between the read and the write are lost.
+func modifyPoolNodesTable(client *api.Client, pool string, modify func([]interface{}) []interface{}, applied func([]interface{}) bool) error {
+	poolNodeMutexKV.Lock(pool)
+	defer poolNodeMutexKV.Unlock(pool)
+
+	nodesTable, err := getPoolNodesTable(client, pool)
+	if err != nil {
+		return err
+	}
+	if applied(nodesTable) {
+		return nil
+	}
+	return setPoolNodesTable(client, pool, modify(nodesTable))
+}
+
+func resourcePoolNodeSet(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	pool := d.Get("pool").(string)
+	node := d.Get("node").(string)
+	priority := d.Get("priority").(int)
+	state := d.Get("state").(string)
+	weight := d.Get("weight").(int)
+
+	modify := func(nodesTable []interface{}) []interface{} {
+		updatedNodesTable := make([]interface{}, 0)
+		nodeFound := false
+		for _, item := range nodesTable {
+			entry := item.(map[string]interface{})
+			if entry["node"] != node {
+				updatedNodesTable = append(updatedNodesTable, entry)
+				continue
+			}
+			updatedEntry := make(map[string]interface{})
+			for key, value := range entry {
+				updatedEntry[key] = value
+			}
+			updatedEntry["priority"] = priority
+			updatedEntry["state"] = state
+			updatedEntry["weight"] = weight
+			updatedNodesTable = append(updatedNodesTable, updatedEntry)
+			nodeFound = true
+		}
+		if !nodeFound {
+			updatedNodesTable = append(updatedNodesTable, map[string]interface{}{
+				"node":     node,
+				"priority": priority,
+				"state":    state,
+				"weight":   weight,
+			})
+		}
+		return updatedNodesTable
+	}
+	applied := func(nodesTable []interface{}) bool {
+		entry, ok := findPoolNode(nodesTable, node)
+		if !ok {
+			return false
+		}
+		return fmt.Sprint(entry["priority"]) == fmt.Sprint(priority) &&
+			fmt.Sprint(entry["weight"]) == fmt.Sprint(weight) &&
+			entry["state"] == state
+	}
+
+	err := modifyPoolNodesTable(client, pool, modify, applied)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM Pool error whilst setting node %s in pool %s: %v", node, pool, err)
+	}
+	d.SetId(pool + "/" + node)
+
+	return resourcePoolNodeRead(d, m)
+}
+
+func resourcePoolNodeRead(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	idParts := strings.SplitN(d.Id(), "/", 2)
+	if len(idParts) != 2 {
+		return fmt.Errorf("[ERROR] PulseVTM pool node ID %s is not in the format <pool>/<node>", d.Id())
+	}
+	pool, node := idParts[0], idParts[1]
+
+	nodesTable, err := getPoolNodesTable(client, pool)
+	if err != nil {
+		if client.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("[ERROR] PulseVTM Pools error whilst retrieving %s: %v", pool, err)
+	}
+
+	entry, ok := findPoolNode(nodesTable, node)
+	if !ok {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("pool", pool)
+	d.Set("node", node)
+	for _, key := range []string{"priority", "state", "weight"} {
+		err := d.Set(key, entry[key])
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM Pools error whilst setting attribute %s in state", key)
+		}
+	}
+	return nil
+}
+
+func resourcePoolNodeDelete(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	pool := d.Get("pool").(string)
+	node := d.Get("node").(string)
+
+	if d.Get("drain_on_removal").(bool) {
+		err := drainPoolNodes(client, config["statusClient"].(*api.Client), pool, []string{node}, time.Duration(d.Get("drain_timeout").(int))*time.Second)
+		if err != nil {
+			if client.StatusCode == http.StatusNotFound {
+				d.SetId("")
+				return nil
+			}
+			return err
+		}
+	}
+
+	modify := func(nodesTable []interface{}) []interface{} {
+		remainingNodesTable := make([]interface{}, 0)
+		for _, item := range nodesTable {
+			if item.(map[string]interface{})["node"] != node {
+				remainingNodesTable = append(remainingNodesTable, item)
+			}
+		}
+		return remainingNodesTable
+	}
+	applied := func(nodesTable []interface{}) bool {
+		_, ok := findPoolNode(nodesTable, node)
+		return !ok
+	}
+
+	err := modifyPoolNodesTable(client, pool, modify, applied)
+	if err != nil {
+		if client.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("[ERROR] PulseVTM Pool error whilst removing node %s from pool %s: %v", node, pool, err)
+	}
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,268 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func TestAccPulseVTMPoolNodeBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+
+	poolName := fmt.Sprintf("acctest_pulsevtm_pool_node-%d", randomInt)
+	resourceName := "pulsevtm_pool_node.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMPoolNodeCheckDestroy(state, "192.168.100.20:80")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPulseVTMPoolNodeNoPool(),
+				ExpectError: regexp.MustCompile(`required field is not set`),
+			},
+			{
+				Config: testAccPulseVTMPoolNodeCreate(poolName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMPoolNodeExists(poolName, "192.168.100.20:80"),
+					testAccPulseVTMPoolNodeExists(poolName, "192.168.100.10:80"),
+					resource.TestCheckResourceAttr(resourceName, "pool", poolName),
+					resource.TestCheckResourceAttr(resourceName, "node", "192.168.100.20:80"),
+					resource.TestCheckResourceAttr(resourceName, "priority", "1"),
+					resource.TestCheckResourceAttr(resourceName, "state", "active"),
+					resource.TestCheckResourceAttr(resourceName, "weight", "1"),
+				),
+			},
+			{
+				Config: testAccPulseVTMPoolNodeUpdate(poolName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMPoolNodeExists(poolName, "192.168.100.20:80"),
+					testAccPulseVTMPoolNodeExists(poolName, "192.168.100.10:80"),
+					resource.TestCheckResourceAttr(resourceName, "priority", "2"),
+					resource.TestCheckResourceAttr(resourceName, "state", "disabled"),
+					resource.TestCheckResourceAttr(resourceName, "weight", "50"),
+				),
+			},
+		},
+	})
+}
+
+func TestPoolNodeSetConcurrently(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+
+	server.setResource("pools/web", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{
+				"nodes_table": []interface{}{
+					map[string]interface{}{"node": "10.0.0.1:80", "priority": 1, "state": "active", "weight": 1},
+				},
+			},
+		},
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 2; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, resourcePoolNode().Schema, map[string]interface{}{
+				"pool":   "web",
+				"node":   fmt.Sprintf("10.0.0.%d:80", i),
+				"weight": 20,
+			})
+			errs <- resourcePoolNodeSet(d, m)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	nodesTable := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+	for i := 1; i < 12; i++ {
+		node := fmt.Sprintf("10.0.0.%d:80", i)
+		if _, ok := findPoolNode(nodesTable, node); !ok {
+			t.Errorf("expected node %s in nodes table, got %+v", node, nodesTable)
+		}
+	}
+}
+
+func TestPoolSetWaitsForPoolNodeChanges(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+
+	properties := map[string]interface{}{"basic": map[string]interface{}{"nodes_table": []interface{}{}}}
+	for _, section := range []string{"auto_scaling", "dns_autoscale", "ftp", "http", "kerberos_protocol_transition", "load_balancing",
+		"node", "connection", "smtp", "ssl", "tcp", "udp", "l4accel"} {
+		properties[section] = map[string]interface{}{}
+	}
+	server.setResource("pools/web", map[string]interface{}{"properties": properties})
+	nodesTable := func() []interface{} {
+		return server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+	}
+	d := schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{
+		"name":       "web",
+		"nodes_list": []interface{}{"10.0.0.1:80"},
+	})
+
+	// The pool's nodes table isn't written whilst a pool node is being changed
+	poolNodeMutexKV.Lock("web")
+	done := make(chan error, 1)
+	go func() {
+		done <- resourcePoolSet(d, m)
+	}()
+	select {
+	case err := <-done:
+		poolNodeMutexKV.Unlock("web")
+		t.Fatalf("expected the pool to wait for the pool node lock, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if len(nodesTable()) != 0 {
+		t.Errorf("expected the nodes table not to be written whilst locked, got %v", nodesTable())
+	}
+	poolNodeMutexKV.Unlock("web")
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := findPoolNode(nodesTable(), "10.0.0.1:80"); !ok {
+		t.Errorf("expected the nodes table to be written once unlocked, got %v", nodesTable())
+	}
+}
+
+func TestPoolNodeDeleteKeepsOtherNodes(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+
+	server.setResource("pools/web", map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{
+				"nodes_table": []interface{}{
+					map[string]interface{}{"node": "10.0.0.1:80", "priority": 1, "state": "active", "weight": 1},
+					map[string]interface{}{"node": "10.0.0.2:80", "priority": 1, "state": "active", "weight": 1},
+				},
+			},
+		},
+	})
+
+	d := schema.TestResourceDataRaw(t, resourcePoolNode().Schema, map[string]interface{}{
+		"pool": "web",
+		"node": "10.0.0.2:80",
+	})
+	d.SetId("web/10.0.0.2:80")
+	err := resourcePoolNodeDelete(d, server.meta(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nodesTable := server.getResource("pools/web")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["nodes_table"].([]interface{})
+	if len(nodesTable) != 1 {
+		t.Fatalf("expected a single node to remain, got %+v", nodesTable)
+	}
+	if _, ok := findPoolNode(nodesTable, "10.0.0.1:80"); !ok {
+		t.Errorf("expected node 10.0.0.1:80 to remain, got %+v", nodesTable)
+	}
+}
+
+func testAccPulseVTMPoolNodeCheckDestroy(state *terraform.State, node string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_pool_node" {
+			continue
+		}
+		nodesTable, err := getPoolNodesTable(client, rs.Primary.Attributes["pool"])
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: %+v", err)
+		}
+		if _, ok := findPoolNode(nodesTable, node); ok {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: pool node %s still exists", node)
+		}
+	}
+	return nil
+}
+
+func testAccPulseVTMPoolNodeExists(poolName, node string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+
+		nodesTable, err := getPoolNodesTable(client, poolName)
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving pool %s: %+v", poolName, err)
+		}
+		if _, ok := findPoolNode(nodesTable, node); !ok {
+			return fmt.Errorf("[ERROR] Pulse vTM node %s not found in pool %s", node, poolName)
+		}
+		return nil
+	}
+}
+
+func testAccPulseVTMPoolNodeNoPool() string {
+	return `
+resource "pulsevtm_pool_node" "acctest" {
+	node = "192.168.100.20:80"
+}
+`
+}
+
+func testAccPulseVTMPoolNodeCreate(poolName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_pool" "acctest" {
+	name = "%s"
+	nodes_list = ["192.168.100.10:80"]
+	lifecycle {
+		ignore_changes = ["nodes_list", "nodes_table"]
+	}
+}
+
+resource "pulsevtm_pool_node" "acctest" {
+	pool = "${pulsevtm_pool.acctest.name}"
+	node = "192.168.100.20:80"
+}
+`, poolName)
+}
+
+func testAccPulseVTMPoolNodeUpdate(poolName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_pool" "acctest" {
+	name = "%s"
+	nodes_list = ["192.168.100.10:80"]
+	lifecycle {
+		ignore_changes = ["nodes_list", "nodes_table"]
+	}
+}
+
+resource "pulsevtm_pool_node" "acctest" {
+	pool = "${pulsevtm_pool.acctest.name}"
+	node = "192.168.100.20:80"
+	priority = 2
+	state = "disabled"
+	weight = 50
+}
+`, poolName)
+}
@@ -5,7 +5,8 @@ import (
 	"net/http"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
 func resourceRule() *schema.Resource {
@@ -22,9 +23,10 @@ func resourceRule() *schema.Resource {
 				Required:    true,
 			},
 			"rule": {
-				Type:        schema.TypeString,
-				Description: "The rule in traffic script language as a here document",
-				Required:    true,
+				Type:         schema.TypeString,
+				Description:  "The rule in traffic script language as a here document",
+				Required:     true,
+				ValidateFunc: util.ValidateTrafficScript,
 			},
 		},
 	}
@@ -40,7 +42,7 @@ func resourceRuleSet(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("rules", name, []byte(rule), nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Rule error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Rule error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
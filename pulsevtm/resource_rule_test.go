@@ -5,8 +5,10 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -56,6 +58,88 @@ func TestAccPulseVTMRuleBasic(t *testing.T) {
 	})
 }
 
+func TestTrafficScriptParse(t *testing.T) {
+	valid := []string{
+		"if( string.ipmaskmatch( request.getremoteip(), \"192.168.11.13\" ) ){\n    connection.discard();\n}\n",
+		`import library as lib;
+# Send API requests to their own pool
+sub backend( $path ) {
+   if( string.startsWith( $path, '/api/' ) ) { return "api"; } else if ( $path == "/" ) { return "home"; }
+   return "web";
+}
+$hosts = [ "a.example.com" => 1, "b.example.com" => 2 ];
+$count = 0x1F + 1.5e2 * -$hosts["a.example.com"];
+for( $i = 0; $i < 3; $i++ ) { if( $i == 1 ) { continue; } $count += $i; }
+foreach( $name in hash.keys( $hosts ) ) { while( 1 ) { break; } }
+$message = "path " . http.getPath() . ( $count > 2 ? " is busy" : " is quiet" );
+lib.audit( $message );
+pool.use( backend( http.getPath() ) );
+`,
+	}
+	for _, rule := range valid {
+		if _, err := util.ParseTrafficScript(rule); err != nil {
+			t.Errorf("unexpected error parsing %q: %v", rule, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"connection.discard()\n":                    `line 2, column 1: expected ";" at the end of the statement, but found the end of the rule`,
+		"if( 1 ) {\n  log.info( \"unclosed );\n}\n": "line 2, column 13: string is never closed",
+		"if 1 { }":                    `line 1, column 4: expected "(" after if, but found the number 1`,
+		"while( 1 ) {\n  $a = 1;\n":   "line 1, column 12: { is never closed",
+		"break;":                      "line 1, column 1: break is outside of a loop",
+		"$a = 1;\n  1 = $a;":          "line 2, column 3: = can only assign to a variable",
+		"$a = connection.discard;":    "line 1, column 6: connection.discard isn't followed by ( to call it; variables start with $",
+		"$a = 1 @ 2;":                 `line 1, column 8: unexpected character '@'`,
+		"$a = 1.2.3;":                 `line 1, column 6: "1.2.3" isn't a valid number`,
+		"foreach( $a in [ 1, 2 ) { }": `line 1, column 23: expected "," between the elements of [, but found ")"`,
+		"sub f( $a $b ) { }":          `line 1, column 11: expected "," between the arguments of subroutine f, but found the variable $b`,
+		"}":                           `line 1, column 1: expected an expression, but found "}"`,
+		"log.info( \"a\", );\nsub f() { for( $i = 0; ) {} }": `line 1, column 16: expected an expression, but found ")"`,
+	}
+	for rule, expected := range invalid {
+		_, err := util.ParseTrafficScript(rule)
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q parsing %q, got %v", expected, rule, err)
+		}
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	validate := resourceRule().Schema["rule"].ValidateFunc
+
+	ws, errors := validate("sub audit() { log.info( \"rule\" ); }\nimport shared;\naudit();\nshared.check();\nsys.time.hour();\npool.use( $name );\n", "rule")
+	if len(ws) != 0 || len(errors) != 0 {
+		t.Errorf("expected no warnings or errors, got %v, %v", ws, errors)
+	}
+
+	ws, errors = validate("http.setHeadr( \"X\", \"1\" );\n\n$a = audit();\nPool.Use( \"web\" );\n", "rule")
+	if len(errors) != 0 {
+		t.Errorf("unexpected errors: %v", errors)
+	}
+	expected := []string{
+		`[WARN] "rule" line 1, column 1: http.setHeadr is not a known TrafficScript function`,
+		`[WARN] "rule" line 3, column 6: audit is not a known TrafficScript function`,
+	}
+	if strings.Join(ws, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected warnings %v, got %v", expected, ws)
+	}
+
+	_, errors = validate("if( 1 ) {\n  connection.discard()\n}\n", "rule")
+	if len(errors) != 1 || errors[0].Error() != `[ERROR] "rule" is not valid TrafficScript: line 3, column 1: expected ";" at the end of the statement, but found "}"` {
+		t.Errorf("expected an error with the line and column, got %v", errors)
+	}
+}
+
+func TestRuleWarningsReturnedWhenValidating(t *testing.T) {
+	ws, errors := Provider().ValidateResource("pulsevtm_rule", testResourceConfig(t, map[string]interface{}{
+		"name": "routing", "rule": "sys.time.hours();\n",
+	}))
+	if len(errors) != 0 || len(ws) != 1 || !strings.Contains(ws[0], "sys.time.hours is not a known TrafficScript function") {
+		t.Errorf("expected a warning for the unknown function, got %v, %v", ws, errors)
+	}
+}
+
 func testAccPulseVTMRuleCheckDestroy(state *terraform.State, name string) error {
 	config := testAccProvider.Meta().(map[string]interface{})
 
@@ -1,10 +1,14 @@
 package pulsevtm
 
 import (
+	"crypto/x509"
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"net/http"
+	"strings"
 )
 
 func resourceSSLCasFile() *schema.Resource {
@@ -22,31 +26,111 @@ func resourceSSLCasFile() *schema.Resource {
 				ForceNew:    true,
 			},
 			"ssl_cas_config": {
-				Type:        schema.TypeString,
-				Description: "The CA including CRL",
-				Required:    true,
+				Type:             schema.TypeString,
+				Description:      "The CA including CRL",
+				Optional:         true,
+				Computed:         true,
+				ConflictsWith:    []string{"certificates", "crls"},
+				ValidateFunc:     util.ValidateCABundle,
+				DiffSuppressFunc: util.SuppressEquivalentCABundle,
+			},
+			"certificates": {
+				Type:          schema.TypeSet,
+				Description:   "The PEM encoded certificates of the bundle, as an alternative to ssl_cas_config",
+				Optional:      true,
+				ConflictsWith: []string{"ssl_cas_config"},
+				Elem:          &schema.Schema{Type: schema.TypeString, ValidateFunc: util.ValidateCACertificate},
+				Set:           util.HashCABundleEntry,
+			},
+			"crls": {
+				Type:          schema.TypeSet,
+				Description:   "The PEM encoded certificate revocation lists of the bundle, as an alternative to ssl_cas_config",
+				Optional:      true,
+				ConflictsWith: []string{"ssl_cas_config"},
+				Elem:          &schema.Schema{Type: schema.TypeString, ValidateFunc: util.ValidateCRL},
+				Set:           util.HashCABundleEntry,
 			},
 		},
 	}
 }
 
+// sslCasUsesLists : whether the bundle is given as lists of certificates and CRLs rather than in ssl_cas_config
+func sslCasUsesLists(d *schema.ResourceData) bool {
+	return d.Get("certificates").(*schema.Set).Len() > 0 || d.Get("crls").(*schema.Set).Len() > 0
+}
+
+// buildSSLCasConfig : returns the bundle to upload, either ssl_cas_config or built from the lists of certificates and
+// CRLs
+func buildSSLCasConfig(d *schema.ResourceData) (string, error) {
+	if !sslCasUsesLists(d) {
+		return d.Get("ssl_cas_config").(string), nil
+	}
+	entries := make([]string, 0)
+	for _, attribute := range []string{"certificates", "crls"} {
+		for _, entry := range d.Get(attribute).(*schema.Set).List() {
+			entries = append(entries, entry.(string))
+		}
+	}
+	caBundle, err := util.ParseCABundle(strings.Join(entries, "\n"))
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst building %s: %v", d.Get("name"), err)
+	}
+	return caBundle.PEM(), nil
+}
+
+// checkSSLCasConfig : checks when planning that the bundle is given, in ssl_cas_config or as certificates or crls
+func checkSSLCasConfig(s *terraform.InstanceState, c *terraform.ResourceConfig) error {
+	for _, attribute := range []string{"ssl_cas_config", "certificates", "crls"} {
+		if c.IsSet(attribute) {
+			return nil
+		}
+	}
+	return fmt.Errorf("[ERROR] PulseVTM SSL cas config file %v needs one of ssl_cas_config, certificates or crls", c.Config["name"])
+}
+
+// setSSLCasLists : sets the lists of certificates and CRLs from a bundle, re-encoding each entry on its own
+func setSSLCasLists(d *schema.ResourceData, bundle string) error {
+	caBundle, err := util.ParseCABundle(bundle)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst parsing %s: %v", d.Id(), err)
+	}
+	certificates := make([]interface{}, 0)
+	for _, certificate := range caBundle.Certificates {
+		certificates = append(certificates, (&util.CABundle{Certificates: []*x509.Certificate{certificate}}).PEM())
+	}
+	crls := make([]interface{}, 0)
+	for _, crl := range caBundle.CRLs {
+		crls = append(crls, (&util.CABundle{CRLs: []*util.CRL{crl}}).PEM())
+	}
+	err = d.Set("certificates", schema.NewSet(util.HashCABundleEntry, certificates))
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst setting attribute certificates: %v", err)
+	}
+	err = d.Set("crls", schema.NewSet(util.HashCABundleEntry, crls))
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst setting attribute crls: %v", err)
+	}
+	return nil
+}
+
 func resourceSSLCasCreate(d *schema.ResourceData, m interface{}) error {
 
 	config := m.(map[string]interface{})
 	client := config["octetClient"].(*api.Client)
 
-	var name, sslCasConfig string
+	var name string
 
 	if v, ok := d.GetOk("name"); ok && v != "" {
 		name = v.(string)
 	}
-	if v, ok := d.GetOk("ssl_cas_config"); ok {
-		sslCasConfig = v.(string)
+	sslCasConfig, err := buildSSLCasConfig(d)
+	if err != nil {
+		return err
 	}
 
-	err := client.Set("ssl/cas", name, []byte(sslCasConfig), nil)
+	err = client.Set("ssl/cas", name, []byte(sslCasConfig), nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM SSL cas config file error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
@@ -74,6 +158,11 @@ func resourceSSLCasRead(d *schema.ResourceData, m interface{}) error {
 	if err != nil {
 		return fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst setting attribute  ssl_cas_config: %v", err)
 	}
+	// The lists are only read back when they're in use, so a bundle given in ssl_cas_config doesn't show a diff
+	// against them
+	if sslCasUsesLists(d) {
+		return setSSLCasLists(d, string(*sslCasConfig))
+	}
 
 	return nil
 }
@@ -84,17 +173,14 @@ func resourceSSLCasUpdate(d *schema.ResourceData, m interface{}) error {
 	client := config["octetClient"].(*api.Client)
 
 	name := d.Id()
-	var sslCasConfig string
-
-	if d.HasChange("ssl_cas_config") {
-		if v, ok := d.GetOk("ssl_cas_config"); ok {
-			sslCasConfig = v.(string)
-		}
+	sslCasConfig, err := buildSSLCasConfig(d)
+	if err != nil {
+		return err
 	}
 
-	err := client.Set("ssl/cas", name, []byte(sslCasConfig), nil)
+	err = client.Set("ssl/cas", name, []byte(sslCasConfig), nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM SSL cas config file error whilst updating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM SSL cas config file error whilst updating %s: %v", name, err)
 	}
 	err = d.Set("ssl_cas_config", sslCasConfig)
 	if err != nil {
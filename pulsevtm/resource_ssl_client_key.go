@@ -12,10 +12,19 @@ func resourceSSLClientKey() *schema.Resource {
 		Update: resourceSSLClientKeyUpdate,
 		Delete: resourceSSLClientKeyDelete,
 
-		Schema: util.SchemaSSLKey(),
+		Schema: sslClientKeySchema(),
 	}
 }
 
+// sslClientKeySchema : the SSL key schema along with the details of the certificate
+func sslClientKeySchema() map[string]*schema.Schema {
+	sslClientKeySchema := util.SchemaSSLKey()
+	for attribute, attributeSchema := range util.SchemaSSLKeyCertificateDetails() {
+		sslClientKeySchema[attribute] = attributeSchema
+	}
+	return sslClientKeySchema
+}
+
 func resourceSSLClientKeyCreate(d *schema.ResourceData, m interface{}) error {
 	err := util.SSLKeyCreate(d, m, "ssl/client_keys")
 	if err != nil {
@@ -25,10 +34,14 @@ func resourceSSLClientKeyCreate(d *schema.ResourceData, m interface{}) error {
 }
 
 func resourceSSLClientKeyRead(d *schema.ResourceData, m interface{}) error {
+	previousFingerprint := d.Get("sha256_fingerprint").(string)
 	err := util.SSLKeyRead(d, m, "ssl/client_keys")
 	if err != nil {
 		return err
 	}
+	if d.Id() != "" {
+		util.SSLKeyCertificateDrifted(d, previousFingerprint)
+	}
 	return nil
 }
 
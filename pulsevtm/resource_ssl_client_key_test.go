@@ -1,15 +1,26 @@
 package pulsevtm
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net/http"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 )
 
 func TestAccPulseVTMSSLClientKeyBasic(t *testing.T) {
@@ -478,3 +489,168 @@ REQUEST
 }
 `, name)
 }
+
+// testSSLCertificate : issues a certificate for a new key with issuer and its key, or self-signed when issuer is nil,
+// returning the PEM encoded key and certificate
+func testSSLCertificate(t *testing.T, commonName string, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, notAfter time.Time) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{commonName},
+		NotBefore:             notAfter.AddDate(-1, 0, 0),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  issuer == nil,
+	}
+	if issuer == nil {
+		issuer, issuerKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), issuerKey)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return certificate, key,
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestSSLKeyValidation(t *testing.T) {
+	nextYear := time.Now().AddDate(1, 0, 0)
+	ca, caKey, _, caPEM := testSSLCertificate(t, "Example CA", nil, nil, nextYear)
+	_, _, serverKeyPEM, serverPEM := testSSLCertificate(t, "www.example.com", ca, caKey, nextYear)
+	_, _, _, expiredPEM := testSSLCertificate(t, "old.example.com", ca, caKey, time.Now().AddDate(0, 0, -1))
+	sslClientKey := resourceSSLClientKey()
+
+	ws, es := sslClientKey.Schema["public"].ValidateFunc(serverPEM+caPEM, "public")
+	if len(ws) != 0 || len(es) != 0 {
+		t.Errorf("expected a chain in order to be valid, got %v %v", ws, es)
+	}
+	_, es = sslClientKey.Schema["public"].ValidateFunc(caPEM+serverPEM, "public")
+	if len(es) != 1 || !regexp.MustCompile(`certificate 1 \(CN=Example CA\) is not issued by certificate 2 \(CN=www.example.com\)`).MatchString(es[0].Error()) {
+		t.Errorf("expected an error for a chain out of order, got %v", es)
+	}
+	_, es = sslClientKey.Schema["public"].ValidateFunc("not a certificate", "public")
+	if len(es) != 1 {
+		t.Errorf("expected an error for an invalid certificate, got %v", es)
+	}
+	ws, es = sslClientKey.Schema["public"].ValidateFunc(expiredPEM, "public")
+	if len(es) != 0 || len(ws) != 1 || !strings.Contains(ws[0], "certificate 1 (CN=old.example.com) expired at") {
+		t.Errorf("expected a warning for an expired certificate, got %v %v", ws, es)
+	}
+	_, es = sslClientKey.Schema["private"].ValidateFunc(serverPEM, "private")
+	if len(es) != 1 {
+		t.Errorf("expected an error for a certificate given as the private key, got %v", es)
+	}
+
+	// The key pair is checked when planning, against the private key in state when it's not configured
+	provider := Provider().(*pulseVTMProvider)
+	info := &terraform.InstanceInfo{Type: "pulsevtm_ssl_client_key"}
+	_, err := provider.Diff(info, nil, testResourceConfig(t, map[string]interface{}{
+		"name": "example", "private": serverKeyPEM, "public": serverPEM + caPEM,
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err = provider.Diff(info, nil, testResourceConfig(t, map[string]interface{}{
+		"name": "example", "private": serverKeyPEM, "public": caPEM,
+	}))
+	if err == nil || !strings.Contains(err.Error(), "SSL key example: the certificate (CN=Example CA) is not for the private key") {
+		t.Errorf("expected an error for a certificate not matching the key, got %v", err)
+	}
+	state := &terraform.InstanceState{ID: "example", Attributes: map[string]string{"name": "example", "private": serverKeyPEM}}
+	_, err = provider.Diff(&terraform.InstanceInfo{Type: "pulsevtm_ssl_server_key"}, state, testResourceConfig(t, map[string]interface{}{
+		"name": "example", "public": expiredPEM,
+	}))
+	if err == nil {
+		t.Errorf("expected an error for a certificate not matching the private key in state")
+	}
+}
+
+func TestSSLKeyCertificateDetailsAndDrift(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	nextYear := time.Now().AddDate(1, 0, 0).Truncate(time.Second)
+	ca, caKey, _, caPEM := testSSLCertificate(t, "Example CA", nil, nil, nextYear)
+	certificate, _, keyPEM, publicPEM := testSSLCertificate(t, "www.example.com", ca, caKey, nextYear)
+	sslClientKey := resourceSSLClientKey()
+
+	d := schema.TestResourceDataRaw(t, sslClientKey.Schema, map[string]interface{}{
+		"name":    "example",
+		"private": keyPEM,
+		"public":  publicPEM + caPEM,
+	})
+	err := sslClientKey.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"subject":   "CN=www.example.com",
+		"not_after": nextYear.UTC().Format(time.RFC3339),
+		"sans.#":    1,
+		"sans.0":    "www.example.com",
+	}
+	for key, value := range expected {
+		if d.Get(key) != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, d.Get(key))
+		}
+	}
+	fingerprint := d.Get("sha256_fingerprint").(string)
+	checksum := sha256.Sum256(certificate.Raw)
+	if len(fingerprint) != 95 || !strings.HasPrefix(fingerprint, fmt.Sprintf("%02X:%02X:", checksum[0], checksum[1])) {
+		t.Errorf("unexpected sha256_fingerprint %s", fingerprint)
+	}
+
+	// Reading the key back unchanged keeps the private key
+	err = sslClientKey.Read(d, m)
+	if err != nil || d.Get("private") != keyPEM {
+		t.Errorf("expected the private key to be kept: %v", err)
+	}
+
+	// A key pair replaced on the traffic manager clears the private key for it to be sent again
+	_, _, _, replacedPEM := testSSLCertificate(t, "replaced.example.com", ca, caKey, nextYear)
+	server.setResource("ssl/client_keys/example", map[string]interface{}{
+		"properties": map[string]interface{}{"basic": map[string]interface{}{"note": "", "public": replacedPEM, "request": ""}},
+	})
+	err = sslClientKey.Read(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("private") != "" || d.Get("subject") != "CN=replaced.example.com" || d.Get("sha256_fingerprint") == fingerprint {
+		t.Errorf("expected the replaced certificate to be detected, got subject %s", d.Get("subject"))
+	}
+}
+
+func TestSSLServerKeyGeneratedKeyDrift(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	sslServerKey := resourceSSLServerKey()
+
+	d := schema.TestResourceDataRaw(t, sslServerKey.Schema, map[string]interface{}{
+		"name":     "generated",
+		"generate": []interface{}{map[string]interface{}{"key_type": "ecdsa", "common_name": "www.example.com"}},
+	})
+	err := sslServerKey.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _, _, replacedPEM := testSSLCertificate(t, "replaced.example.com", nil, nil, time.Now().AddDate(1, 0, 0))
+	server.setResource("ssl/server_keys/generated", map[string]interface{}{
+		"properties": map[string]interface{}{"basic": map[string]interface{}{"note": "", "public": replacedPEM, "request": ""}},
+	})
+	err = sslServerKey.Read(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("generate.#") != 0 {
+		t.Errorf("expected the generate block to be cleared for the key pair to be generated again")
+	}
+}
@@ -12,12 +12,30 @@ func resourceSSLServerKey() *schema.Resource {
 		Update: resourceSSLServerKeyUpdate,
 		Delete: resourceSSLServerKeyDelete,
 
-		Schema: util.SchemaSSLKey(),
+		Schema: sslServerKeySchema(),
 	}
 }
 
+// sslServerKeySchema : the SSL key schema, along with the generate block and the details of the certificate. The
+// key, certificate and request are computed as they may be generated.
+func sslServerKeySchema() map[string]*schema.Schema {
+	sslServerKeySchema := util.SchemaSSLKey()
+	for _, attribute := range []string{"private", "public", "request"} {
+		sslServerKeySchema[attribute].Computed = true
+	}
+	sslServerKeySchema["generate"] = util.SchemaSSLKeyGenerate()
+	for attribute, attributeSchema := range util.SchemaSSLKeyCertificateDetails() {
+		sslServerKeySchema[attribute] = attributeSchema
+	}
+	return sslServerKeySchema
+}
+
 func resourceSSLServerKeyCreate(d *schema.ResourceData, m interface{}) error {
-	err := util.SSLKeyCreate(d, m, "ssl/server_keys")
+	err := util.SSLKeyGenerate(d)
+	if err != nil {
+		return err
+	}
+	err = util.SSLKeyCreate(d, m, "ssl/server_keys")
 	if err != nil {
 		return err
 	}
@@ -25,10 +43,15 @@ func resourceSSLServerKeyCreate(d *schema.ResourceData, m interface{}) error {
 }
 
 func resourceSSLServerKeyRead(d *schema.ResourceData, m interface{}) error {
+	previousFingerprint := d.Get("sha256_fingerprint").(string)
 	err := util.SSLKeyRead(d, m, "ssl/server_keys")
 	if err != nil {
 		return err
 	}
+	// A generated key pair replaced outside of Terraform is generated again
+	if d.Id() != "" && util.SSLKeyCertificateDrifted(d, previousFingerprint) {
+		d.Set("generate", []interface{}{})
+	}
 	return nil
 }
 
@@ -1,15 +1,22 @@
 package pulsevtm
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
 func TestAccPulseVTMSSLServerKeyBasic(t *testing.T) {
@@ -477,3 +484,111 @@ REQUEST
 }
 `, name)
 }
+
+func TestSSLServerKeyGenerateSelfSigned(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	sslServerKey := resourceSSLServerKey()
+
+	d := schema.TestResourceDataRaw(t, sslServerKey.Schema, map[string]interface{}{
+		"name": "generated",
+		"generate": []interface{}{map[string]interface{}{
+			"key_type":      "ecdsa",
+			"ecdsa_curve":   "P384",
+			"common_name":   "www.example.com",
+			"organization":  "Example",
+			"country":       "GB",
+			"dns_names":     []interface{}{"example.com"},
+			"ip_addresses":  []interface{}{"192.0.2.1"},
+			"validity_days": 30,
+		}},
+	})
+	err := sslServerKey.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	basic := server.getResource("ssl/server_keys/generated")["properties"].(map[string]interface{})["basic"].(map[string]interface{})
+	if basic["private"] != d.Get("private") || basic["public"] != d.Get("public") {
+		t.Errorf("expected the generated key and certificate to be stored on the vTM")
+	}
+	block, _ := pem.Decode([]byte(d.Get("private").(string)))
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		t.Fatalf("expected an EC private key, got %s", d.Get("private"))
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil || key.Curve != elliptic.P384() {
+		t.Errorf("expected a P384 key: %v", err)
+	}
+
+	block, _ = pem.Decode([]byte(d.Get("public").(string)))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse the generated certificate: %v", err)
+	}
+	if err := certificate.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature); err != nil {
+		t.Errorf("expected the certificate to be self-signed: %v", err)
+	}
+	if publicKey := certificate.PublicKey.(*ecdsa.PublicKey); publicKey.X.Cmp(key.X) != 0 || publicKey.Y.Cmp(key.Y) != 0 {
+		t.Errorf("expected the certificate to be for the generated key")
+	}
+	if certificate.Subject.CommonName != "www.example.com" || certificate.Subject.Organization[0] != "Example" || certificate.Subject.Country[0] != "GB" {
+		t.Errorf("unexpected subject %s", util.CertificateSubject(certificate))
+	}
+	if fmt.Sprint(certificate.DNSNames) != "[www.example.com example.com]" || fmt.Sprint(certificate.IPAddresses) != "[192.0.2.1]" {
+		t.Errorf("unexpected SANs %v %v", certificate.DNSNames, certificate.IPAddresses)
+	}
+	if validity := certificate.NotAfter.Sub(certificate.NotBefore); validity != 30*24*time.Hour {
+		t.Errorf("expected the certificate to be valid for 30 days, got %s", validity)
+	}
+
+	if d.Get("not_after") != certificate.NotAfter.UTC().Format(time.RFC3339) {
+		t.Errorf("unexpected not_after %s", d.Get("not_after"))
+	}
+	if !regexp.MustCompile(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`).MatchString(d.Get("sha256_fingerprint").(string)) {
+		t.Errorf("unexpected sha256_fingerprint %s", d.Get("sha256_fingerprint"))
+	}
+}
+
+func TestSSLServerKeyGenerateRequest(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	sslServerKey := resourceSSLServerKey()
+
+	d := schema.TestResourceDataRaw(t, sslServerKey.Schema, map[string]interface{}{
+		"name": "requested",
+		"generate": []interface{}{map[string]interface{}{
+			"common_name": "www.example.com",
+			"self_signed": false,
+		}},
+	})
+	err := sslServerKey.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	block, _ := pem.Decode([]byte(d.Get("private").(string)))
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		t.Fatalf("expected an RSA private key, got %s", d.Get("private"))
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil || key.N.BitLen() != 2048 {
+		t.Errorf("expected a 2048 bit key: %v", err)
+	}
+	block, _ = pem.Decode([]byte(d.Get("request").(string)))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		t.Fatalf("expected a certificate signing request, got %s", d.Get("request"))
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil || request.CheckSignature() != nil || request.Subject.CommonName != "www.example.com" {
+		t.Errorf("expected a signed request for www.example.com: %v", err)
+	}
+	if d.Get("public") != "" || d.Get("sha256_fingerprint") != "" || d.Get("not_after") != "" {
+		t.Errorf("expected no certificate until the request is signed, got %q", d.Get("public"))
+	}
+	if _, stored := server.getResource("ssl/server_keys/requested")["properties"].(map[string]interface{})["basic"].(map[string]interface{})["request"]; !stored {
+		t.Errorf("expected the request to be stored on the vTM")
+	}
+}
@@ -4,15 +4,17 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"net/http"
+	"strings"
 )
 
 func resourceSSLTicketKey() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceSSLTicketKeySet,
+		Create: resourceSSLTicketKeyCreate,
 		Read:   resourceSSLTicketKeyRead,
-		Update: resourceSSLTicketKeySet,
+		Update: resourceSSLTicketKeyUpdate,
 		Delete: resourceSSLTicketKeyDelete,
 
 		Schema: map[string]*schema.Schema{
@@ -30,65 +32,134 @@ func resourceSSLTicketKey() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{"aes_256_cbc_hmac_sha256"}, false),
 			},
 			"identifier": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "A 16-byte key identifier, with each byte encoded as two hexadecimal digits.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"rotation"},
+				Description:   "A 16-byte key identifier, with each byte encoded as two hexadecimal digits.",
 			},
 			"key": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Sensitive:   true,
-				Description: "The session ticket encryption key, with each byte encoded as two hexadecimal digits.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"rotation"},
+				Description:   "The session ticket encryption key, with each byte encoded as two hexadecimal digits.",
 			},
 			"validity_end": {
-				Type:         schema.TypeInt,
-				Required:     true,
-				Description:  "The latest time at which this key may be used to encrypt new session tickets. Given as number of seconds since the epoch",
-				ValidateFunc: validation.IntAtLeast(0),
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"rotation"},
+				Description:   "The latest time at which this key may be used to encrypt new session tickets. Given as number of seconds since the epoch",
+				ValidateFunc:  validation.IntAtLeast(0),
 			},
 			"validity_start": {
-				Type:         schema.TypeInt,
-				Required:     true,
-				Description:  "The earliest time at which this key may be used to encrypt new session tickets. Given as number of seconds since the epoch",
-				ValidateFunc: validation.IntAtLeast(0),
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"rotation"},
+				Description:   "The earliest time at which this key may be used to encrypt new session tickets. Given as number of seconds since the epoch",
+				ValidateFunc:  validation.IntAtLeast(0),
+			},
+			"rotation":     schemaTicketKeyRotation(),
+			"managed_keys": schemaManagedTicketKeys(),
+			"next_rotation": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The time, in seconds since the epoch, after which the managed keys are rotated when applying",
 			},
 		},
 	}
 }
 
-func resourceSSLTicketKeySet(d *schema.ResourceData, m interface{}) error {
+// checkSSLTicketKeyConfig : checks when planning that a ticket key is given unless it's generated by rotation
+func checkSSLTicketKeyConfig(s *terraform.InstanceState, c *terraform.ResourceConfig) error {
+	if c.IsSet("rotation") {
+		return nil
+	}
+	missing := make([]string, 0)
+	for _, attribute := range []string{"identifier", "key", "validity_end", "validity_start"} {
+		if !c.IsSet(attribute) {
+			missing = append(missing, attribute)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("[ERROR] PulseVTM SSL Ticket Key %v needs %s, or a rotation block", c.Config["name"], strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func resourceSSLTicketKeyCreate(d *schema.ResourceData, m interface{}) error {
 	config := m.(map[string]interface{})
 	client := config["jsonClient"].(*api.Client)
-	sslTicketKeyConfiguration := make(map[string]interface{})
-	sslTicketKeyPropertiesConfiguration := make(map[string]interface{})
-	sslTicketKeyBasicConfiguration := make(map[string]interface{})
-
 	name := d.Get("name").(string)
 
-	sslTicketKeyBasicConfiguration["algorithm"] = d.Get("algorithm").(string)
-	sslTicketKeyBasicConfiguration["id"] = d.Get("identifier").(string)
-	sslTicketKeyBasicConfiguration["key"] = d.Get("key").(string)
-	sslTicketKeyBasicConfiguration["validity_end"] = d.Get("validity_end").(int)
-	sslTicketKeyBasicConfiguration["validity_start"] = d.Get("validity_start").(int)
-
-	sslTicketKeyPropertiesConfiguration["basic"] = sslTicketKeyBasicConfiguration
-	sslTicketKeyConfiguration["properties"] = sslTicketKeyPropertiesConfiguration
+	if _, _, ok := ticketKeyRotation(d); ok {
+		d.SetId(name)
+		err := rotateManagedTicketKeys(d, client)
+		if err != nil {
+			return err
+		}
+		return resourceSSLTicketKeyRead(d, m)
+	}
 
-	err := client.Set("ssl/ticket_keys", name, &sslTicketKeyConfiguration, nil)
+	err := setTicketKey(client, name, d.Get("algorithm").(string), d.Get("identifier").(string), d.Get("key").(string),
+		d.Get("validity_start").(int), d.Get("validity_end").(int))
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst creating SSL Ticket Key %s: %v", name, err)
+		return err
 	}
 	d.SetId(name)
 
 	return resourceSSLTicketKeyRead(d, m)
 }
 
+func resourceSSLTicketKeyUpdate(d *schema.ResourceData, m interface{}) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+	name := d.Id()
+	oldRotation, _ := d.GetChange("rotation")
+
+	if _, _, ok := ticketKeyRotation(d); ok {
+		// The key given by hand is replaced by the managed keys
+		if len(oldRotation.([]interface{})) == 0 {
+			err := deleteTicketKey(client, name)
+			if err != nil {
+				return err
+			}
+		}
+		err := rotateManagedTicketKeys(d, client)
+		if err != nil {
+			return err
+		}
+		return resourceSSLTicketKeyRead(d, m)
+	}
+
+	err := setTicketKey(client, name, d.Get("algorithm").(string), d.Get("identifier").(string), d.Get("key").(string),
+		d.Get("validity_start").(int), d.Get("validity_end").(int))
+	if err != nil {
+		return err
+	}
+	// The managed keys are replaced by the key given by hand
+	for _, key := range managedTicketKeys(d) {
+		err := deleteTicketKey(client, key.name)
+		if err != nil {
+			return err
+		}
+	}
+	err = setManagedTicketKeys(d, []*managedTicketKey{})
+	if err != nil {
+		return err
+	}
+
+	return resourceSSLTicketKeyRead(d, m)
+}
+
 func resourceSSLTicketKeyRead(d *schema.ResourceData, m interface{}) error {
 
 	name := d.Id()
 	config := m.(map[string]interface{})
 	client := config["jsonClient"].(*api.Client)
 	client.WorkWithConfigurationResources()
+	if _, _, ok := ticketKeyRotation(d); ok {
+		return readManagedTicketKeys(d, client)
+	}
 	sslTicketKeyConfiguration := make(map[string]interface{})
 
 	err := client.GetByName("ssl/ticket_keys", name, &sslTicketKeyConfiguration)
@@ -121,6 +192,18 @@ func resourceSSLTicketKeyRead(d *schema.ResourceData, m interface{}) error {
 }
 
 func resourceSSLTicketKeyDelete(d *schema.ResourceData, m interface{}) error {
+	if _, _, ok := ticketKeyRotation(d); ok {
+		config := m.(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+		for _, key := range managedTicketKeys(d) {
+			err := deleteTicketKey(client, key.name)
+			if err != nil {
+				return err
+			}
+		}
+		d.SetId("")
+		return nil
+	}
 	return DeleteResource("ssl/ticket_keys", d, m)
 }
 
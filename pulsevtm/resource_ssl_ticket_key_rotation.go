@@ -0,0 +1,387 @@
+package pulsevtm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ticketKeyBytes : the size of the identifiers and keys generated for managed ticket keys
+const ticketKeyBytes = 16
+
+// ticketKeyClock : returns the time rotation of managed ticket keys is judged by
+var ticketKeyClock = time.Now
+
+// managedTicketKey : a ticket key generated and rotated by the provider
+type managedTicketKey struct {
+	name          string
+	identifier    string
+	key           string
+	validityStart int
+	validityEnd   int
+}
+
+// schemaTicketKeyRotation : the schema of the rotation block of an SSL Ticket Key
+func schemaTicketKeyRotation() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{"identifier", "key", "validity_start", "validity_end"},
+		Description: "Generate the ticket keys and rotate them when applying once the lifetime of the newest has " +
+			"passed, rather than giving identifier, key, validity_start and validity_end",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"keys": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      3,
+					ValidateFunc: validateTicketKeyCount,
+					Description:  "The number of keys kept, each valid for encrypting session tickets for this many lifetimes",
+				},
+				"lifetime": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      86400,
+					ValidateFunc: validateTicketKeyLifetime,
+					Description:  "The number of seconds between the validity start of one key and the next",
+				},
+			},
+		},
+	}
+}
+
+// schemaManagedTicketKeys : the schema of the ticket keys generated by the rotation block
+func schemaManagedTicketKeys() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The ticket keys generated by rotation, oldest first",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"identifier": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"validity_start": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"validity_end": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// validateTicketKeyCount : check at least two keys are kept, so tickets outlive a rotation
+func validateTicketKeyCount(v interface{}, k string) (ws []string, errors []error) {
+	if v.(int) < 2 {
+		errors = append(errors, fmt.Errorf("[ERROR] %q must keep at least 2 keys", k))
+	}
+	return
+}
+
+// validateTicketKeyLifetime : check the lifetime of a key is at least a minute
+func validateTicketKeyLifetime(v interface{}, k string) (ws []string, errors []error) {
+	if v.(int) < 60 {
+		errors = append(errors, fmt.Errorf("[ERROR] %q must be at least 60 seconds", k))
+	}
+	return
+}
+
+// ticketKeyRotation : returns the number of keys to keep and their lifetime, and whether rotation is configured
+func ticketKeyRotation(d *schema.ResourceData) (int, int, bool) {
+	rotation := d.Get("rotation").([]interface{})
+	if len(rotation) == 0 || rotation[0] == nil {
+		return 0, 0, false
+	}
+	settings := rotation[0].(map[string]interface{})
+	return settings["keys"].(int), settings["lifetime"].(int), true
+}
+
+// randomHex : returns size random bytes, each encoded as two hexadecimal digits
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// generateTicketKey : generates a ticket key valid for encrypting session tickets from start for keys lifetimes
+func generateTicketKey(name string, generation, start, keys, lifetime int) (*managedTicketKey, error) {
+	identifier, err := randomHex(ticketKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	key, err := randomHex(ticketKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &managedTicketKey{
+		name:          name + "-" + strconv.Itoa(generation),
+		identifier:    identifier,
+		key:           key,
+		validityStart: start,
+		validityEnd:   start + keys*lifetime,
+	}, nil
+}
+
+// ticketKeyGeneration : returns the generation of a managed key from its name, and whether the name is that of a
+// managed key of the SSL Ticket Key
+func ticketKeyGeneration(name string, key *managedTicketKey) (int, bool) {
+	prefix := name + "-"
+	if !strings.HasPrefix(key.name, prefix) {
+		return 0, false
+	}
+	generation, err := strconv.Atoi(strings.TrimPrefix(key.name, prefix))
+	if err != nil {
+		return 0, false
+	}
+	return generation, true
+}
+
+// rotateTicketKeys : returns the keys to keep and the keys to add for the keys existing at now. A new key starting at
+// now is added when there are no keys or once the lifetime of the newest has passed, keys which have expired are
+// dropped and only the newest keys are kept. Keys missing from those kept, as when there were none or some were
+// removed outside of Terraform, are generated at once with validity windows staggered by lifetime before the oldest,
+// so there are always as many keys as configured.
+func rotateTicketKeys(name string, existing []*managedTicketKey, keys, lifetime int, now time.Time) ([]*managedTicketKey, []*managedTicketKey, error) {
+	kept := make([]*managedTicketKey, 0)
+	for _, key := range existing {
+		if int64(key.validityEnd) > now.Unix() {
+			kept = append(kept, key)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].validityStart < kept[j].validityStart })
+
+	added := make([]*managedTicketKey, 0)
+	generation := 0
+	for _, key := range existing {
+		if keyGeneration, ok := ticketKeyGeneration(name, key); ok && keyGeneration >= generation {
+			generation = keyGeneration + 1
+		}
+	}
+	starts := make([]int, 0)
+	if len(kept) == 0 || int64(kept[len(kept)-1].validityStart+lifetime) <= now.Unix() {
+		starts = append(starts, int(now.Unix()))
+	}
+	// Missing keys start before the oldest, but no earlier than needed to be valid for at least a lifetime
+	start, earliest := int(now.Unix()), int(now.Unix())-(keys-1)*lifetime
+	if len(kept) > 0 {
+		start = kept[0].validityStart
+	}
+	for missing := keys - len(kept) - len(starts); missing > 0; missing-- {
+		start -= lifetime
+		if start < earliest {
+			start = earliest
+		}
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+	for _, start := range starts {
+		key, err := generateTicketKey(name, generation, start, keys, lifetime)
+		if err != nil {
+			return nil, nil, err
+		}
+		generation++
+		added = append(added, key)
+	}
+
+	if surplus := len(kept) + len(added) - keys; surplus > 0 {
+		kept = kept[surplus:]
+	}
+	return kept, added, nil
+}
+
+// nextTicketKeyRotation : returns the time, in seconds since the epoch, once which keys are rotated when applying. The
+// rotation is due at once when fewer keys than configured are kept, so the missing keys are generated when applying.
+func nextTicketKeyRotation(keys []*managedTicketKey, count, lifetime int) int {
+	if len(keys) == 0 || len(keys) < count {
+		return 0
+	}
+	newest := 0
+	for _, key := range keys {
+		if key.validityStart > newest {
+			newest = key.validityStart
+		}
+	}
+	return newest + lifetime
+}
+
+// managedTicketKeys : returns the managed keys held in state
+func managedTicketKeys(d *schema.ResourceData) []*managedTicketKey {
+	keys := make([]*managedTicketKey, 0)
+	for _, raw := range d.Get("managed_keys").([]interface{}) {
+		key := raw.(map[string]interface{})
+		keys = append(keys, &managedTicketKey{
+			name:          key["name"].(string),
+			identifier:    key["identifier"].(string),
+			validityStart: key["validity_start"].(int),
+			validityEnd:   key["validity_end"].(int),
+		})
+	}
+	return keys
+}
+
+// setManagedTicketKeys : sets the managed keys in state, along with when they're next rotated. The key material
+// isn't kept in state.
+func setManagedTicketKeys(d *schema.ResourceData, keys []*managedTicketKey) error {
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].validityStart < keys[j].validityStart })
+	managedKeys := make([]interface{}, 0)
+	for _, key := range keys {
+		managedKeys = append(managedKeys, map[string]interface{}{
+			"name":           key.name,
+			"identifier":     key.identifier,
+			"validity_start": key.validityStart,
+			"validity_end":   key.validityEnd,
+		})
+	}
+	err := d.Set("managed_keys", managedKeys)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM SSL Ticket Key error whilst setting attribute managed_keys: %v", err)
+	}
+	count, lifetime, _ := ticketKeyRotation(d)
+	return d.Set("next_rotation", nextTicketKeyRotation(keys, count, lifetime))
+}
+
+// setTicketKey : creates or updates a ticket key on the traffic manager
+func setTicketKey(client *api.Client, name, algorithm, identifier, key string, validityStart, validityEnd int) error {
+	sslTicketKeyConfiguration := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"basic": map[string]interface{}{
+				"algorithm":      algorithm,
+				"id":             identifier,
+				"key":            key,
+				"validity_end":   validityEnd,
+				"validity_start": validityStart,
+			},
+		},
+	}
+	err := client.Set("ssl/ticket_keys", name, &sslTicketKeyConfiguration, nil)
+	if err != nil {
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst creating SSL Ticket Key %s: %v", name, err)
+	}
+	return nil
+}
+
+// deleteTicketKey : deletes a ticket key from the traffic manager, if it exists
+func deleteTicketKey(client *api.Client, name string) error {
+	err := client.Delete("ssl/ticket_keys", name)
+	if err != nil && client.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst deleting SSL Ticket Key %s: %v", name, err)
+	}
+	return nil
+}
+
+// rotateManagedTicketKeys : rotates the managed keys of an SSL Ticket Key, adding any new keys before removing the
+// old ones so there's always a key to encrypt session tickets with
+func rotateManagedTicketKeys(d *schema.ResourceData, client *api.Client) error {
+	name := d.Get("name").(string)
+	count, lifetime, _ := ticketKeyRotation(d)
+	existing := managedTicketKeys(d)
+	kept, added, err := rotateTicketKeys(name, existing, count, lifetime, ticketKeyClock())
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst generating SSL Ticket Key %s: %v", name, err)
+	}
+
+	// State follows each key added and removed, so is left accurate should one fail
+	current := append([]*managedTicketKey{}, existing...)
+	for _, key := range added {
+		err := setTicketKey(client, key.name, d.Get("algorithm").(string), key.identifier, key.key, key.validityStart, key.validityEnd)
+		if err != nil {
+			setManagedTicketKeys(d, current)
+			return err
+		}
+		log.Printf("[INFO] PulseVTM SSL Ticket Key %s added %s, valid from %d until %d", name, key.name, key.validityStart, key.validityEnd)
+		current = append(current, key)
+	}
+
+	for _, key := range existing {
+		if containsTicketKey(kept, key.name) {
+			continue
+		}
+		err := deleteTicketKey(client, key.name)
+		if err != nil {
+			setManagedTicketKeys(d, current)
+			return err
+		}
+		log.Printf("[INFO] PulseVTM SSL Ticket Key %s removed %s", name, key.name)
+		remaining := make([]*managedTicketKey, 0)
+		for _, currentKey := range current {
+			if currentKey.name != key.name {
+				remaining = append(remaining, currentKey)
+			}
+		}
+		current = remaining
+	}
+	return setManagedTicketKeys(d, current)
+}
+
+// containsTicketKey : whether a list of keys holds the key with a name
+func containsTicketKey(keys []*managedTicketKey, name string) bool {
+	for _, key := range keys {
+		if key.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readManagedTicketKeys : drops the managed keys which no longer exist on the traffic manager from state. The rotation
+// is then due, so the next plan generates keys in their place when applying.
+func readManagedTicketKeys(d *schema.ResourceData, client *api.Client) error {
+	keys := make([]*managedTicketKey, 0)
+	for _, key := range managedTicketKeys(d) {
+		sslTicketKeyConfiguration := make(map[string]interface{})
+		err := client.GetByName("ssl/ticket_keys", key.name, &sslTicketKeyConfiguration)
+		if client.StatusCode == http.StatusNotFound {
+			log.Printf("[WARN] PulseVTM SSL Ticket Key %s no longer exists", key.name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst retrieving  SSL Ticket Key %s: %v", key.name, err)
+		}
+		keys = append(keys, key)
+	}
+	return setManagedTicketKeys(d, keys)
+}
+
+// planTicketKeyRotation : adds the rotation of the managed keys of an SSL Ticket Key to its plan once it's due
+func planTicketKeyRotation(s *terraform.InstanceState, c *terraform.ResourceConfig, diff *terraform.InstanceDiff) *terraform.InstanceDiff {
+	if s == nil || s.ID == "" || !c.IsSet("rotation") || (diff != nil && (diff.Destroy || diff.RequiresNew())) {
+		return diff
+	}
+	nextRotation, err := strconv.ParseInt(s.Attributes["next_rotation"], 10, 64)
+	if err != nil || nextRotation > ticketKeyClock().Unix() {
+		return diff
+	}
+	if diff == nil {
+		diff = new(terraform.InstanceDiff)
+	}
+	if diff.Attributes == nil {
+		diff.Attributes = make(map[string]*terraform.ResourceAttrDiff)
+	}
+	diff.Attributes["next_rotation"] = &terraform.ResourceAttrDiff{
+		Old:         s.Attributes["next_rotation"],
+		NewComputed: true,
+	}
+	return diff
+}
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAccPulseVTMBasicSSLTicketKey(t *testing.T) {
@@ -122,3 +126,206 @@ resource "pulsevtm_ssl_ticket_key" "acctest" {
 }
 `, name)
 }
+
+func TestRotateTicketKeys(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	kept, added, err := rotateTicketKeys("tickets", nil, 3, 3600, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 0 || len(added) != 3 {
+		t.Fatalf("expected 3 keys to be generated, got %d kept and %d added", len(kept), len(added))
+	}
+	for i, key := range added {
+		if key.name != fmt.Sprintf("tickets-%d", i) || key.validityStart != 1600000000-(2-i)*3600 ||
+			key.validityEnd != key.validityStart+3*3600 || len(key.identifier) != 32 || len(key.key) != 32 {
+			t.Errorf("unexpected key %d: %+v", i, key)
+		}
+	}
+	if added[0].key == added[1].key || added[0].identifier == added[1].identifier {
+		t.Errorf("expected each key to be random")
+	}
+	if nextTicketKeyRotation(added, 3, 3600) != 1600003600 {
+		t.Errorf("expected the next rotation a lifetime after the newest key, got %d", nextTicketKeyRotation(added, 3, 3600))
+	}
+	if nextTicketKeyRotation(added[1:], 3, 3600) != 0 {
+		t.Errorf("expected the rotation to be due with a key missing, got %d", nextTicketKeyRotation(added[1:], 3, 3600))
+	}
+
+	// Nothing changes until the lifetime of the newest key has passed
+	kept, added, _ = rotateTicketKeys("tickets", added, 3, 3600, now.Add(3599*time.Second))
+	if len(kept) != 3 || len(added) != 0 {
+		t.Fatalf("expected no rotation, got %d kept and %d added", len(kept), len(added))
+	}
+
+	// A key is added and the oldest dropped once it has
+	kept, added, _ = rotateTicketKeys("tickets", kept, 3, 3600, now.Add(3600*time.Second))
+	if len(kept) != 2 || len(added) != 1 {
+		t.Fatalf("expected a rotation, got %d kept and %d added", len(kept), len(added))
+	}
+	if kept[0].name != "tickets-1" || kept[1].name != "tickets-2" || added[0].name != "tickets-3" ||
+		added[0].validityStart != 1600003600 {
+		t.Errorf("expected tickets-0 to be replaced by tickets-3, got %+v %+v", kept, added)
+	}
+
+	// A missing key is generated at once before the oldest, valid for at least a lifetime, leaving the next rotation as
+	// it was
+	existing := append(kept, added...)
+	missing, added, _ := rotateTicketKeys("tickets", existing[1:], 3, 3600, now.Add(3601*time.Second))
+	if len(missing) != 2 || len(added) != 1 || added[0].name != "tickets-4" || added[0].validityStart >= 1600000000 ||
+		added[0].validityEnd < 1600003601+3600 {
+		t.Fatalf("expected the missing key to be generated before the oldest, got %+v kept and %+v added", missing, added)
+	}
+	if next := nextTicketKeyRotation(append(missing, added...), 3, 3600); next != 1600007200 {
+		t.Errorf("expected the next rotation a lifetime after the newest key, got %d", next)
+	}
+
+	// Missing keys are generated along with a rotation
+	missing, added, _ = rotateTicketKeys("tickets", existing[2:], 3, 3600, now.Add(7200*time.Second))
+	if len(missing) != 1 || len(added) != 2 || added[1].validityStart != 1600007200 || added[0].validityStart != 1600000000 {
+		t.Errorf("expected a missing key and a new key to be generated, got %+v kept and %+v added", missing, added)
+	}
+
+	// Keys which have expired are dropped, and replaced as when there were none
+	kept, added, _ = rotateTicketKeys("tickets", existing, 3, 3600, now.Add(10*3600*time.Second))
+	if len(kept) != 0 || len(added) != 3 || added[0].name != "tickets-4" || added[0].validityStart != 1600036000-7200 {
+		t.Errorf("expected the expired keys to be replaced, got %d kept and %+v added", len(kept), added)
+	}
+}
+
+func TestTicketKeyGeneration(t *testing.T) {
+	cases := map[string]struct {
+		generation int
+		ok         bool
+	}{
+		"tickets-3":       {3, true},
+		"tickets-12":      {12, true},
+		"tickets-":        {0, false},
+		"tickets-next":    {0, false},
+		"other-3":         {0, false},
+		"tickets":         {0, false},
+		"tickets-extra-3": {0, false},
+	}
+	for name, expected := range cases {
+		generation, ok := ticketKeyGeneration("tickets", &managedTicketKey{name: name})
+		if generation != expected.generation || ok != expected.ok {
+			t.Errorf("%s: expected %d, %t, got %d, %t", name, expected.generation, expected.ok, generation, ok)
+		}
+	}
+}
+
+func TestSSLTicketKeyRotation(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	now := time.Unix(1600000000, 0)
+	defer func(clock func() time.Time) { ticketKeyClock = clock }(ticketKeyClock)
+	ticketKeyClock = func() time.Time { return now }
+	sslTicketKey := resourceSSLTicketKey()
+
+	d := schema.TestResourceDataRaw(t, sslTicketKey.Schema, map[string]interface{}{
+		"name":     "tickets",
+		"rotation": []interface{}{map[string]interface{}{"keys": 2, "lifetime": 600}},
+	})
+	err := sslTicketKey.Create(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"tickets-0", "tickets-1"} {
+		basic, ok := server.getResource("ssl/ticket_keys/" + name)["properties"].(map[string]interface{})
+		if !ok || len(basic["basic"].(map[string]interface{})["key"].(string)) != 32 {
+			t.Errorf("expected %s to be created, got %v", name, server.getResource("ssl/ticket_keys/"+name))
+		}
+	}
+	if d.Get("managed_keys.#").(int) != 2 || d.Get("managed_keys.1.name").(string) != "tickets-1" ||
+		d.Get("next_rotation").(int) != 1600000600 {
+		t.Errorf("unexpected state %v, next rotation %v", d.Get("managed_keys"), d.Get("next_rotation"))
+	}
+	if d.Get("key").(string) != "" {
+		t.Errorf("expected the key material to be kept out of state")
+	}
+
+	// Planning once the rotation is due changes next_rotation, so the keys are rotated when applying
+	state := &terraform.InstanceState{ID: "tickets", Attributes: map[string]string{
+		"name": "tickets", "next_rotation": "1600000600", "rotation.#": "1", "rotation.0.keys": "2", "rotation.0.lifetime": "600",
+	}}
+	config := testResourceConfig(t, map[string]interface{}{
+		"name":     "tickets",
+		"rotation": []interface{}{map[string]interface{}{"keys": 2, "lifetime": 600}},
+	})
+	provider := Provider().(*pulseVTMProvider)
+	diff, err := provider.Diff(&terraform.InstanceInfo{Type: "pulsevtm_ssl_ticket_key"}, state, config)
+	if err != nil || (diff != nil && diff.Attributes["next_rotation"] != nil) {
+		t.Errorf("expected no rotation to be planned yet, got %v: %v", diff, err)
+	}
+	now = now.Add(600 * time.Second)
+	diff, err = provider.Diff(&terraform.InstanceInfo{Type: "pulsevtm_ssl_ticket_key"}, state, config)
+	if err != nil || diff == nil || diff.Attributes["next_rotation"] == nil || !diff.Attributes["next_rotation"].NewComputed {
+		t.Errorf("expected a rotation to be planned, got %v: %v", diff, err)
+	}
+
+	err = sslTicketKey.Update(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.getResource("ssl/ticket_keys/tickets-0")) != 0 || len(server.getResource("ssl/ticket_keys/tickets-2")) == 0 {
+		t.Errorf("expected tickets-0 to be replaced by tickets-2")
+	}
+	if d.Get("managed_keys.1.name").(string) != "tickets-2" || d.Get("next_rotation").(int) != 1600001200 {
+		t.Errorf("unexpected state %v, next rotation %v", d.Get("managed_keys"), d.Get("next_rotation"))
+	}
+
+	// A key removed outside of Terraform is dropped from state when refreshing, without changing the traffic manager,
+	// and the rotation planned next generates a key in its place
+	server.mu.Lock()
+	delete(server.resources, "ssl/ticket_keys/tickets-1")
+	server.mu.Unlock()
+	err = sslTicketKey.Read(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("managed_keys.#").(int) != 1 || d.Get("managed_keys.0.name").(string) != "tickets-2" || d.Get("next_rotation").(int) != 0 {
+		t.Errorf("expected tickets-1 to be dropped and the rotation to be due, got %v, next rotation %v", d.Get("managed_keys"), d.Get("next_rotation"))
+	}
+	if len(server.getResource("ssl/ticket_keys/tickets-3")) != 0 {
+		t.Errorf("expected no key to be created when refreshing")
+	}
+	state.Attributes["next_rotation"] = strconv.Itoa(d.Get("next_rotation").(int))
+	diff, err = provider.Diff(&terraform.InstanceInfo{Type: "pulsevtm_ssl_ticket_key"}, state, config)
+	if err != nil || diff == nil || diff.Attributes["next_rotation"] == nil || !diff.Attributes["next_rotation"].NewComputed {
+		t.Errorf("expected a rotation to be planned for the missing key, got %v: %v", diff, err)
+	}
+	err = sslTicketKey.Update(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("managed_keys.#").(int) != 2 || d.Get("managed_keys.0.name").(string) != "tickets-3" ||
+		d.Get("managed_keys.1.name").(string) != "tickets-2" || d.Get("next_rotation").(int) != 1600001200 {
+		t.Errorf("expected tickets-1 to be replaced by tickets-3, got %v, next rotation %v", d.Get("managed_keys"), d.Get("next_rotation"))
+	}
+	if len(server.getResource("ssl/ticket_keys/tickets-3")) == 0 {
+		t.Errorf("expected tickets-3 to be created")
+	}
+
+	err = sslTicketKey.Delete(d, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.getResource("ssl/ticket_keys/tickets-2")) != 0 || len(server.getResource("ssl/ticket_keys/tickets-3")) != 0 {
+		t.Errorf("expected the managed keys to be deleted")
+	}
+}
+
+func TestSSLTicketKeyConfigCheck(t *testing.T) {
+	_, err := Provider().(*pulseVTMProvider).Diff(&terraform.InstanceInfo{Type: "pulsevtm_ssl_ticket_key"}, nil,
+		testResourceConfig(t, map[string]interface{}{"name": "tickets", "identifier": "00112233445566778899aabbccddeeff"}))
+	if err == nil || !strings.Contains(err.Error(), "needs key, validity_end, validity_start, or a rotation block") {
+		t.Errorf("expected an error planning a key missing attributes, got %v", err)
+	}
+
+	_, errs := validateTicketKeyCount(1, "rotation.0.keys")
+	if len(errs) != 1 {
+		t.Errorf("expected an error keeping a single key")
+	}
+}
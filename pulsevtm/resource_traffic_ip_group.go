@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"net/http"
 	"regexp"
@@ -247,7 +247,7 @@ func resourceTrafficIPGroupSet(d *schema.ResourceData, m interface{}) error {
 	trafficIPGroupRequest["properties"] = trafficIPGroupProperties
 	err := client.Set("traffic_ip_groups", name, trafficIPGroupRequest, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM Traffic IP Group error whilst creating/updating %s: %s", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Traffic IP Group error whilst creating/updating %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceTrafficIPGroupRead(d, m)
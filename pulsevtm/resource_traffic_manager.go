@@ -6,7 +6,7 @@ import (
 
 	"fmt"
 	"github.com/davecgh/go-spew/spew"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 	"log"
 	"net/http"
@@ -187,110 +187,29 @@ func resourceTrafficManager() *schema.Resource {
 						},
 						"hosts": {
 							Type:        schema.TypeList,
-							Description: "A table of hostname to static ip address mappings, to be placed in the /etc/ hosts file",
+							Description: "A table of hostname to static ip address mappings, to be placed in the /etc/ hosts file. When left out, the entries managed on their own are kept",
 							Optional:    true,
+							Computed:    true,
 							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"name": {
-										Type:        schema.TypeString,
-										Description: "The name of a host",
-										Required:    true,
-									},
-									"ip_address": {
-										Type:        schema.TypeString,
-										Description: "The static IP address of the host",
-										Required:    true,
-									},
-								},
+								Schema: applianceHostsSchema(),
 							},
 						},
 						"if": {
 							Type:        schema.TypeList,
-							Description: "A table of network interface specific settings",
+							Description: "A table of network interface specific settings. When left out, the entries managed on their own are kept",
 							Optional:    true,
+							Computed:    true,
 							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"name": {
-										Type:        schema.TypeString,
-										Description: "A network interface name",
-										Required:    true,
-									},
-									"autoneg": {
-										Type:        schema.TypeBool,
-										Description: "Whether auto-negotiation should be enabled for the interface",
-										Optional:    true,
-										Default:     true,
-									},
-									"bmode": {
-										Type:         schema.TypeString,
-										Description:  "The trunk of which the interface should be a member",
-										Optional:     true,
-										Default:      "802_3ad",
-										ValidateFunc: validation.StringInSlice([]string{"802_3ad"}, true),
-									},
-									"bond": {
-										Type:         schema.TypeString,
-										Description:  "The trunk of which the interface should be a member",
-										Optional:     true,
-										ValidateFunc: validateApplianceIFBond,
-									},
-									"duplex": {
-										Type:        schema.TypeBool,
-										Description: "Whether full-duplex should be enabled for the interface",
-										Optional:    true,
-										Default:     true,
-									},
-									"mode": {
-										Type:         schema.TypeString,
-										Description:  "Set the configuriation mode of an interface, the interface name is used in place of the * (asterisk).",
-										Optional:     true,
-										ValidateFunc: validation.StringInSlice([]string{"dhcp", "static"}, true),
-									},
-									"mtu": {
-										Type:         schema.TypeInt,
-										Description:  "The maximum transmission unit (MTU) of the interface",
-										Optional:     true,
-										Default:      1500,
-										ValidateFunc: util.ValidateUnsignedInteger,
-									},
-									"speed": {
-										Type:         schema.TypeString,
-										Description:  "The speed of the interface",
-										Optional:     true,
-										Default:      "1000",
-										ValidateFunc: validation.StringInSlice([]string{"10", "100", "1000"}, false),
-									},
-								},
+								Schema: applianceIfSchema(),
 							},
 						},
 						"ip": {
 							Type:        schema.TypeList,
-							Description: "A table of network interfaces and their network settings.",
+							Description: "A table of network interfaces and their network settings. When left out, the entries managed on their own are kept",
 							Optional:    true,
+							Computed:    true,
 							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"name": {
-										Type:        schema.TypeString,
-										Description: "A network interface name",
-										Required:    true,
-									},
-									"addr": {
-										Type:        schema.TypeString,
-										Description: "The IP address for the interface",
-										Required:    true,
-									},
-									"isexternal": {
-										Type:        schema.TypeBool,
-										Description: "Whether the interface is externally facing",
-										Optional:    true,
-										Default:     false,
-									},
-									"mask": {
-										Type:        schema.TypeString,
-										Description: "The IP mask (netmask) for the interface",
-										Required:    true,
-									},
-								},
+								Schema: applianceIPSchema(),
 							},
 						},
 						"ipmi_lan_access": {
@@ -402,31 +321,11 @@ func resourceTrafficManager() *schema.Resource {
 						},
 						"routes": {
 							Type:        schema.TypeList,
-							Description: "A table of destination IP addresses and routing details to reach them.",
+							Description: "A table of destination IP addresses and routing details to reach them. When left out, the entries managed on their own are kept",
 							Optional:    true,
+							Computed:    true,
 							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"name": {
-										Type:        schema.TypeString,
-										Description: "A destination IP address",
-										Required:    true,
-									},
-									"gw": {
-										Type:        schema.TypeString,
-										Description: "The gateway IP to configure for the route",
-										Required:    true,
-									},
-									"if": {
-										Type:        schema.TypeString,
-										Description: "The network interface to configure for the route",
-										Required:    true,
-									},
-									"mask": {
-										Type:        schema.TypeString,
-										Description: "The netmask to apply to the IP address",
-										Required:    true,
-									},
-								},
+								Schema: applianceRoutesSchema(),
 							},
 						},
 						"search_domains": {
@@ -737,6 +636,131 @@ func resourceTrafficManager() *schema.Resource {
 	}
 }
 
+// applianceHostsSchema : returns the schema of an entry in the appliance hosts table
+func applianceHostsSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of a host",
+			Required:    true,
+		},
+		"ip_address": {
+			Type:        schema.TypeString,
+			Description: "The static IP address of the host",
+			Required:    true,
+		},
+	}
+}
+
+// applianceIfSchema : returns the schema of an entry in the appliance if table
+func applianceIfSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "A network interface name",
+			Required:    true,
+		},
+		"autoneg": {
+			Type:        schema.TypeBool,
+			Description: "Whether auto-negotiation should be enabled for the interface",
+			Optional:    true,
+			Default:     true,
+		},
+		"bmode": {
+			Type:         schema.TypeString,
+			Description:  "The trunk of which the interface should be a member",
+			Optional:     true,
+			Default:      "802_3ad",
+			ValidateFunc: validation.StringInSlice([]string{"802_3ad"}, true),
+		},
+		"bond": {
+			Type:         schema.TypeString,
+			Description:  "The trunk of which the interface should be a member",
+			Optional:     true,
+			ValidateFunc: validateApplianceIFBond,
+		},
+		"duplex": {
+			Type:        schema.TypeBool,
+			Description: "Whether full-duplex should be enabled for the interface",
+			Optional:    true,
+			Default:     true,
+		},
+		"mode": {
+			Type:         schema.TypeString,
+			Description:  "Set the configuriation mode of an interface, the interface name is used in place of the * (asterisk).",
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"dhcp", "static"}, true),
+		},
+		"mtu": {
+			Type:         schema.TypeInt,
+			Description:  "The maximum transmission unit (MTU) of the interface",
+			Optional:     true,
+			Default:      1500,
+			ValidateFunc: util.ValidateUnsignedInteger,
+		},
+		"speed": {
+			Type:         schema.TypeString,
+			Description:  "The speed of the interface",
+			Optional:     true,
+			Default:      "1000",
+			ValidateFunc: validation.StringInSlice([]string{"10", "100", "1000"}, false),
+		},
+	}
+}
+
+// applianceIPSchema : returns the schema of an entry in the appliance ip table
+func applianceIPSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "A network interface name",
+			Required:    true,
+		},
+		"addr": {
+			Type:        schema.TypeString,
+			Description: "The IP address for the interface",
+			Required:    true,
+		},
+		"isexternal": {
+			Type:        schema.TypeBool,
+			Description: "Whether the interface is externally facing",
+			Optional:    true,
+			Default:     false,
+		},
+		"mask": {
+			Type:        schema.TypeString,
+			Description: "The IP mask (netmask) for the interface",
+			Required:    true,
+		},
+	}
+}
+
+// applianceRoutesSchema : returns the schema of an entry in the appliance routes table
+func applianceRoutesSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "A destination IP address",
+			Required:    true,
+		},
+		"gw": {
+			Type:        schema.TypeString,
+			Description: "The gateway IP to configure for the route",
+			Required:    true,
+		},
+		"if": {
+			Type:        schema.TypeString,
+			Description: "The network interface to configure for the route",
+			Required:    true,
+		},
+		"mask": {
+			Type:        schema.TypeString,
+			Description: "The netmask to apply to the IP address",
+			Required:    true,
+		},
+	}
+}
+
 func validateApplianceCardLabel(v interface{}, k string) (ws []string, errors []error) {
 	r := regexp.MustCompile(`^[\w.:@\-]{1,64}$`)
 	if r.MatchString(v.(string)) {
@@ -804,6 +828,18 @@ func resourceTrafficManagerSet(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
+	// The appliance tables are only sent when they've changed, as those left out of the configuration hold the
+	// entries of the resources managing them one at a time
+	if appliance, ok := trafficManagerPropertiesConfiguration["appliance"].(map[string]interface{}); ok {
+		for _, table := range []string{"hosts", "if", "ip", "routes"} {
+			if !d.HasChange("appliance.0." + table) {
+				delete(appliance, table)
+			}
+		}
+		trafficManagerApplianceMutexKV.Lock(name)
+		defer trafficManagerApplianceMutexKV.Unlock(name)
+	}
+
 	for _, attribute := range []string{"appliance_card", "appliance_sysctl", "trafficip", "num_children", "location", "nameip", "admin_master_xmlip", "admin_slave_xmlip", "authentication_server_ip", "num_aptimizer_threads", "number_of_cpus", "rest_server_port", "updater_ip"} {
 		if d.HasChange(attribute) {
 			trafficManagerBasicConfiguration[getTrafficManagerAttributeName(attribute)] = d.Get(attribute)
@@ -815,7 +851,7 @@ func resourceTrafficManagerSet(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("traffic_managers", name, &trafficManagerConfiguration, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst creating Traffic Manager %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst creating Traffic Manager %s: %v", name, err)
 	}
 	d.SetId(name)
 
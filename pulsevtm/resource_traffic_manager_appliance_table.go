@@ -0,0 +1,195 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/mutexkv"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+	"net/http"
+	"strings"
+)
+
+// trafficManagerApplianceMutexKV serialises changes made to the appliance tables of the same traffic manager by this
+// provider
+var trafficManagerApplianceMutexKV = mutexkv.NewMutexKV()
+
+// trafficManagerApplianceTableSchema : returns the schema of a resource which manages a single entry of a traffic manager
+// appliance table, built from copies of the attributes of the table's entries so the entry schema is left untouched
+func trafficManagerApplianceTableSchema(entrySchema map[string]*schema.Schema) map[string]*schema.Schema {
+	resourceSchema := make(map[string]*schema.Schema)
+	for key, value := range entrySchema {
+		attribute := *value
+		resourceSchema[key] = &attribute
+	}
+	resourceSchema["name"].ForceNew = true
+	resourceSchema["traffic_manager"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Name of the traffic manager the entry belongs to",
+		Required:    true,
+		ForceNew:    true,
+	}
+	return resourceSchema
+}
+
+// trafficManagerApplianceTableEntryID : builds the ID of a traffic manager appliance table entry
+func trafficManagerApplianceTableEntryID(trafficManager, name string) string {
+	return trafficManager + "/" + name
+}
+
+// getTrafficManagerApplianceTable : retrieves the current content of an appliance table from a traffic manager
+func getTrafficManagerApplianceTable(client *api.Client, trafficManager, table string) ([]interface{}, error) {
+	trafficManagerConfiguration := make(map[string]interface{})
+	client.WorkWithConfigurationResources()
+	err := client.GetByName("traffic_managers", trafficManager, &trafficManagerConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	trafficManagerPropertiesConfig := trafficManagerConfiguration["properties"].(map[string]interface{})
+	applianceSection, ok := trafficManagerPropertiesConfig["appliance"].(map[string]interface{})
+	if !ok {
+		return make([]interface{}, 0), nil
+	}
+	entries, ok := applianceSection[table].([]interface{})
+	if !ok {
+		return make([]interface{}, 0), nil
+	}
+	return entries, nil
+}
+
+// setTrafficManagerApplianceTable : replaces an appliance table of a traffic manager leaving the rest of its configuration untouched
+func setTrafficManagerApplianceTable(client *api.Client, trafficManager, table string, entries []interface{}) error {
+	trafficManagerConfiguration := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"appliance": map[string]interface{}{
+				table: entries,
+			},
+		},
+	}
+	return client.Set("traffic_managers", trafficManager, &trafficManagerConfiguration, nil)
+}
+
+// trafficManagerApplianceTableEntrySet : adds or replaces a single entry of a traffic manager appliance table
+func trafficManagerApplianceTableEntrySet(d *schema.ResourceData, m interface{}, table string, entrySchema map[string]*schema.Schema) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	trafficManager := d.Get("traffic_manager").(string)
+	name := d.Get("name").(string)
+
+	entry := make(map[string]interface{})
+	for key := range entrySchema {
+		entry[key] = d.Get(key)
+	}
+
+	trafficManagerApplianceMutexKV.Lock(trafficManager)
+	defer trafficManagerApplianceMutexKV.Unlock(trafficManager)
+
+	entries, err := getTrafficManagerApplianceTable(client, trafficManager, table)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst retrieving Traffic Manager %s: %v", trafficManager, err)
+	}
+
+	updatedEntries := make([]interface{}, 0)
+	entryReplaced := false
+	for _, item := range entries {
+		if item.(map[string]interface{})["name"] == name {
+			updatedEntries = append(updatedEntries, entry)
+			entryReplaced = true
+			continue
+		}
+		updatedEntries = append(updatedEntries, item)
+	}
+	if !entryReplaced {
+		updatedEntries = append(updatedEntries, entry)
+	}
+
+	err = setTrafficManagerApplianceTable(client, trafficManager, table, updatedEntries)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst setting %s entry %s on Traffic Manager %s: %v", table, name, trafficManager, err)
+	}
+	d.SetId(trafficManagerApplianceTableEntryID(trafficManager, name))
+
+	return trafficManagerApplianceTableEntryRead(d, m, table, entrySchema)
+}
+
+// trafficManagerApplianceTableEntryRead : reads a single entry of a traffic manager appliance table
+func trafficManagerApplianceTableEntryRead(d *schema.ResourceData, m interface{}, table string, entrySchema map[string]*schema.Schema) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	idParts := strings.SplitN(d.Id(), "/", 2)
+	if len(idParts) != 2 {
+		return fmt.Errorf("[ERROR] PulseVTM %s entry ID %s is not in the format <traffic_manager>/<name>", table, d.Id())
+	}
+	trafficManager, name := idParts[0], idParts[1]
+
+	entries, err := getTrafficManagerApplianceTable(client, trafficManager, table)
+	if client.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst retrieving Traffic Manager %s: %v", trafficManager, err)
+	}
+
+	for _, item := range entries {
+		entry := item.(map[string]interface{})
+		if entry["name"] != name {
+			continue
+		}
+		err := d.Set("traffic_manager", trafficManager)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst setting %s entry attribute traffic_manager: %v", table, err)
+		}
+		for key := range entrySchema {
+			if value, ok := entry[key]; ok {
+				err := d.Set(key, value)
+				if err != nil {
+					return fmt.Errorf("[ERROR] PulseVTM error whilst setting %s entry attribute %s: %v", table, key, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// trafficManagerApplianceTableEntryDelete : removes a single entry from a traffic manager appliance table
+func trafficManagerApplianceTableEntryDelete(d *schema.ResourceData, m interface{}, table string) error {
+	config := m.(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	trafficManager := d.Get("traffic_manager").(string)
+	name := d.Get("name").(string)
+
+	trafficManagerApplianceMutexKV.Lock(trafficManager)
+	defer trafficManagerApplianceMutexKV.Unlock(trafficManager)
+
+	entries, err := getTrafficManagerApplianceTable(client, trafficManager, table)
+	if client.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst retrieving Traffic Manager %s: %v", trafficManager, err)
+	}
+
+	remainingEntries := make([]interface{}, 0)
+	for _, item := range entries {
+		if item.(map[string]interface{})["name"] != name {
+			remainingEntries = append(remainingEntries, item)
+		}
+	}
+
+	if len(remainingEntries) != len(entries) {
+		err = setTrafficManagerApplianceTable(client, trafficManager, table, remainingEntries)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst deleting %s entry %s from Traffic Manager %s: %v", table, name, trafficManager, err)
+		}
+	}
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,118 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestTrafficManagerApplianceTableEntriesSetConcurrently(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	server.setResource("traffic_managers/tm1", map[string]interface{}{"properties": map[string]interface{}{
+		"appliance": map[string]interface{}{"hosts": []interface{}{}},
+	}})
+	// The test server merges each PUT into the traffic manager, so an entry is only kept when each write saw the
+	// entries written before it
+	hosts := resourceTrafficManagerHost()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, hosts.Schema, map[string]interface{}{
+				"traffic_manager": "tm1",
+				"name":            fmt.Sprintf("host%d.example.com", i),
+				"ip_address":      fmt.Sprintf("10.0.0.%d", i),
+			})
+			errs <- hosts.Create(d, m)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries := server.getResource("traffic_managers/tm1")["properties"].(map[string]interface{})["appliance"].(map[string]interface{})["hosts"].([]interface{})
+	if len(entries) != 10 {
+		t.Errorf("expected every entry to be kept, got %d: %v", len(entries), entries)
+	}
+}
+
+func TestTrafficManagerLeavesUnconfiguredApplianceTables(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	m := server.meta(t)
+	trafficManagerConfiguration := func(hosts ...string) map[string]interface{} {
+		properties := map[string]interface{}{"basic": map[string]interface{}{}}
+		for _, section := range []string{"cluster_comms", "ec2", "fault_tolerance", "iptables", "iptrans", "java", "remote_licensing", "rest_api", "snmp"} {
+			properties[section] = map[string]interface{}{}
+		}
+		hostsTable := make([]interface{}, 0)
+		for i, host := range hosts {
+			hostsTable = append(hostsTable, map[string]interface{}{"name": host, "ip_address": fmt.Sprintf("10.0.0.%d", i+50)})
+		}
+		properties["appliance"] = map[string]interface{}{"hosts": hostsTable, "gateway_ipv4": "10.0.0.1"}
+		return map[string]interface{}{"properties": properties}
+	}
+	server.setResource("traffic_managers/tm1", trafficManagerConfiguration("backend.example.com"))
+	trafficManager := resourceTrafficManager()
+
+	// The hosts table is left out of the configuration, as its entries are managed on their own
+	config := map[string]interface{}{"name": "tm1", "appliance": []interface{}{map[string]interface{}{"gateway_ipv4": "10.0.0.1"}}}
+	diff, err := trafficManager.Diff(nil, testResourceConfig(t, config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err := trafficManager.Apply(nil, diff, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Attributes["appliance.0.hosts.#"] != "1" {
+		t.Fatalf("expected the hosts table to be read, got %v", state.Attributes)
+	}
+
+	diff, err = trafficManager.Diff(state, testResourceConfig(t, config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Errorf("expected no changes planned for the entries managed on their own, got %v", diff.Attributes)
+	}
+
+	// Meanwhile another entry is added on its own, and is kept when the traffic manager is changed
+	server.setResource("traffic_managers/tm1", trafficManagerConfiguration("backend.example.com", "frontend.example.com"))
+	config["appliance"] = []interface{}{map[string]interface{}{"gateway_ipv4": "10.0.0.254"}}
+	diff, err = trafficManager.Diff(state, testResourceConfig(t, config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = trafficManager.Apply(state, diff, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	appliance := server.getResource("traffic_managers/tm1")["properties"].(map[string]interface{})["appliance"].(map[string]interface{})
+	if appliance["gateway_ipv4"] != "10.0.0.254" || len(appliance["hosts"].([]interface{})) != 2 {
+		t.Errorf("expected the gateway to be changed and the hosts table left as it was, got %v", appliance)
+	}
+}
+
+func TestTrafficManagerApplianceTableSchemaLeavesEntrySchema(t *testing.T) {
+	entrySchema := applianceHostsSchema()
+	resourceSchema := trafficManagerApplianceTableSchema(entrySchema)
+
+	if !resourceSchema["name"].ForceNew || resourceSchema["traffic_manager"] == nil {
+		t.Errorf("expected the entry to be replaced when its name or traffic manager changes, got %v", resourceSchema)
+	}
+	if entrySchema["name"].ForceNew || entrySchema["traffic_manager"] != nil {
+		t.Errorf("expected the schema of the table's entries to be left untouched, got %v", entrySchema)
+	}
+}
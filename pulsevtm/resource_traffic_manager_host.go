@@ -0,0 +1,31 @@
+package pulsevtm
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceTrafficManagerHost() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceTrafficManagerHostSet,
+		Read:   resourceTrafficManagerHostRead,
+		Update: resourceTrafficManagerHostSet,
+		Delete: resourceTrafficManagerHostDelete,
+
+		Schema: trafficManagerApplianceTableSchema(applianceHostsSchema()),
+	}
+}
+
+// resourceTrafficManagerHostSet - Creates or updates a single static host mapping of a traffic manager
+func resourceTrafficManagerHostSet(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntrySet(d, m, "hosts", applianceHostsSchema())
+}
+
+// resourceTrafficManagerHostRead - Reads a single static host mapping of a traffic manager
+func resourceTrafficManagerHostRead(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryRead(d, m, "hosts", applianceHostsSchema())
+}
+
+// resourceTrafficManagerHostDelete - Removes a single static host mapping from a traffic manager
+func resourceTrafficManagerHostDelete(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryDelete(d, m, "hosts")
+}
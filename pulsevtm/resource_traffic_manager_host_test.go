@@ -0,0 +1,134 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func TestAccPulseVTMTrafficManagerHostBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+
+	trafficManagerName := fmt.Sprintf("acctest_pulsevtm_traffic_manager_host-%d", randomInt)
+	resourceName := "pulsevtm_traffic_manager_host.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMTrafficManagerHostCheckDestroy(state, "backend.example.com")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPulseVTMTrafficManagerHostNoTrafficManager(),
+				ExpectError: regexp.MustCompile(`required field is not set`),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerHostCreate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerHostExists(trafficManagerName, "backend.example.com"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "backend.example.com"),
+					resource.TestCheckResourceAttr(resourceName, "ip_address", "10.0.0.50"),
+				),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerHostUpdate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerHostExists(trafficManagerName, "backend.example.com"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "backend.example.com"),
+					resource.TestCheckResourceAttr(resourceName, "ip_address", "10.0.0.51"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPulseVTMTrafficManagerHostCheckDestroy(state *terraform.State, name string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_traffic_manager_host" {
+			continue
+		}
+		entries, err := getTrafficManagerApplianceTable(client, rs.Primary.Attributes["traffic_manager"], "hosts")
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: %+v", err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: hosts entry %s still exists", name)
+			}
+		}
+	}
+	return nil
+}
+
+func testAccPulseVTMTrafficManagerHostExists(trafficManagerName, name string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+
+		entries, err := getTrafficManagerApplianceTable(client, trafficManagerName, "hosts")
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving Traffic Manager %s: %+v", trafficManagerName, err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("[ERROR] Pulse vTM hosts entry %s not found on Traffic Manager %s", name, trafficManagerName)
+	}
+}
+
+func testAccPulseVTMTrafficManagerHostNoTrafficManager() string {
+	return `
+resource "pulsevtm_traffic_manager_host" "acctest" {
+	name = "backend.example.com"
+	ip_address = "10.0.0.50"
+}
+`
+}
+
+func testAccPulseVTMTrafficManagerHostCreate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_host" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "backend.example.com"
+	ip_address = "10.0.0.50"
+}
+`, trafficManagerName)
+}
+
+func testAccPulseVTMTrafficManagerHostUpdate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_host" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "backend.example.com"
+	ip_address = "10.0.0.51"
+}
+`, trafficManagerName)
+}
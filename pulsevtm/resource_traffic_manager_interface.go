@@ -0,0 +1,31 @@
+package pulsevtm
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceTrafficManagerInterface() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceTrafficManagerInterfaceSet,
+		Read:   resourceTrafficManagerInterfaceRead,
+		Update: resourceTrafficManagerInterfaceSet,
+		Delete: resourceTrafficManagerInterfaceDelete,
+
+		Schema: trafficManagerApplianceTableSchema(applianceIfSchema()),
+	}
+}
+
+// resourceTrafficManagerInterfaceSet - Creates or updates the settings of a single network interface of a traffic manager
+func resourceTrafficManagerInterfaceSet(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntrySet(d, m, "if", applianceIfSchema())
+}
+
+// resourceTrafficManagerInterfaceRead - Reads the settings of a single network interface of a traffic manager
+func resourceTrafficManagerInterfaceRead(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryRead(d, m, "if", applianceIfSchema())
+}
+
+// resourceTrafficManagerInterfaceDelete - Removes the settings of a single network interface from a traffic manager
+func resourceTrafficManagerInterfaceDelete(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryDelete(d, m, "if")
+}
@@ -0,0 +1,144 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func TestAccPulseVTMTrafficManagerInterfaceBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+
+	trafficManagerName := fmt.Sprintf("acctest_pulsevtm_traffic_manager_interface-%d", randomInt)
+	resourceName := "pulsevtm_traffic_manager_interface.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMTrafficManagerInterfaceCheckDestroy(state, "eth1")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPulseVTMTrafficManagerInterfaceNoTrafficManager(),
+				ExpectError: regexp.MustCompile(`required field is not set`),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerInterfaceCreate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerInterfaceExists(trafficManagerName, "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "autoneg", "true"),
+					resource.TestCheckResourceAttr(resourceName, "mtu", "1500"),
+					resource.TestCheckResourceAttr(resourceName, "speed", "1000"),
+				),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerInterfaceUpdate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerInterfaceExists(trafficManagerName, "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "autoneg", "false"),
+					resource.TestCheckResourceAttr(resourceName, "mtu", "9000"),
+					resource.TestCheckResourceAttr(resourceName, "speed", "100"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPulseVTMTrafficManagerInterfaceCheckDestroy(state *terraform.State, name string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_traffic_manager_interface" {
+			continue
+		}
+		entries, err := getTrafficManagerApplianceTable(client, rs.Primary.Attributes["traffic_manager"], "if")
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: %+v", err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: if entry %s still exists", name)
+			}
+		}
+	}
+	return nil
+}
+
+func testAccPulseVTMTrafficManagerInterfaceExists(trafficManagerName, name string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+
+		entries, err := getTrafficManagerApplianceTable(client, trafficManagerName, "if")
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving Traffic Manager %s: %+v", trafficManagerName, err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("[ERROR] Pulse vTM if entry %s not found on Traffic Manager %s", name, trafficManagerName)
+	}
+}
+
+func testAccPulseVTMTrafficManagerInterfaceNoTrafficManager() string {
+	return `
+resource "pulsevtm_traffic_manager_interface" "acctest" {
+	name = "eth1"
+	autoneg = true
+	mtu = 1500
+	speed = "1000"
+}
+`
+}
+
+func testAccPulseVTMTrafficManagerInterfaceCreate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_interface" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "eth1"
+	autoneg = true
+	mtu = 1500
+	speed = "1000"
+}
+`, trafficManagerName)
+}
+
+func testAccPulseVTMTrafficManagerInterfaceUpdate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_interface" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "eth1"
+	autoneg = false
+	mtu = 9000
+	speed = "100"
+}
+`, trafficManagerName)
+}
@@ -0,0 +1,31 @@
+package pulsevtm
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceTrafficManagerIP() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceTrafficManagerIPSet,
+		Read:   resourceTrafficManagerIPRead,
+		Update: resourceTrafficManagerIPSet,
+		Delete: resourceTrafficManagerIPDelete,
+
+		Schema: trafficManagerApplianceTableSchema(applianceIPSchema()),
+	}
+}
+
+// resourceTrafficManagerIPSet - Creates or updates the network settings of a single interface of a traffic manager
+func resourceTrafficManagerIPSet(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntrySet(d, m, "ip", applianceIPSchema())
+}
+
+// resourceTrafficManagerIPRead - Reads the network settings of a single interface of a traffic manager
+func resourceTrafficManagerIPRead(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryRead(d, m, "ip", applianceIPSchema())
+}
+
+// resourceTrafficManagerIPDelete - Removes the network settings of a single interface from a traffic manager
+func resourceTrafficManagerIPDelete(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryDelete(d, m, "ip")
+}
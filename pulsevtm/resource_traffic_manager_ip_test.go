@@ -0,0 +1,144 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func TestAccPulseVTMTrafficManagerIPBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+
+	trafficManagerName := fmt.Sprintf("acctest_pulsevtm_traffic_manager_ip-%d", randomInt)
+	resourceName := "pulsevtm_traffic_manager_ip.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMTrafficManagerIPCheckDestroy(state, "eth1")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPulseVTMTrafficManagerIPNoTrafficManager(),
+				ExpectError: regexp.MustCompile(`required field is not set`),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerIPCreate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerIPExists(trafficManagerName, "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "addr", "10.0.0.10"),
+					resource.TestCheckResourceAttr(resourceName, "mask", "255.255.255.0"),
+					resource.TestCheckResourceAttr(resourceName, "isexternal", "false"),
+				),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerIPUpdate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerIPExists(trafficManagerName, "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "addr", "10.0.0.20"),
+					resource.TestCheckResourceAttr(resourceName, "mask", "255.255.0.0"),
+					resource.TestCheckResourceAttr(resourceName, "isexternal", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPulseVTMTrafficManagerIPCheckDestroy(state *terraform.State, name string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_traffic_manager_ip" {
+			continue
+		}
+		entries, err := getTrafficManagerApplianceTable(client, rs.Primary.Attributes["traffic_manager"], "ip")
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: %+v", err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: ip entry %s still exists", name)
+			}
+		}
+	}
+	return nil
+}
+
+func testAccPulseVTMTrafficManagerIPExists(trafficManagerName, name string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+
+		entries, err := getTrafficManagerApplianceTable(client, trafficManagerName, "ip")
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving Traffic Manager %s: %+v", trafficManagerName, err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("[ERROR] Pulse vTM ip entry %s not found on Traffic Manager %s", name, trafficManagerName)
+	}
+}
+
+func testAccPulseVTMTrafficManagerIPNoTrafficManager() string {
+	return `
+resource "pulsevtm_traffic_manager_ip" "acctest" {
+	name = "eth1"
+	addr = "10.0.0.10"
+	mask = "255.255.255.0"
+	isexternal = false
+}
+`
+}
+
+func testAccPulseVTMTrafficManagerIPCreate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_ip" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "eth1"
+	addr = "10.0.0.10"
+	mask = "255.255.255.0"
+	isexternal = false
+}
+`, trafficManagerName)
+}
+
+func testAccPulseVTMTrafficManagerIPUpdate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_ip" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "eth1"
+	addr = "10.0.0.20"
+	mask = "255.255.0.0"
+	isexternal = true
+}
+`, trafficManagerName)
+}
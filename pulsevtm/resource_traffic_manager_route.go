@@ -0,0 +1,31 @@
+package pulsevtm
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceTrafficManagerRoute() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceTrafficManagerRouteSet,
+		Read:   resourceTrafficManagerRouteRead,
+		Update: resourceTrafficManagerRouteSet,
+		Delete: resourceTrafficManagerRouteDelete,
+
+		Schema: trafficManagerApplianceTableSchema(applianceRoutesSchema()),
+	}
+}
+
+// resourceTrafficManagerRouteSet - Creates or updates a single static route of a traffic manager
+func resourceTrafficManagerRouteSet(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntrySet(d, m, "routes", applianceRoutesSchema())
+}
+
+// resourceTrafficManagerRouteRead - Reads a single static route of a traffic manager
+func resourceTrafficManagerRouteRead(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryRead(d, m, "routes", applianceRoutesSchema())
+}
+
+// resourceTrafficManagerRouteDelete - Removes a single static route from a traffic manager
+func resourceTrafficManagerRouteDelete(d *schema.ResourceData, m interface{}) error {
+	return trafficManagerApplianceTableEntryDelete(d, m, "routes")
+}
@@ -0,0 +1,144 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+func TestAccPulseVTMTrafficManagerRouteBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+
+	trafficManagerName := fmt.Sprintf("acctest_pulsevtm_traffic_manager_route-%d", randomInt)
+	resourceName := "pulsevtm_traffic_manager_route.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccPulseVTMTrafficManagerRouteCheckDestroy(state, "192.168.10.0")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPulseVTMTrafficManagerRouteNoTrafficManager(),
+				ExpectError: regexp.MustCompile(`required field is not set`),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerRouteCreate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerRouteExists(trafficManagerName, "192.168.10.0"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "192.168.10.0"),
+					resource.TestCheckResourceAttr(resourceName, "gw", "10.0.0.1"),
+					resource.TestCheckResourceAttr(resourceName, "if", "eth0"),
+					resource.TestCheckResourceAttr(resourceName, "mask", "255.255.255.0"),
+				),
+			},
+			{
+				Config: testAccPulseVTMTrafficManagerRouteUpdate(trafficManagerName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccPulseVTMTrafficManagerRouteExists(trafficManagerName, "192.168.10.0"),
+					resource.TestCheckResourceAttr(resourceName, "traffic_manager", trafficManagerName),
+					resource.TestCheckResourceAttr(resourceName, "name", "192.168.10.0"),
+					resource.TestCheckResourceAttr(resourceName, "gw", "10.0.0.254"),
+					resource.TestCheckResourceAttr(resourceName, "if", "eth1"),
+					resource.TestCheckResourceAttr(resourceName, "mask", "255.255.0.0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPulseVTMTrafficManagerRouteCheckDestroy(state *terraform.State, name string) error {
+	config := testAccProvider.Meta().(map[string]interface{})
+	client := config["jsonClient"].(*api.Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "pulsevtm_traffic_manager_route" {
+			continue
+		}
+		entries, err := getTrafficManagerApplianceTable(client, rs.Primary.Attributes["traffic_manager"], "routes")
+		if client.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: %+v", err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return fmt.Errorf("[ERROR] Pulse vTM Check Destroy Error: routes entry %s still exists", name)
+			}
+		}
+	}
+	return nil
+}
+
+func testAccPulseVTMTrafficManagerRouteExists(trafficManagerName, name string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		config := testAccProvider.Meta().(map[string]interface{})
+		client := config["jsonClient"].(*api.Client)
+
+		entries, err := getTrafficManagerApplianceTable(client, trafficManagerName, "routes")
+		if err != nil {
+			return fmt.Errorf("[ERROR] Pulse vTM error whilst retrieving Traffic Manager %s: %+v", trafficManagerName, err)
+		}
+		for _, entry := range entries {
+			if entry.(map[string]interface{})["name"] == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("[ERROR] Pulse vTM routes entry %s not found on Traffic Manager %s", name, trafficManagerName)
+	}
+}
+
+func testAccPulseVTMTrafficManagerRouteNoTrafficManager() string {
+	return `
+resource "pulsevtm_traffic_manager_route" "acctest" {
+	name = "192.168.10.0"
+	gw = "10.0.0.1"
+	if = "eth0"
+	mask = "255.255.255.0"
+}
+`
+}
+
+func testAccPulseVTMTrafficManagerRouteCreate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_route" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "192.168.10.0"
+	gw = "10.0.0.1"
+	if = "eth0"
+	mask = "255.255.255.0"
+}
+`, trafficManagerName)
+}
+
+func testAccPulseVTMTrafficManagerRouteUpdate(trafficManagerName string) string {
+	return fmt.Sprintf(`
+resource "pulsevtm_traffic_manager" "acctest" {
+	name = "%s"
+}
+
+resource "pulsevtm_traffic_manager_route" "acctest" {
+	traffic_manager = "${pulsevtm_traffic_manager.acctest.name}"
+	name = "192.168.10.0"
+	gw = "10.0.0.254"
+	if = "eth1"
+	mask = "255.255.0.0"
+}
+`, trafficManagerName)
+}
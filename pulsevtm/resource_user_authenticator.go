@@ -7,7 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
@@ -268,7 +268,7 @@ func resourceUserAuthenticatorSet(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("user_authenticators", name, res, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM error whilst creating user authenticator %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM error whilst creating user authenticator %s: %v", name, err)
 	}
 	d.SetId(name)
 	return resourceUserAuthenticatorRead(d, m)
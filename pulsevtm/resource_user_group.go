@@ -7,7 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 )
 
 func resourceUserGroup() *schema.Resource {
@@ -91,7 +91,7 @@ func resourceUserGroupCreate(d *schema.ResourceData, m interface{}) error {
 
 	err := client.Set("user_groups", name, &res, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM User Group error whilst creating %s: %v", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM User Group error whilst creating %s: %v", name, err)
 	}
 
 	d.SetId(name)
@@ -153,7 +153,7 @@ func resourceUserGroupUpdate(d *schema.ResourceData, m interface{}) error {
 	client := config["jsonClient"].(*api.Client)
 	err := client.Set("user_groups", d.Id(), res, nil)
 	if err != nil {
-		return fmt.Errorf("[ERROR] PulseVTM User Group error whilst updating %s: %v", d.Id(), err)
+		return wrapErrorf(err, "[ERROR] PulseVTM User Group error whilst updating %s: %v", d.Id(), err)
 	}
 	return resourceUserGroupRead(d, m)
 }
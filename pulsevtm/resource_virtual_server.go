@@ -7,7 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
@@ -18,6 +18,9 @@ func resourceVirtualServer() *schema.Resource {
 		Update: resourceVirtualServerSet,
 		Delete: resourceVirtualServerDelete,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVirtualServerMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -37,7 +40,7 @@ func resourceVirtualServer() *schema.Resource {
 				Default:     false,
 			},
 			"completion_rules": {
-				Type:        schema.TypeSet,
+				Type:        schema.TypeList,
 				Description: "Rules that are run at the end of a transaction, in order, comma separated.",
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
@@ -124,13 +127,13 @@ func resourceVirtualServer() *schema.Resource {
 				Default:     false,
 			},
 			"request_rules": {
-				Type:        schema.TypeSet,
+				Type:        schema.TypeList,
 				Description: "Rules to be applied to incoming requests, in order, comma separated.",
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
 			"response_rules": {
-				Type:        schema.TypeSet,
+				Type:        schema.TypeList,
 				Description: "Rules to be applied to responses, in order, comma separated.",
 				Optional:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
@@ -152,6 +155,7 @@ func resourceVirtualServer() *schema.Resource {
 				Optional:    true,
 				Default:     false,
 			},
+			"wait_for_healthy": waitForHealthySchema("When set, the apply waits for the default pool of the virtual server to have a minimum number of healthy nodes and fails if that doesn't happen within the timeout"),
 
 			"aptimizer": {
 				Type:     schema.TypeList,
@@ -1526,23 +1530,31 @@ func resourceVirtualServerSet(d *schema.ResourceData, m interface{}) error {
 	res := make(map[string]interface{})
 	pros := make(map[string]interface{})
 
-	util.GetSection(d, "basic", pros, basicVirtualServerKeys())
+	util.GetSection(d, "basic", pros, supportedAttributes(m, "pulsevtm_virtual_server", basicVirtualServerKeys()))
 
-	for _, section := range virtualServerSectionNames() {
+	for _, section := range supportedAttributes(m, "pulsevtm_virtual_server", virtualServerSectionNames()) {
 		if d.HasChange(section) {
 			pros[sectionName(section)] = d.Get(section).([]interface{})[0]
 		}
 	}
 
+	toAPIVersionProperties(m, "pulsevtm_virtual_server", pros)
 	res["properties"] = pros
 	util.TraverseMapTypes(res)
 	err := client.Set("virtual_servers", name, res, nil)
 	if err != nil {
 		log.Println("[ERROR] ", client.RootPath)
-		return fmt.Errorf("[ERROR] PulseVTM Virtual Server error whilst creating/updating %s: %s", name, err)
+		return wrapErrorf(err, "[ERROR] PulseVTM Virtual Server error whilst creating/updating %s: %v", name, err)
 	}
 	d.SetId(name)
 
+	if pool, ok := d.GetOk("pool"); ok {
+		err = waitForHealthyPool(client, config["statusClient"].(*api.Client), pool.(string), d)
+		if err != nil {
+			return err
+		}
+	}
+
 	return resourceVirtualServerRead(d, m)
 }
 
@@ -1572,9 +1584,10 @@ func resourceVirtualServerRead(d *schema.ResourceData, m interface{}) error {
 	}
 
 	props := res["properties"].(map[string]interface{})
+	fromAPIVersionProperties(m, "pulsevtm_virtual_server", props)
 	basic := props["basic"].(map[string]interface{})
 
-	for _, key := range basicVirtualServerKeys() {
+	for _, key := range supportedAttributes(m, "pulsevtm_virtual_server", basicVirtualServerKeys()) {
 		err := d.Set(key, basic[key])
 		if err != nil {
 			log.Println("[ERROR] Basic section setting failed: ", err)
@@ -1582,7 +1595,7 @@ func resourceVirtualServerRead(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
-	for _, section := range virtualServerSectionNames() {
+	for _, section := range supportedAttributes(m, "pulsevtm_virtual_server", virtualServerSectionNames()) {
 		set := make([]map[string]interface{}, 0)
 		reorderedSection := util.ReorderTablesInSection(props, tables(), sectionName(section), d)
 		set = append(set, reorderedSection)
@@ -0,0 +1,20 @@
+package pulsevtm
+
+import (
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
+)
+
+// resourceVirtualServerMigrateState : upgrades virtual server state stored with an earlier schema version
+var resourceVirtualServerMigrateState = util.MigrateStateFunc("PulseVTM Virtual Server",
+	migrateVirtualServerStateV0toV1,
+)
+
+// migrateVirtualServerStateV0toV1 : the rule attributes were sets in v0, keyed by the hash of each rule name, and are
+// ordered lists from v1
+func migrateVirtualServerStateV0toV1(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	for _, attribute := range []string{"completion_rules", "request_rules", "response_rules"} {
+		util.MigrateSetToList(is, attribute)
+	}
+	return is, nil
+}
@@ -0,0 +1,90 @@
+package pulsevtm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestVirtualServerMigrateStateV0toV1(t *testing.T) {
+	cases := map[string]struct {
+		Attributes map[string]string
+		Expected   map[string]string
+	}{
+		"rules as sets": {
+			Attributes: map[string]string{
+				"name":                        "vs1",
+				"request_rules.#":             "2",
+				"request_rules.2716145458":    "ruleTwo",
+				"request_rules.1034452165":    "ruleOne",
+				"response_rules.#":            "1",
+				"response_rules.4103456789":   "ruleFour",
+				"completion_rules.#":          "0",
+				"listen_on_traffic_ips.#":     "1",
+				"listen_on_traffic_ips.12345": "ip1",
+			},
+			Expected: map[string]string{
+				"name":                        "vs1",
+				"request_rules.#":             "2",
+				"request_rules.0":             "ruleOne",
+				"request_rules.1":             "ruleTwo",
+				"response_rules.#":            "1",
+				"response_rules.0":            "ruleFour",
+				"completion_rules.#":          "0",
+				"listen_on_traffic_ips.#":     "1",
+				"listen_on_traffic_ips.12345": "ip1",
+			},
+		},
+		"rules in the order of their hashes": {
+			Attributes: map[string]string{
+				"name":                     "vs1",
+				"request_rules.#":          "3",
+				"request_rules.3518293064": "audit",
+				"request_rules.1203761924": "redirect",
+				"request_rules.2946003152": "block",
+			},
+			Expected: map[string]string{
+				"name":            "vs1",
+				"request_rules.#": "3",
+				"request_rules.0": "redirect",
+				"request_rules.1": "block",
+				"request_rules.2": "audit",
+			},
+		},
+		"no rules": {
+			Attributes: map[string]string{
+				"name": "vs1",
+				"port": "80",
+			},
+			Expected: map[string]string{
+				"name": "vs1",
+				"port": "80",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		is := &terraform.InstanceState{
+			ID:         "vs1",
+			Attributes: tc.Attributes,
+		}
+		is, err := resourceVirtualServerMigrateState(0, is, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(is.Attributes, tc.Expected) {
+			t.Errorf("%s: expected attributes %#v, got %#v", name, tc.Expected, is.Attributes)
+		}
+	}
+}
+
+func TestVirtualServerMigrateStateEmpty(t *testing.T) {
+	is, err := resourceVirtualServerMigrateState(0, &terraform.InstanceState{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !is.Empty() {
+		t.Errorf("expected an empty state, got %#v", is)
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/util"
 )
 
@@ -41,8 +41,8 @@ func TestAccPulseVTMVirtualServerBasic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "bandwidth_class", "test"),
 					resource.TestCheckResourceAttr(resourceName, "bypass_data_plane_acceleration", "true"),
 					resource.TestCheckResourceAttr(resourceName, "completion_rules.#", "2"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("completion_rules"), "completionRule1"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("completion_rules"), "completionRule2"),
+					resource.TestCheckResourceAttr(resourceName, "completion_rules.0", "completionRule1"),
+					resource.TestCheckResourceAttr(resourceName, "completion_rules.1", "completionRule2"),
 					resource.TestCheckResourceAttr(resourceName, "connect_timeout", "50"),
 					resource.TestCheckResourceAttr(resourceName, "enabled", "true"),
 					resource.TestCheckResourceAttr(resourceName, "glb_services.#", "2"),
@@ -63,11 +63,11 @@ func TestAccPulseVTMVirtualServerBasic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "protocol", "dns"),
 					resource.TestCheckResourceAttr(resourceName, "proxy_protocol", "true"),
 					resource.TestCheckResourceAttr(resourceName, "request_rules.#", "2"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("request_rules"), "ruleOne"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("request_rules"), "ruleTwo"),
+					resource.TestCheckResourceAttr(resourceName, "request_rules.0", "ruleOne"),
+					resource.TestCheckResourceAttr(resourceName, "request_rules.1", "ruleTwo"),
 					resource.TestCheckResourceAttr(resourceName, "response_rules.#", "2"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("response_rules"), "ruleOne"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("response_rules"), "ruleTwo"),
+					resource.TestCheckResourceAttr(resourceName, "response_rules.0", "ruleTwo"),
+					resource.TestCheckResourceAttr(resourceName, "response_rules.1", "ruleOne"),
 					resource.TestCheckResourceAttr(resourceName, "slm_class", "testClass"),
 					resource.TestCheckResourceAttr(resourceName, "ssl_decrypt", "true"),
 					resource.TestCheckResourceAttr(resourceName, "transparent", "true"),
@@ -278,8 +278,8 @@ func TestAccPulseVTMVirtualServerBasic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "bandwidth_class", "testUpdate"),
 					resource.TestCheckResourceAttr(resourceName, "bypass_data_plane_acceleration", "false"),
 					resource.TestCheckResourceAttr(resourceName, "completion_rules.#", "2"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("completion_rules"), "completionRule2"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("completion_rules"), "completionRule3"),
+					resource.TestCheckResourceAttr(resourceName, "completion_rules.0", "completionRule3"),
+					resource.TestCheckResourceAttr(resourceName, "completion_rules.1", "completionRule2"),
 					resource.TestCheckResourceAttr(resourceName, "connect_timeout", "100"),
 					resource.TestCheckResourceAttr(resourceName, "enabled", "false"),
 					resource.TestCheckResourceAttr(resourceName, "glb_services.#", "2"),
@@ -299,9 +299,9 @@ func TestAccPulseVTMVirtualServerBasic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "protocol", "ftp"),
 					resource.TestCheckResourceAttr(resourceName, "proxy_protocol", "false"),
 					resource.TestCheckResourceAttr(resourceName, "request_rules.#", "1"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("request_rules"), "ruleThree"),
+					resource.TestCheckResourceAttr(resourceName, "request_rules.0", "ruleThree"),
 					resource.TestCheckResourceAttr(resourceName, "response_rules.#", "1"),
-					util.AccTestCheckValueInKeyPattern(resourceName, util.AccTestCreateRegexPatternForSet("response_rules"), "ruleFour"),
+					resource.TestCheckResourceAttr(resourceName, "response_rules.0", "ruleFour"),
 					resource.TestCheckResourceAttr(resourceName, "slm_class", "testClassUpdate"),
 					resource.TestCheckResourceAttr(resourceName, "ssl_decrypt", "false"),
 					resource.TestCheckResourceAttr(resourceName, "transparent", "false"),
@@ -578,7 +578,7 @@ resource "pulsevtm_virtual_server" "acctest" {
 	protocol = "dns"
 	proxy_protocol = true
 	request_rules = ["ruleOne","ruleTwo"]
-	response_rules = ["ruleOne","ruleTwo"]
+	response_rules = ["ruleTwo","ruleOne"]
 	slm_class = "testClass"
 	ssl_decrypt = true
 	transparent = true
@@ -852,7 +852,7 @@ resource "pulsevtm_virtual_server" "acctest" {
 	name = "%s"
 	bandwidth_class = "testUpdate"
 	bypass_data_plane_acceleration = false
-	completion_rules = ["completionRule2","completionRule3"]
+	completion_rules = ["completionRule3","completionRule2"]
 	connect_timeout = 100
 	enabled = false
 	glb_services = ["testservice3","testservice4"]
@@ -0,0 +1,96 @@
+package pulsevtm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// readStateFixture : returns the primary instance state of a resource held in a captured state file under testdata
+func readStateFixture(t *testing.T, fileName, resourceName string) *terraform.InstanceState {
+	file, err := os.Open(filepath.Join("testdata", "state_migrations", fileName))
+	if err != nil {
+		t.Fatalf("unable to open state fixture %s: %v", fileName, err)
+	}
+	defer file.Close()
+
+	state, err := terraform.ReadState(file)
+	if err != nil {
+		t.Fatalf("unable to read state fixture %s: %v", fileName, err)
+	}
+	resourceState, ok := state.RootModule().Resources[resourceName]
+	if !ok {
+		t.Fatalf("state fixture %s doesn't hold resource %s", fileName, resourceName)
+	}
+	return resourceState.Primary
+}
+
+// stateMigrationFixtures : the captured state of each resource with a schema version above 0, from before its first
+// migration, and the state it's expected to be migrated to
+var stateMigrationFixtures = []struct {
+	ResourceType string
+	ResourceName string
+	Fixture      string
+	Expected     string
+}{
+	{"pulsevtm_virtual_server", "pulsevtm_virtual_server.web", "virtual_server_v0.tfstate", "virtual_server_v1.tfstate"},
+}
+
+func TestStateMigrationFixtures(t *testing.T) {
+	resources := Provider().(*pulseVTMProvider).ResourcesMap
+	for _, tc := range stateMigrationFixtures {
+		resource := resources[tc.ResourceType]
+		is := readStateFixture(t, tc.Fixture, tc.ResourceName)
+		expected := readStateFixture(t, tc.Expected, tc.ResourceName)
+
+		expectedVersion, _ := strconv.Atoi(expected.Meta["schema_version"].(string))
+		if resource.SchemaVersion != expectedVersion {
+			t.Errorf("%s: expected schema version %d, got %d", tc.ResourceType, expectedVersion, resource.SchemaVersion)
+		}
+
+		is, err := resource.MigrateState(0, is, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.ResourceType, err)
+		}
+		if !reflect.DeepEqual(is.Attributes, expected.Attributes) {
+			t.Errorf("%s: expected attributes %#v, got %#v", tc.ResourceType, expected.Attributes, is.Attributes)
+		}
+		for key := range is.Attributes {
+			attribute := strings.SplitN(key, ".", 2)[0]
+			if _, ok := resource.Schema[attribute]; !ok && attribute != "id" {
+				t.Errorf("%s: migrated attribute %s isn't in the current schema", tc.ResourceType, key)
+			}
+		}
+	}
+}
+
+func TestStateMigrationFixturesCoverVersionedResources(t *testing.T) {
+	fixtures := make(map[string]bool)
+	for _, tc := range stateMigrationFixtures {
+		fixtures[tc.ResourceType] = true
+	}
+	for resourceType, resource := range Provider().(*pulseVTMProvider).ResourcesMap {
+		if resource.SchemaVersion == 0 {
+			continue
+		}
+		if resource.MigrateState == nil {
+			t.Errorf("%s: schema version %d has no state migration", resourceType, resource.SchemaVersion)
+		}
+		if !fixtures[resourceType] {
+			t.Errorf("%s: schema version %d has no captured state fixture", resourceType, resource.SchemaVersion)
+		}
+	}
+}
+
+func TestStateMigrationOfUnknownVersion(t *testing.T) {
+	resource := resourceVirtualServer()
+	_, err := resource.MigrateState(resource.SchemaVersion, &terraform.InstanceState{ID: "web"}, nil)
+	if err == nil {
+		t.Errorf("expected an error migrating from the current schema version")
+	}
+}
@@ -0,0 +1,554 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxZoneTTL : the largest TTL a record may have, as TTLs are 31-bit values
+const maxZoneTTL = 2147483647
+
+// zoneTTLPattern : matches a TTL, as seconds or in the BIND format of numbers with units such as 1h30m
+var zoneTTLPattern = regexp.MustCompile(`^([0-9]+[sSmMhHdDwW]?)+$`)
+
+// zoneTypePattern : matches a record type, such as A or TYPE65
+var zoneTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// zoneClasses : the classes a record may be given
+var zoneClasses = map[string]bool{"IN": true, "CH": true, "CS": true, "HS": true}
+
+// zoneToken : a word or quoted string of a zone file, and where it lies in the text it was read from
+type zoneToken struct {
+	text       string
+	start, end int
+}
+
+// zoneLine : a line of a zone file, which spans several lines of text when parenthesised
+type zoneLine struct {
+	tokens []zoneToken
+	// inherit is set when the line starts with whitespace, so a record on it has the owner of the one before
+	inherit    bool
+	line       int
+	start, end int
+}
+
+// ZoneRecord : a resource record of a zone file
+type ZoneRecord struct {
+	// Owner is the name of the record, made absolute by the origin in effect unless the zone has no origin
+	Owner string
+	// TTL is the TTL given to the record, when ExplicitTTL is set
+	TTL         int
+	ExplicitTTL bool
+	Class       string
+	Type        string
+	Data        []string
+	Line        int
+}
+
+// zoneEntry : a line of a zone file, holding a record, a directive or only whitespace and comments
+type zoneEntry struct {
+	text   string
+	record *ZoneRecord
+	// inherit is set when the record has the owner of the one before rather than one of its own
+	inherit bool
+}
+
+// Zone : a zone file in the BIND format, kept as written so changes to its records leave the rest of it untouched
+type Zone struct {
+	entries []*zoneEntry
+	// origin is the origin in effect at the end of the zone, which records added are relative to
+	origin string
+	// DefaultTTL is the TTL set by the last $TTL directive, and zero without one
+	DefaultTTL int
+}
+
+// zoneError : an error found on a line of a zone file
+func zoneError(line int, format string, a ...interface{}) error {
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, a...))
+}
+
+// tokenizeZone : splits a zone file into its lines and their words, dropping comments and joining parenthesised lines
+func tokenizeZone(text string) ([]*zoneLine, error) {
+	lines := make([]*zoneLine, 0)
+	line := 1
+	depth, depthLine := 0, 0
+	current := &zoneLine{line: line}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\n':
+			line++
+			if depth == 0 {
+				current.end = i + 1
+				lines = append(lines, current)
+				current = &zoneLine{line: line, start: i + 1}
+			}
+		case c == ';':
+			for i+1 < len(text) && text[i+1] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			if i == current.start {
+				current.inherit = true
+			}
+		case c == '(':
+			if depth == 0 {
+				depthLine = line
+			}
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return nil, zoneError(line, "unbalanced parenthesis")
+			}
+			depth--
+		case c == '"':
+			start := i
+			for i++; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+				if i < len(text) && text[i] == '\n' {
+					return nil, zoneError(line, "unterminated quoted string")
+				}
+			}
+			if i >= len(text) {
+				return nil, zoneError(line, "unterminated quoted string")
+			}
+			current.tokens = append(current.tokens, zoneToken{text: text[start : i+1], start: start, end: i + 1})
+		default:
+			start := i
+			for ; i < len(text) && !strings.ContainsRune(" \t\r\n;()\"", rune(text[i])); i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+			if i > len(text) {
+				i = len(text)
+			}
+			current.tokens = append(current.tokens, zoneToken{text: text[start:i], start: start, end: i})
+			i--
+		}
+	}
+	if depth > 0 {
+		return nil, zoneError(depthLine, "parenthesis is never closed")
+	}
+	if current.start < len(text) {
+		current.end = len(text)
+		lines = append(lines, current)
+	}
+	return lines, nil
+}
+
+// ParseZoneTTL : parses a TTL given in seconds or in the BIND format, such as 1h30m
+func ParseZoneTTL(ttl string) (int, error) {
+	if !zoneTTLPattern.MatchString(ttl) {
+		return 0, fmt.Errorf("%q is not a valid TTL", ttl)
+	}
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	seconds, number := 0, 0
+	for i := 0; i < len(ttl); i++ {
+		c := ttl[i]
+		if c >= '0' && c <= '9' {
+			number = number*10 + int(c-'0')
+		} else {
+			seconds += number * units[c|0x20]
+			number = 0
+		}
+		if number > maxZoneTTL || seconds > maxZoneTTL {
+			return 0, fmt.Errorf("%q is longer than the largest TTL of %d seconds", ttl, maxZoneTTL)
+		}
+	}
+	seconds += number
+	if seconds > maxZoneTTL {
+		return 0, fmt.Errorf("%q is longer than the largest TTL of %d seconds", ttl, maxZoneTTL)
+	}
+	return seconds, nil
+}
+
+// ZoneAbsoluteName : returns a name of a zone made absolute by an origin, unless it already is or there's no origin
+func ZoneAbsoluteName(name, origin string) string {
+	switch {
+	case name == "@":
+		if origin == "" {
+			return name
+		}
+		return origin
+	case strings.HasSuffix(name, ".") || origin == "":
+		return name
+	case origin == ".":
+		return name + "."
+	}
+	return name + "." + origin
+}
+
+// zoneNameEqual : whether two names of a zone are the same, as names are compared regardless of case
+func zoneNameEqual(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// ParseZone : parses a zone file in the BIND format, with origin the origin in effect until a $ORIGIN directive,
+// which may be empty when the zone file is used with a zone's origin. Errors give the line they were found on.
+func ParseZone(text, origin string) (*Zone, error) {
+	lines, err := tokenizeZone(text)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := &Zone{entries: make([]*zoneEntry, 0), origin: origin}
+	owner := ""
+	for _, line := range lines {
+		entry := &zoneEntry{text: text[line.start:line.end]}
+		zone.entries = append(zone.entries, entry)
+		if len(line.tokens) == 0 {
+			continue
+		}
+
+		tokens := line.tokens
+		if !line.inherit && strings.HasPrefix(tokens[0].text, "$") {
+			err := zone.parseDirective(line)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		record := &ZoneRecord{Line: line.line}
+		if line.inherit {
+			if owner == "" {
+				return nil, zoneError(line.line, "record has no owner, as it starts with whitespace and follows no other record")
+			}
+			record.Owner = owner
+			entry.inherit = true
+		} else {
+			record.Owner = ZoneAbsoluteName(tokens[0].text, zone.origin)
+			tokens = tokens[1:]
+		}
+		owner = record.Owner
+
+		for len(tokens) > 0 && (zoneTTLPattern.MatchString(tokens[0].text) || zoneClasses[strings.ToUpper(tokens[0].text)]) {
+			if zoneClasses[strings.ToUpper(tokens[0].text)] {
+				if record.Class != "" {
+					return nil, zoneError(line.line, "record is given a class twice")
+				}
+				record.Class = strings.ToUpper(tokens[0].text)
+			} else {
+				if record.ExplicitTTL {
+					return nil, zoneError(line.line, "record is given a TTL twice")
+				}
+				record.TTL, err = ParseZoneTTL(tokens[0].text)
+				if err != nil {
+					return nil, zoneError(line.line, "%v", err)
+				}
+				record.ExplicitTTL = true
+			}
+			tokens = tokens[1:]
+		}
+		if len(tokens) == 0 {
+			return nil, zoneError(line.line, "record for %s has no type", record.Owner)
+		}
+		if !zoneTypePattern.MatchString(tokens[0].text) {
+			return nil, zoneError(line.line, "record for %s has an invalid type %q", record.Owner, tokens[0].text)
+		}
+		record.Type = strings.ToUpper(tokens[0].text)
+		record.Data = make([]string, 0)
+		for _, token := range tokens[1:] {
+			record.Data = append(record.Data, token.text)
+		}
+		if len(record.Data) == 0 {
+			return nil, zoneError(line.line, "%s record for %s has no data", record.Type, record.Owner)
+		}
+		entry.record = record
+	}
+	return zone, nil
+}
+
+// parseDirective : applies a $ORIGIN or $TTL directive to the zone as it's parsed
+func (zone *Zone) parseDirective(line *zoneLine) error {
+	directive := strings.ToUpper(line.tokens[0].text)
+	switch directive {
+	case "$ORIGIN":
+		if len(line.tokens) != 2 {
+			return zoneError(line.line, "$ORIGIN takes a single domain name")
+		}
+		if err := checkZoneName("domain name", line.tokens[1].text); err != nil || line.tokens[1].text == "@" {
+			return zoneError(line.line, "$ORIGIN %s is not a valid domain name", line.tokens[1].text)
+		}
+		zone.origin = ZoneAbsoluteName(line.tokens[1].text, zone.origin)
+	case "$TTL":
+		if len(line.tokens) != 2 {
+			return zoneError(line.line, "$TTL takes a single TTL")
+		}
+		ttl, err := ParseZoneTTL(line.tokens[1].text)
+		if err != nil {
+			return zoneError(line.line, "%v", err)
+		}
+		zone.DefaultTTL = ttl
+	case "$INCLUDE", "$GENERATE":
+		return zoneError(line.line, "%s is not supported, as a zone file can't refer to other files", directive)
+	default:
+		return zoneError(line.line, "unknown directive %s", line.tokens[0].text)
+	}
+	return nil
+}
+
+// String : returns the zone file in the BIND format. Lines which haven't been changed are as they were written.
+func (zone *Zone) String() string {
+	var text bytes.Buffer
+	owner := ""
+	for _, entry := range zone.entries {
+		// A record which had the owner of one since removed or changed is given its owner explicitly
+		if entry.record != nil && entry.inherit && !zoneNameEqual(owner, entry.record.Owner) {
+			entry.text = formatZoneRecord(entry.record)
+			entry.inherit = false
+		}
+		text.WriteString(entry.text)
+		if entry.record != nil {
+			owner = entry.record.Owner
+		}
+	}
+	return text.String()
+}
+
+// Origin : returns the origin in effect at the end of the zone, which the names of records added are relative to
+func (zone *Zone) Origin() string {
+	return zone.origin
+}
+
+// Records : returns the records of the zone in the order they're written
+func (zone *Zone) Records() []*ZoneRecord {
+	records := make([]*ZoneRecord, 0)
+	for _, entry := range zone.entries {
+		if entry.record != nil {
+			records = append(records, entry.record)
+		}
+	}
+	return records
+}
+
+// ParseZoneRecordData : splits the data of a record into its words and quoted strings, as it's written in a zone file
+func ParseZoneRecordData(data string) ([]string, error) {
+	lines, err := tokenizeZone(" " + strings.Replace(data, "\n", " ", -1))
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, 0)
+	for _, line := range lines {
+		for _, token := range line.tokens {
+			words = append(words, token.text)
+		}
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no data is given")
+	}
+	return words, nil
+}
+
+// FindRecord : returns the record of the zone with a name, type and data, where the name is made absolute by the
+// origin at the end of the zone. Differences in case and in the whitespace of the data are ignored.
+func (zone *Zone) FindRecord(name, recordType string, data []string) *ZoneRecord {
+	owner := ZoneAbsoluteName(name, zone.origin)
+	for _, record := range zone.Records() {
+		if zoneNameEqual(record.Owner, owner) && strings.EqualFold(record.Type, recordType) &&
+			strings.Join(record.Data, " ") == strings.Join(data, " ") {
+			return record
+		}
+	}
+	return nil
+}
+
+// formatZoneRecord : returns a record as a line of a zone file
+func formatZoneRecord(record *ZoneRecord) string {
+	fields := []string{record.Owner}
+	if record.ExplicitTTL {
+		fields = append(fields, strconv.Itoa(record.TTL))
+	}
+	if record.Class != "" {
+		fields = append(fields, record.Class)
+	}
+	fields = append(fields, record.Type, strings.Join(record.Data, " "))
+	return strings.Join(fields, "\t") + "\n"
+}
+
+// AddRecord : adds a record to the end of the zone, with its name relative to the origin at the end of the zone. A
+// TTL of zero leaves the record with the zone's default TTL.
+func (zone *Zone) AddRecord(name string, ttl int, recordType string, data []string) *ZoneRecord {
+	record := &ZoneRecord{
+		Owner:       ZoneAbsoluteName(name, zone.origin),
+		TTL:         ttl,
+		ExplicitTTL: ttl > 0,
+		Type:        strings.ToUpper(recordType),
+		Data:        data,
+	}
+	if n := len(zone.entries); n > 0 && !strings.HasSuffix(zone.entries[n-1].text, "\n") {
+		zone.entries[n-1].text += "\n"
+	}
+	zone.entries = append(zone.entries, &zoneEntry{text: formatZoneRecord(record), record: record})
+	return record
+}
+
+// SetRecordTTL : changes the TTL of a record of the zone, where a TTL of zero leaves it with the zone's default TTL
+func (zone *Zone) SetRecordTTL(record *ZoneRecord, ttl int) {
+	for _, entry := range zone.entries {
+		if entry.record == record {
+			record.TTL, record.ExplicitTTL = ttl, ttl > 0
+			entry.text = formatZoneRecord(record)
+			entry.inherit = false
+		}
+	}
+}
+
+// RemoveRecord : removes a record from the zone
+func (zone *Zone) RemoveRecord(record *ZoneRecord) {
+	entries := make([]*zoneEntry, 0)
+	for _, entry := range zone.entries {
+		if entry.record != record {
+			entries = append(entries, entry)
+		}
+	}
+	zone.entries = entries
+}
+
+// NextZoneSerial : returns the serial following one. Serials in the YYYYMMDDnn date format move on to today's date
+// once it's later, and all others are incremented, wrapping around as serials are compared modulo 2^32.
+func NextZoneSerial(serial uint32, now time.Time) uint32 {
+	next := serial + 1
+	today, _ := strconv.ParseUint(now.UTC().Format("20060102")+"00", 10, 32)
+	if serial >= 1970010100 && serial < 2100000000 && uint32(today) > next {
+		return uint32(today)
+	}
+	return next
+}
+
+// soaEntry : returns the entry of the SOA record of the zone, or nil when it has none
+func (zone *Zone) soaEntry() (*zoneEntry, error) {
+	for _, entry := range zone.entries {
+		if entry.record == nil || entry.record.Type != "SOA" {
+			continue
+		}
+		if len(entry.record.Data) != 7 {
+			return nil, zoneError(entry.record.Line, "SOA record has %d fields rather than 7", len(entry.record.Data))
+		}
+		return entry, nil
+	}
+	return nil, nil
+}
+
+// serialToken : returns where the serial of an SOA record lies in the text of its entry. The serial is the third word
+// of the data, which follows the owner, TTL, class and type.
+func (entry *zoneEntry) serialToken() (zoneToken, error) {
+	lines, err := tokenizeZone(entry.text)
+	if err != nil || len(lines) == 0 {
+		return zoneToken{}, zoneError(entry.record.Line, "SOA record can't be read")
+	}
+	tokens := lines[0].tokens
+	return tokens[len(tokens)-len(entry.record.Data)+2], nil
+}
+
+// Serial : returns the serial of the SOA record of the zone, or false when the zone has no SOA record
+func (zone *Zone) Serial() (uint32, bool, error) {
+	entry, err := zone.soaEntry()
+	if entry == nil || err != nil {
+		return 0, false, err
+	}
+	serial, err := strconv.ParseUint(entry.record.Data[2], 10, 32)
+	if err != nil {
+		return 0, false, zoneError(entry.record.Line, "SOA record has an invalid serial %q", entry.record.Data[2])
+	}
+	return uint32(serial), true, nil
+}
+
+// SetSerial : sets the serial of the SOA record of the zone. Only the serial is rewritten, leaving the formatting of
+// the record as it was.
+func (zone *Zone) SetSerial(serial uint32) error {
+	entry, err := zone.soaEntry()
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("the zone has no SOA record")
+	}
+	token, err := entry.serialToken()
+	if err != nil {
+		return err
+	}
+	entry.record.Data[2] = strconv.FormatUint(uint64(serial), 10)
+	entry.text = entry.text[:token.start] + entry.record.Data[2] + entry.text[token.end:]
+	return nil
+}
+
+// BumpSerial : moves the serial of the SOA record of the zone on, for secondaries to know the zone has changed.
+// Returns the new serial, or false when the zone has no SOA record.
+func (zone *Zone) BumpSerial(now time.Time) (uint32, bool, error) {
+	serial, ok, err := zone.Serial()
+	if !ok || err != nil {
+		return 0, false, err
+	}
+	next := NextZoneSerial(serial, now)
+	return next, true, zone.SetSerial(next)
+}
+
+// ValidateZoneFile : check a zone file is in the BIND format, with records of known types and valid data, giving the
+// line of each error
+func ValidateZoneFile(v interface{}, k string) (ws []string, errors []error) {
+	zone, err := ParseZone(v.(string), "")
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid zone file: %v", k, err))
+		return
+	}
+	warnings, checkErrors := zone.Check()
+	for _, warning := range warnings {
+		ws = append(ws, fmt.Sprintf("[WARN] %q %s", k, warning))
+	}
+	for _, err := range checkErrors {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid zone file: %v", k, err))
+	}
+	return
+}
+
+// ValidateZoneRecordType : check a record type is one the traffic manager serves, or is given by its number
+func ValidateZoneRecordType(v interface{}, k string) (ws []string, errors []error) {
+	if !zoneTypePattern.MatchString(v.(string)) || !knownZoneRecordType(v.(string)) {
+		errors = append(errors, fmt.Errorf("[ERROR] %q has an unknown record type %s", k, v.(string)))
+	}
+	return
+}
+
+// zoneWithoutSerial : returns a zone file with the serial of its SOA record removed, or false if it can't be parsed
+func zoneWithoutSerial(text string) (string, bool) {
+	zone, err := ParseZone(text, "")
+	if err != nil {
+		return "", false
+	}
+	entry, err := zone.soaEntry()
+	if err != nil {
+		return "", false
+	}
+	if entry != nil {
+		token, err := entry.serialToken()
+		if err != nil {
+			return "", false
+		}
+		entry.text = entry.text[:token.start] + entry.text[token.end:]
+	}
+	return zone.String(), true
+}
+
+// SuppressZoneSerialDiff : suppresses the diff of a zone file differing only in the serial of its SOA record, as the
+// serial is bumped when the zone file is changed
+func SuppressZoneSerialDiff(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	oldZone, ok := zoneWithoutSerial(old)
+	if !ok {
+		return false
+	}
+	newZone, ok := zoneWithoutSerial(new)
+	return ok && oldZone == newZone
+}
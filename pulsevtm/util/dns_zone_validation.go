@@ -0,0 +1,330 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// zoneGenericTypePattern : matches a record type given by its number, such as TYPE65
+var zoneGenericTypePattern = regexp.MustCompile(`^TYPE[0-9]+$`)
+
+// zoneHexPattern : matches hexadecimal digits, as the data of a record given by its type number is written with
+var zoneHexPattern = regexp.MustCompile(`^[0-9A-Fa-f]*$`)
+
+// zoneCAATagPattern : matches the tag of a CAA record, such as issue
+var zoneCAATagPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// zoneRecordDataChecks : checks of the data of each record type the traffic manager serves, given the data's words
+var zoneRecordDataChecks = map[string]func([]string) error{
+	"A":     checkZoneAData,
+	"AAAA":  checkZoneAAAAData,
+	"CAA":   checkZoneCAAData,
+	"CNAME": checkZoneNameData,
+	"DNAME": checkZoneNameData,
+	"HINFO": checkZoneHINFOData,
+	"MX":    checkZoneMXData,
+	"NAPTR": checkZoneNAPTRData,
+	"NS":    checkZoneNameData,
+	"PTR":   checkZoneNameData,
+	"SOA":   checkZoneSOAData,
+	"SPF":   checkZoneTXTData,
+	"SRV":   checkZoneSRVData,
+	"TXT":   checkZoneTXTData,
+}
+
+// checkZoneDataCount : checks a record has as many words of data as its type has fields
+func checkZoneDataCount(data []string, fields ...string) error {
+	if len(data) != len(fields) {
+		return fmt.Errorf("has %d fields of data rather than %d: %s", len(data), len(fields), strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// checkZoneName : checks a domain name in a zone file has no empty labels, and that neither it nor its labels are
+// too long
+func checkZoneName(field, name string) error {
+	if name == "@" || name == "." {
+		return nil
+	}
+	if strings.HasPrefix(name, `"`) {
+		return fmt.Errorf("has a quoted string for its %s rather than a domain name", field)
+	}
+	if len(strings.TrimSuffix(name, ".")) > 253 {
+		return fmt.Errorf("has a %s %q longer than 253 characters", field, name)
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			return fmt.Errorf("has a %s %q with an empty label", field, name)
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("has a %s %q with a label longer than 63 characters", field, name)
+		}
+	}
+	return nil
+}
+
+// checkZoneNumber : checks a field of a record is an unsigned number of a number of bits
+func checkZoneNumber(field, value string, bits int) error {
+	_, err := strconv.ParseUint(value, 10, bits)
+	if err != nil {
+		return fmt.Errorf("has a %s %q which isn't a number from 0 to %d", field, value, uint64(1)<<uint(bits)-1)
+	}
+	return nil
+}
+
+// checkZoneString : checks a field of a record is a character string, which is at most 255 characters long
+func checkZoneString(field, value string) error {
+	if len(strings.Trim(value, `"`)) > 255 {
+		return fmt.Errorf("has a %s longer than 255 characters", field)
+	}
+	return nil
+}
+
+func checkZoneAData(data []string) error {
+	if err := checkZoneDataCount(data, "address"); err != nil {
+		return err
+	}
+	if ip := net.ParseIP(data[0]); ip == nil || ip.To4() == nil || strings.Contains(data[0], ":") {
+		return fmt.Errorf("has %q rather than an IPv4 address", data[0])
+	}
+	return nil
+}
+
+func checkZoneAAAAData(data []string) error {
+	if err := checkZoneDataCount(data, "address"); err != nil {
+		return err
+	}
+	if ip := net.ParseIP(data[0]); ip == nil || !strings.Contains(data[0], ":") {
+		return fmt.Errorf("has %q rather than an IPv6 address", data[0])
+	}
+	return nil
+}
+
+func checkZoneCAAData(data []string) error {
+	if err := checkZoneDataCount(data, "flags", "tag", "value"); err != nil {
+		return err
+	}
+	if err := checkZoneNumber("flags", data[0], 8); err != nil {
+		return err
+	}
+	if !zoneCAATagPattern.MatchString(data[1]) {
+		return fmt.Errorf("has a tag %q which isn't made of letters and digits", data[1])
+	}
+	return checkZoneString("value", data[2])
+}
+
+func checkZoneNameData(data []string) error {
+	if err := checkZoneDataCount(data, "target"); err != nil {
+		return err
+	}
+	return checkZoneName("target", data[0])
+}
+
+func checkZoneHINFOData(data []string) error {
+	if err := checkZoneDataCount(data, "cpu", "os"); err != nil {
+		return err
+	}
+	if err := checkZoneString("cpu", data[0]); err != nil {
+		return err
+	}
+	return checkZoneString("os", data[1])
+}
+
+func checkZoneMXData(data []string) error {
+	if err := checkZoneDataCount(data, "preference", "exchange"); err != nil {
+		return err
+	}
+	if err := checkZoneNumber("preference", data[0], 16); err != nil {
+		return err
+	}
+	return checkZoneName("exchange", data[1])
+}
+
+func checkZoneNAPTRData(data []string) error {
+	if err := checkZoneDataCount(data, "order", "preference", "flags", "services", "regexp", "replacement"); err != nil {
+		return err
+	}
+	for i, field := range []string{"order", "preference"} {
+		if err := checkZoneNumber(field, data[i], 16); err != nil {
+			return err
+		}
+	}
+	for i, field := range []string{"flags", "services", "regexp"} {
+		if err := checkZoneString(field, data[i+2]); err != nil {
+			return err
+		}
+	}
+	return checkZoneName("replacement", data[5])
+}
+
+func checkZoneSOAData(data []string) error {
+	if err := checkZoneDataCount(data, "mname", "rname", "serial", "refresh", "retry", "expire", "minimum"); err != nil {
+		return err
+	}
+	for i, field := range []string{"mname", "rname"} {
+		if err := checkZoneName(field, data[i]); err != nil {
+			return err
+		}
+	}
+	if err := checkZoneNumber("serial", data[2], 32); err != nil {
+		return err
+	}
+	for i, field := range []string{"refresh", "retry", "expire", "minimum"} {
+		if _, err := ParseZoneTTL(data[i+3]); err != nil {
+			return fmt.Errorf("has a %s which isn't valid: %v", field, err)
+		}
+	}
+	return nil
+}
+
+func checkZoneSRVData(data []string) error {
+	if err := checkZoneDataCount(data, "priority", "weight", "port", "target"); err != nil {
+		return err
+	}
+	for i, field := range []string{"priority", "weight", "port"} {
+		if err := checkZoneNumber(field, data[i], 16); err != nil {
+			return err
+		}
+	}
+	return checkZoneName("target", data[3])
+}
+
+func checkZoneTXTData(data []string) error {
+	for _, value := range data {
+		if err := checkZoneString("string", value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkZoneGenericData : checks the data of a record given by its type number, which is written as \# followed by
+// its length and the data in hexadecimal
+func checkZoneGenericData(data []string) error {
+	if len(data) < 2 || data[0] != `\#` {
+		return fmt.Errorf(`has data which doesn't start with \# and its length, as a type given by its number needs`)
+	}
+	length, err := strconv.Atoi(data[1])
+	hex := strings.Join(data[2:], "")
+	if err != nil || !zoneHexPattern.MatchString(hex) || len(hex) != length*2 {
+		return fmt.Errorf("has data which isn't %s bytes in hexadecimal", data[1])
+	}
+	return nil
+}
+
+// knownZoneRecordType : whether a record type is one the traffic manager serves, or is given by its number
+func knownZoneRecordType(recordType string) bool {
+	recordType = strings.ToUpper(recordType)
+	_, ok := zoneRecordDataChecks[recordType]
+	return ok || zoneGenericTypePattern.MatchString(recordType)
+}
+
+// CheckZoneRecordData : checks the data of a record is valid for its type
+func CheckZoneRecordData(recordType string, data []string) error {
+	recordType = strings.ToUpper(recordType)
+	if !knownZoneRecordType(recordType) {
+		return fmt.Errorf("has an unknown type %s", recordType)
+	}
+	if zoneGenericTypePattern.MatchString(recordType) {
+		return checkZoneGenericData(data)
+	}
+	return zoneRecordDataChecks[recordType](data)
+}
+
+// Check : checks the records of the zone are valid, returning an error naming the line of each which isn't, and
+// warnings of what is valid but unlikely to be meant
+func (zone *Zone) Check() ([]string, []error) {
+	warnings := make([]string, 0)
+	errors := make([]error, 0)
+
+	soaRecords := make([]*ZoneRecord, 0)
+	cnames := make(map[string]*ZoneRecord)
+	for _, record := range zone.Records() {
+		if err := checkZoneName("owner", record.Owner); err != nil {
+			errors = append(errors, zoneError(record.Line, "%s record for %s %v", record.Type, record.Owner, err))
+		}
+		if err := CheckZoneRecordData(record.Type, record.Data); err != nil {
+			errors = append(errors, zoneError(record.Line, "%s record for %s %v", record.Type, record.Owner, err))
+		}
+		if record.Class != "" && record.Class != "IN" {
+			warnings = append(warnings, fmt.Sprintf("line %d: %s record for %s is in class %s, the traffic manager only serves IN",
+				record.Line, record.Type, record.Owner, record.Class))
+		}
+
+		if record.Type == "SOA" {
+			soaRecords = append(soaRecords, record)
+		}
+		if _, ok := cnames[strings.ToLower(record.Owner)]; record.Type == "CNAME" && !ok {
+			cnames[strings.ToLower(record.Owner)] = record
+		}
+	}
+
+	for _, record := range zone.Records() {
+		cname, ok := cnames[strings.ToLower(record.Owner)]
+		if ok && record != cname {
+			errors = append(errors, zoneError(record.Line, "%s record for %s is alongside the CNAME record on line %d, "+
+				"a name with a CNAME record can't have other records", record.Type, record.Owner, cname.Line))
+		}
+	}
+	if len(soaRecords) == 0 {
+		warnings = append(warnings, "the zone file has no SOA record")
+	}
+	for i := 1; i < len(soaRecords); i++ {
+		errors = append(errors, zoneError(soaRecords[i].Line, "SOA record for %s follows the SOA record on line %d, a zone has only one",
+			soaRecords[i].Owner, soaRecords[0].Line))
+	}
+	return warnings, errors
+}
+
+// ZoneFileOrigin : returns the origin a zone file gives itself with a $ORIGIN directive before its first record, and
+// the line of the directive, or an empty origin when it has none
+func ZoneFileOrigin(text string) (string, int, error) {
+	lines, err := tokenizeZone(text)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, line := range lines {
+		if len(line.tokens) == 0 {
+			continue
+		}
+		directive := strings.ToUpper(line.tokens[0].text)
+		if line.inherit || !strings.HasPrefix(directive, "$") {
+			return "", 0, nil
+		}
+		if directive == "$ORIGIN" && len(line.tokens) == 2 {
+			return line.tokens[1].text, line.line, nil
+		}
+	}
+	return "", 0, nil
+}
+
+// CheckZoneOrigin : checks a zone file is for the origin of the zone using it, in that any $ORIGIN directive before
+// its first record names the origin, its SOA record is for the origin and its records are within it
+func CheckZoneOrigin(text, origin string) error {
+	origin = strings.ToLower(strings.TrimSuffix(origin, ".") + ".")
+	fileOrigin, line, err := ZoneFileOrigin(text)
+	if err != nil {
+		return err
+	}
+	if fileOrigin != "" && strings.HasSuffix(fileOrigin, ".") && !zoneNameEqual(fileOrigin, origin) {
+		return zoneError(line, "$ORIGIN %s doesn't match the origin of the zone, %s", fileOrigin, origin)
+	}
+
+	zone, err := ParseZone(text, origin)
+	if err != nil {
+		return err
+	}
+	for _, record := range zone.Records() {
+		owner := strings.ToLower(record.Owner)
+		if record.Type == "SOA" && owner != origin {
+			return zoneError(record.Line, "SOA record is for %s rather than the origin of the zone, %s", record.Owner, origin)
+		}
+		if owner != origin && !strings.HasSuffix(owner, "."+origin) && origin != "." {
+			return zoneError(record.Line, "%s record for %s is outside of the origin of the zone, %s", record.Type, record.Owner, origin)
+		}
+	}
+	return nil
+}
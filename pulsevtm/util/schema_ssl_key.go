@@ -3,7 +3,7 @@ package util
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/sky-uk/go-pulse-vtm/api"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
 	"net/http"
 )
 
@@ -23,14 +23,16 @@ func SchemaSSLKey() map[string]*schema.Schema {
 		},
 
 		"private": {
-			Type:      schema.TypeString,
-			Optional:  true,
-			Sensitive: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			ValidateFunc: ValidateSSLPrivateKey,
 		},
 
 		"public": {
-			Type:     schema.TypeString,
-			Optional: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: ValidateSSLCertificateChain,
 		},
 
 		"request": {
@@ -99,6 +101,10 @@ func SSLKeyRead(d *schema.ResourceData, meta interface{}, keyType string) error
 			return fmt.Errorf("[ERROR] PulseVTM %s error whilst setting attribute %s: %v", keyType, attribute, err)
 		}
 	}
+	err = SSLKeySetCertificateDetails(d)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM %s error whilst setting the certificate details of %s: %v", keyType, d.Id(), err)
+	}
 
 	return nil
 }
@@ -132,5 +138,9 @@ func SSLKeyUpdate(d *schema.ResourceData, meta interface{}, keyType string) erro
 	if err != nil {
 		return fmt.Errorf("[ERROR] PulseVTM %s error whilst updating %s: %v", keyType, d.Id(), err)
 	}
+	// The certificate has been replaced by Terraform, so isn't compared with the previous one when read back
+	if d.HasChange("public") {
+		d.Set("sha256_fingerprint", "")
+	}
 	return nil
 }
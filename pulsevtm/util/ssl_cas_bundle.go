@@ -0,0 +1,166 @@
+package util
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"sort"
+	"strings"
+	"time"
+)
+
+// crlPEMType : the PEM block type of a certificate revocation list
+const crlPEMType = "X509 CRL"
+
+// CRL : a certificate revocation list, along with the DER encoding it was parsed from
+type CRL struct {
+	*pkix.CertificateList
+	Raw []byte
+}
+
+// CABundle : the certificates and certificate revocation lists of a bundle of trusted certificates
+type CABundle struct {
+	Certificates []*x509.Certificate
+	CRLs         []*CRL
+}
+
+// ParseCABundle : parses the PEM encoded certificates and certificate revocation lists of a CA bundle. Lines may be
+// indented, and end with CRLF. Text outside of the PEM blocks, such as the comments of a bundle, is ignored.
+func ParseCABundle(bundle string) (*CABundle, error) {
+	caBundle := &CABundle{Certificates: []*x509.Certificate{}, CRLs: []*CRL{}}
+	lines := strings.Split(bundle, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	rest := []byte(strings.Join(lines, "\n"))
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("certificate %d is invalid: %v", len(caBundle.Certificates)+1, err)
+			}
+			caBundle.Certificates = append(caBundle.Certificates, certificate)
+		case crlPEMType:
+			crl, err := x509.ParseDERCRL(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("CRL %d is invalid: %v", len(caBundle.CRLs)+1, err)
+			}
+			caBundle.CRLs = append(caBundle.CRLs, &CRL{CertificateList: crl, Raw: block.Bytes})
+		default:
+			return nil, fmt.Errorf("unexpected PEM block %s", block.Type)
+		}
+	}
+	return caBundle, nil
+}
+
+// PEM : returns the bundle PEM encoded, with the certificates followed by the certificate revocation lists
+func (caBundle *CABundle) PEM() string {
+	var bundle bytes.Buffer
+	for _, certificate := range caBundle.Certificates {
+		bundle.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}))
+	}
+	for _, crl := range caBundle.CRLs {
+		bundle.Write(pem.EncodeToMemory(&pem.Block{Type: crlPEMType, Bytes: crl.Raw}))
+	}
+	return bundle.String()
+}
+
+// Fingerprints : returns the SHA-256 fingerprints of the certificates and certificate revocation lists of the bundle,
+// sorted so bundles differing only in order and formatting have the same fingerprints
+func (caBundle *CABundle) Fingerprints() []string {
+	fingerprints := make([]string, 0)
+	for _, certificate := range caBundle.Certificates {
+		fingerprints = append(fingerprints, "certificate "+CertificateFingerprint(certificate))
+	}
+	for _, crl := range caBundle.CRLs {
+		fingerprints = append(fingerprints, "crl "+derFingerprint(crl.Raw))
+	}
+	sort.Strings(fingerprints)
+	return fingerprints
+}
+
+// Warnings : returns warnings for certificates of the bundle which have expired or are about to, and certificate
+// revocation lists which are stale as their next update is due
+func (caBundle *CABundle) Warnings(k string, now time.Time) []string {
+	warnings := CertificateExpiryWarnings(caBundle.Certificates, k, now)
+	for i, crl := range caBundle.CRLs {
+		nextUpdate := crl.TBSCertList.NextUpdate
+		if !nextUpdate.IsZero() && now.After(nextUpdate) {
+			warnings = append(warnings, fmt.Sprintf("[WARN] %q CRL %d (%s) is stale, its next update was due at %s",
+				k, i+1, FormatDistinguishedName(crl.TBSCertList.Issuer), nextUpdate.UTC().Format(time.RFC3339)))
+		}
+	}
+	return warnings
+}
+
+// validateCABundleEntry : check a value is a single PEM encoded entry of a CA bundle of the given type, warning of
+// it having expired or being stale
+func validateCABundleEntry(v interface{}, k, description string, entries func(*CABundle) int) (ws []string, errors []error) {
+	caBundle, err := ParseCABundle(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid %s: %v", k, description, err))
+		return
+	}
+	if entries(caBundle) != 1 || len(caBundle.Certificates)+len(caBundle.CRLs) != 1 {
+		errors = append(errors, fmt.Errorf("[ERROR] %q must hold exactly one PEM encoded %s", k, description))
+		return
+	}
+	ws = caBundle.Warnings(k, time.Now())
+	return
+}
+
+// ValidateCACertificate : check a value is a single PEM encoded certificate, warning of it having expired
+func ValidateCACertificate(v interface{}, k string) (ws []string, errors []error) {
+	return validateCABundleEntry(v, k, "certificate", func(caBundle *CABundle) int { return len(caBundle.Certificates) })
+}
+
+// ValidateCRL : check a value is a single PEM encoded certificate revocation list, warning of it being stale
+func ValidateCRL(v interface{}, k string) (ws []string, errors []error) {
+	return validateCABundleEntry(v, k, "CRL", func(caBundle *CABundle) int { return len(caBundle.CRLs) })
+}
+
+// ValidateCABundle : check a value is a bundle of PEM encoded certificates and CRLs, warning of any which have
+// expired or are stale
+func ValidateCABundle(v interface{}, k string) (ws []string, errors []error) {
+	caBundle, err := ParseCABundle(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid CA bundle: %v", k, err))
+		return
+	}
+	ws = caBundle.Warnings(k, time.Now())
+	return
+}
+
+// HashCABundleEntry : hashes a PEM encoded certificate or CRL of a set by its fingerprint, so differences in
+// formatting alone don't cause a diff
+func HashCABundleEntry(v interface{}) int {
+	caBundle, err := ParseCABundle(v.(string))
+	if err != nil {
+		return hashcode.String(strings.TrimSpace(v.(string)))
+	}
+	return hashcode.String(strings.Join(caBundle.Fingerprints(), ","))
+}
+
+// SuppressEquivalentCABundle : suppresses the diff of a CA bundle holding the same certificates and CRLs, whatever
+// their order and formatting
+func SuppressEquivalentCABundle(k, old, new string, d *schema.ResourceData) bool {
+	oldBundle, err := ParseCABundle(old)
+	if err != nil {
+		return false
+	}
+	newBundle, err := ParseCABundle(new)
+	if err != nil {
+		return false
+	}
+	return strings.Join(oldBundle.Fingerprints(), ",") == strings.Join(newBundle.Fingerprints(), ",")
+}
@@ -0,0 +1,362 @@
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"log"
+	"strings"
+	"time"
+)
+
+// certificateExpiryWarning : how long before a certificate expires a plan warns about it
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// SchemaSSLKeyCertificateDetails : Returns the schema of the computed details of the certificate of an SSL Key, which
+// SSLKeyRead sets and so every SSL Key resource adds to its schema
+func SchemaSSLKeyCertificateDetails() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"sha256_fingerprint": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The SHA-256 fingerprint of the certificate in public, used to detect it being changed on the traffic manager",
+		},
+		"not_after": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The time the certificate in public expires, in RFC 3339 format",
+		},
+		"subject": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The subject of the certificate in public, in RFC 2253 format",
+		},
+		"sans": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The subject alternative names of the certificate in public",
+		},
+	}
+}
+
+// ParsePrivateKeyPEM : parses a PEM encoded PKCS #1, PKCS #8 or EC private key, returning its public key
+func ParsePrivateKeyPEM(privatePEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return nil, fmt.Errorf("no PEM encoded private key found")
+	}
+	if block.Type == "ENCRYPTED PRIVATE KEY" || block.Headers["Proc-Type"] != "" {
+		return nil, fmt.Errorf("the private key is encrypted")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer.Public(), nil
+}
+
+// ParseCertificatesPEM : parses the certificates in PEM encoded data, in the order they appear
+func ParseCertificatesPEM(certificatesPEM string) ([]*x509.Certificate, error) {
+	certificates := make([]*x509.Certificate, 0)
+	rest := []byte(certificatesPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d is invalid: %v", len(certificates)+1, err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return certificates, nil
+}
+
+// CheckCertificateChainOrder : checks each certificate of a chain is issued by the one following it, so the chain
+// starts with the server certificate and is followed by each issuer in turn
+func CheckCertificateChainOrder(certificates []*x509.Certificate) error {
+	for i := 0; i < len(certificates)-1; i++ {
+		certificate, issuer := certificates[i], certificates[i+1]
+		err := issuer.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature)
+		if err != nil || string(certificate.RawIssuer) != string(issuer.RawSubject) {
+			return fmt.Errorf("certificate %d (%s) is not issued by certificate %d (%s); the chain must start with the "+
+				"server certificate followed by each issuer in turn", i+1, CertificateSubject(certificate), i+2, CertificateSubject(issuer))
+		}
+	}
+	return nil
+}
+
+// distinguishedNameAttributes : the short names of the attributes of distinguished names, by their OID
+var distinguishedNameAttributes = map[string]string{
+	"2.5.4.3":  "CN",
+	"2.5.4.5":  "SERIALNUMBER",
+	"2.5.4.6":  "C",
+	"2.5.4.7":  "L",
+	"2.5.4.8":  "ST",
+	"2.5.4.9":  "STREET",
+	"2.5.4.10": "O",
+	"2.5.4.11": "OU",
+	"2.5.4.17": "POSTALCODE",
+}
+
+// FormatDistinguishedName : returns a distinguished name as text, in the RFC 2253 format, e.g. CN=www.example.com,O=Example
+func FormatDistinguishedName(rdns pkix.RDNSequence) string {
+	names := make([]string, 0)
+	for i := len(rdns) - 1; i >= 0; i-- {
+		attributes := make([]string, 0)
+		for _, attribute := range rdns[i] {
+			attributeType, ok := distinguishedNameAttributes[attribute.Type.String()]
+			if !ok {
+				attributeType = attribute.Type.String()
+			}
+			attributes = append(attributes, attributeType+"="+escapeDistinguishedNameValue(fmt.Sprint(attribute.Value)))
+		}
+		names = append(names, strings.Join(attributes, "+"))
+	}
+	return strings.Join(names, ",")
+}
+
+// escapeDistinguishedNameValue : escapes the characters of an attribute value with a meaning in distinguished names
+func escapeDistinguishedNameValue(value string) string {
+	escaped := make([]rune, 0, len(value))
+	for i, r := range value {
+		switch {
+		case strings.ContainsRune(",+\"<>;\\", r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(value)-1 && r == ' ':
+			escaped = append(escaped, '\\', r)
+		default:
+			escaped = append(escaped, r)
+		}
+	}
+	return string(escaped)
+}
+
+// CertificateSubject : returns the subject of a certificate as text, e.g. CN=www.example.com,O=Example
+func CertificateSubject(certificate *x509.Certificate) string {
+	var subject pkix.RDNSequence
+	if _, err := asn1.Unmarshal(certificate.RawSubject, &subject); err != nil {
+		return certificate.Subject.CommonName
+	}
+	return FormatDistinguishedName(subject)
+}
+
+// certificateURIs : returns the URIs among the subject alternative names of a certificate
+func certificateURIs(certificate *x509.Certificate) []string {
+	uris := make([]string, 0)
+	for _, extension := range certificate.Extensions {
+		// The subject alternative name extension, with URIs held as the general name with tag 6
+		if !extension.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 17}) {
+			continue
+		}
+		var generalNames asn1.RawValue
+		if _, err := asn1.Unmarshal(extension.Value, &generalNames); err != nil {
+			return uris
+		}
+		rest := generalNames.Bytes
+		for len(rest) > 0 {
+			var generalName asn1.RawValue
+			var err error
+			rest, err = asn1.Unmarshal(rest, &generalName)
+			if err != nil {
+				return uris
+			}
+			if generalName.Class == asn1.ClassContextSpecific && generalName.Tag == 6 {
+				uris = append(uris, string(generalName.Bytes))
+			}
+		}
+	}
+	return uris
+}
+
+// CertificateFingerprint : returns the SHA-256 fingerprint of a certificate as colon separated hex bytes
+func CertificateFingerprint(certificate *x509.Certificate) string {
+	return derFingerprint(certificate.Raw)
+}
+
+// derFingerprint : returns the SHA-256 fingerprint of DER encoded data as colon separated hex bytes
+func derFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	bytes := make([]string, 0)
+	for _, b := range sum {
+		bytes = append(bytes, fmt.Sprintf("%02X", b))
+	}
+	return strings.Join(bytes, ":")
+}
+
+// CertificateExpiryWarnings : returns warnings for certificates which have expired or are about to
+func CertificateExpiryWarnings(certificates []*x509.Certificate, k string, now time.Time) []string {
+	warnings := make([]string, 0)
+	for i, certificate := range certificates {
+		notAfter := certificate.NotAfter.UTC().Format(time.RFC3339)
+		if now.After(certificate.NotAfter) {
+			warnings = append(warnings, fmt.Sprintf("[WARN] %q certificate %d (%s) expired at %s", k, i+1, CertificateSubject(certificate), notAfter))
+		} else if now.Add(certificateExpiryWarning).After(certificate.NotAfter) {
+			warnings = append(warnings, fmt.Sprintf("[WARN] %q certificate %d (%s) expires at %s", k, i+1, CertificateSubject(certificate), notAfter))
+		}
+	}
+	return warnings
+}
+
+// certificateSANs : returns the subject alternative names of a certificate
+func certificateSANs(certificate *x509.Certificate) []string {
+	sans := make([]string, 0)
+	sans = append(sans, certificate.DNSNames...)
+	for _, ipAddress := range certificate.IPAddresses {
+		sans = append(sans, ipAddress.String())
+	}
+	sans = append(sans, certificate.EmailAddresses...)
+	return append(sans, certificateURIs(certificate)...)
+}
+
+// ValidateSSLPrivateKey : check the private key of an SSL Key is an unencrypted PEM encoded private key
+func ValidateSSLPrivateKey(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) == "" {
+		return
+	}
+	_, err := ParsePrivateKeyPEM(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid private key: %v", k, err))
+	}
+	return
+}
+
+// ValidateSSLCertificateChain : check the certificate of an SSL Key is a PEM encoded certificate, followed by its
+// chain in order, warning of any which have expired or are about to
+func ValidateSSLCertificateChain(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) == "" {
+		return
+	}
+	certificates, err := ParseCertificatesPEM(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid certificate: %v", k, err))
+		return
+	}
+	err = CheckCertificateChainOrder(certificates)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not a valid certificate chain: %v", k, err))
+	}
+	ws = CertificateExpiryWarnings(certificates, k, time.Now())
+	return
+}
+
+// CheckSSLKeyPair : checks the certificate at the start of public is for the private key
+func CheckSSLKeyPair(privatePEM, publicPEM string) error {
+	publicKey, err := ParsePrivateKeyPEM(privatePEM)
+	if err != nil {
+		return err
+	}
+	certificates, err := ParseCertificatesPEM(publicPEM)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	certificateKeyDER, err := x509.MarshalPKIXPublicKey(certificates[0].PublicKey)
+	if err != nil || !bytes.Equal(keyDER, certificateKeyDER) {
+		return fmt.Errorf("the certificate (%s) is not for the private key", CertificateSubject(certificates[0]))
+	}
+	return nil
+}
+
+// sslKeyPlannedValue : returns the value an attribute of an SSL Key will have, from its configuration or else its
+// state, and whether it's known at plan time
+func sslKeyPlannedValue(s *terraform.InstanceState, c *terraform.ResourceConfig, attribute string) (string, bool) {
+	if c.IsComputed(attribute) {
+		return "", false
+	}
+	if v, ok := c.Get(attribute); ok {
+		value, ok := v.(string)
+		return value, ok && !strings.Contains(value, config.UnknownVariableValue)
+	}
+	if s != nil {
+		return s.Attributes[attribute], true
+	}
+	return "", true
+}
+
+// CheckSSLKeyConfig : checks when planning that the certificate in public of an SSL Key is for its private key
+func CheckSSLKeyConfig(s *terraform.InstanceState, c *terraform.ResourceConfig) error {
+	privatePEM, privateKnown := sslKeyPlannedValue(s, c, "private")
+	publicPEM, publicKnown := sslKeyPlannedValue(s, c, "public")
+	if !privateKnown || !publicKnown || privatePEM == "" || publicPEM == "" {
+		return nil
+	}
+	// Malformed values are reported by the attributes' own validation
+	if _, err := ParsePrivateKeyPEM(privatePEM); err != nil {
+		return nil
+	}
+	if _, err := ParseCertificatesPEM(publicPEM); err != nil {
+		return nil
+	}
+	err := CheckSSLKeyPair(privatePEM, publicPEM)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM SSL key %s: %v", c.Config["name"], err)
+	}
+	return nil
+}
+
+// SSLKeySetCertificateDetails : Sets the details of the certificate in public, which are empty when public doesn't
+// hold a certificate
+func SSLKeySetCertificateDetails(d *schema.ResourceData) error {
+	details := map[string]interface{}{"sha256_fingerprint": "", "not_after": "", "subject": "", "sans": []string{}}
+	if certificates, err := ParseCertificatesPEM(d.Get("public").(string)); err == nil {
+		details["sha256_fingerprint"] = CertificateFingerprint(certificates[0])
+		details["not_after"] = certificates[0].NotAfter.UTC().Format(time.RFC3339)
+		details["subject"] = CertificateSubject(certificates[0])
+		details["sans"] = certificateSANs(certificates[0])
+	}
+	for key, value := range details {
+		err := d.Set(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SSLKeyCertificateDrifted : whether the certificate of an SSL Key read from the traffic manager differs from the one
+// last in state, as when the key pair has been replaced outside of Terraform. The private key can't be read back, so
+// it's cleared from state for the plan to send it again.
+func SSLKeyCertificateDrifted(d *schema.ResourceData, previousFingerprint string) bool {
+	fingerprint := d.Get("sha256_fingerprint").(string)
+	if previousFingerprint == "" || previousFingerprint == fingerprint {
+		return false
+	}
+	log.Printf("[WARN] PulseVTM SSL key %s certificate has changed from %s to %q outside of Terraform", d.Id(), previousFingerprint, fingerprint)
+	d.Set("private", "")
+	return true
+}
@@ -0,0 +1,220 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"math/big"
+	"net"
+	"time"
+)
+
+// ecdsaCurves : the curves ECDSA keys can be generated on
+var ecdsaCurves = map[string]elliptic.Curve{
+	"P256": elliptic.P256(),
+	"P384": elliptic.P384(),
+	"P521": elliptic.P521(),
+}
+
+// SchemaSSLKeyGenerate : Returns the schema of the generate block of an SSL Key
+func SchemaSSLKeyGenerate() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		ForceNew:    true,
+		MaxItems:    1,
+		Description: "Generate the private key along with a self-signed certificate or a certificate signing request",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key_type": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "rsa",
+					ValidateFunc: StringInSlice([]string{"rsa", "ecdsa"}, false),
+					Description:  "Type of the private key, rsa or ecdsa",
+				},
+				"rsa_bits": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      2048,
+					ValidateFunc: IntBetween(2048, 8192),
+					Description:  "Size of an RSA key in bits",
+				},
+				"ecdsa_curve": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "P256",
+					ValidateFunc: StringInSlice([]string{"P256", "P384", "P521"}, false),
+					Description:  "Curve of an ECDSA key, P256, P384 or P521",
+				},
+				"common_name": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: NoZeroValues,
+					Description:  "Common name of the subject",
+				},
+				"organization": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"organizational_unit": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"locality": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"province": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"country": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: StringLenBetween(2, 2),
+					Description:  "Two letter country code of the subject",
+				},
+				"dns_names": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "DNS names the certificate is for, in addition to the common name",
+				},
+				"ip_addresses": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: ValidateIP},
+					Description: "IP addresses the certificate is for",
+				},
+				"validity_days": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      365,
+					ValidateFunc: IntAtLeast(1),
+					Description:  "Number of days a self-signed certificate is valid for",
+				},
+				"self_signed": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Whether to create a self-signed certificate in public, or else a certificate signing request in request to be signed and set as public",
+				},
+			},
+		},
+	}
+}
+
+// generatePrivateKey : generates an RSA or ECDSA private key, returning it along with its PEM encoding
+func generatePrivateKey(settings map[string]interface{}) (crypto.Signer, string, error) {
+	if settings["key_type"].(string) == "ecdsa" {
+		key, err := ecdsa.GenerateKey(ecdsaCurves[settings["ecdsa_curve"].(string)], rand.Reader)
+		if err != nil {
+			return nil, "", err
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, "", err
+		}
+		return key, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, settings["rsa_bits"].(int))
+	if err != nil {
+		return nil, "", err
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})), nil
+}
+
+// generateSubject : returns the subject and SANs given in the generate block
+func generateSubject(settings map[string]interface{}) (pkix.Name, []string, []net.IP) {
+	subject := pkix.Name{CommonName: settings["common_name"].(string)}
+	for key, field := range map[string]*[]string{
+		"organization":        &subject.Organization,
+		"organizational_unit": &subject.OrganizationalUnit,
+		"locality":            &subject.Locality,
+		"province":            &subject.Province,
+		"country":             &subject.Country,
+	} {
+		if value := settings[key].(string); value != "" {
+			*field = []string{value}
+		}
+	}
+
+	dnsNames := []string{subject.CommonName}
+	for _, dnsName := range settings["dns_names"].([]interface{}) {
+		if dnsName.(string) != subject.CommonName {
+			dnsNames = append(dnsNames, dnsName.(string))
+		}
+	}
+	ipAddresses := make([]net.IP, 0)
+	for _, ipAddress := range settings["ip_addresses"].([]interface{}) {
+		ipAddresses = append(ipAddresses, net.ParseIP(ipAddress.(string)))
+	}
+	return subject, dnsNames, ipAddresses
+}
+
+// SSLKeyGenerate : Generates the private key of an SSL Key as set out in its generate block, along with a self-signed
+// certificate in public or a certificate signing request in request. Does nothing without a generate block.
+func SSLKeyGenerate(d *schema.ResourceData) error {
+	generate := d.Get("generate").([]interface{})
+	if len(generate) == 0 {
+		return nil
+	}
+	settings := generate[0].(map[string]interface{})
+
+	key, privatePEM, err := generatePrivateKey(settings)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst generating private key: %v", err)
+	}
+	subject, dnsNames, ipAddresses := generateSubject(settings)
+
+	if !settings["self_signed"].(bool) {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject:     subject,
+			DNSNames:    dnsNames,
+			IPAddresses: ipAddresses,
+		}, key)
+		if err != nil {
+			return fmt.Errorf("[ERROR] PulseVTM error whilst generating certificate signing request: %v", err)
+		}
+		d.Set("private", privatePEM)
+		d.Set("request", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})))
+		return nil
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst generating certificate serial number: %v", err)
+	}
+	notBefore := time.Now().UTC()
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		DNSNames:              dnsNames,
+		IPAddresses:           ipAddresses,
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(0, 0, settings["validity_days"].(int)),
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return fmt.Errorf("[ERROR] PulseVTM error whilst generating self-signed certificate: %v", err)
+	}
+	d.Set("private", privatePEM)
+	d.Set("public", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	return nil
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// StateMigration : upgrades the state of a resource from one schema version to the next. Only the resources whose
+// schema has changed in a way the state stored with it can't be read with set a SchemaVersion and migrations; the
+// others are left at version 0 until they do.
+type StateMigration func(is *terraform.InstanceState) (*terraform.InstanceState, error)
+
+// MigrateStateFunc : returns a schema.StateMigrateFunc which upgrades state from the version it was stored with to
+// the current version. migrations[i] upgrades state from version i to version i+1, so the resource's SchemaVersion
+// should be len(migrations).
+func MigrateStateFunc(resourceType string, migrations ...StateMigration) schema.StateMigrateFunc {
+	return func(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+		if v < 0 || v >= len(migrations) {
+			return is, fmt.Errorf("[ERROR] Unexpected %s schema version: %d", resourceType, v)
+		}
+		if is.Empty() || is.Attributes == nil {
+			log.Printf("[DEBUG] Empty %s InstanceState; nothing to migrate.", resourceType)
+			return is, nil
+		}
+
+		log.Printf("[DEBUG] %s attributes before migration: %#v", resourceType, is.Attributes)
+		for version := v; version < len(migrations); version++ {
+			log.Printf("[INFO] Found %s State v%d; migrating to v%d", resourceType, version, version+1)
+			var err error
+			is, err = migrations[version](is)
+			if err != nil {
+				return is, fmt.Errorf("[ERROR] %s error whilst migrating state from v%d to v%d: %v", resourceType, version, version+1, err)
+			}
+		}
+		log.Printf("[DEBUG] %s attributes after migration: %#v", resourceType, is.Attributes)
+		return is, nil
+	}
+}
+
+// MigrateSetToList : rewrites the flatmap keys of a set of primitives, which are the hashes of its elements, as the
+// indexes of a list. The elements keep the order of their hashes, which is the order schema.Set lists them in and so
+// the order they were sent to the traffic manager in before the migration.
+func MigrateSetToList(is *terraform.InstanceState, attribute string) {
+	if _, ok := is.Attributes[attribute+".#"]; !ok {
+		return
+	}
+
+	hashes := make([]string, 0)
+	elements := make(map[string]string)
+	for key, value := range is.Attributes {
+		if !strings.HasPrefix(key, attribute+".") || key == attribute+".#" {
+			continue
+		}
+		hash := strings.TrimPrefix(key, attribute+".")
+		hashes = append(hashes, hash)
+		elements[hash] = value
+		delete(is.Attributes, key)
+	}
+
+	sort.Strings(hashes)
+	for index, hash := range hashes {
+		is.Attributes[attribute+"."+strconv.Itoa(index)] = elements[hash]
+	}
+	is.Attributes[attribute+".#"] = strconv.Itoa(len(hashes))
+}
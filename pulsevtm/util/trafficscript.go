@@ -0,0 +1,701 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tsTokenKind : the kind of a token of TrafficScript
+type tsTokenKind int
+
+const (
+	tsEOF tsTokenKind = iota
+	tsIdentifier
+	tsVariable
+	tsNumber
+	tsString
+	tsOperator
+)
+
+// tsOperators : the operators and punctuation of TrafficScript, longest first so they're matched greedily
+var tsOperators = []string{
+	"<<=", ">>=",
+	"==", "!=", "<=", ">=", "&&", "||", "++", "--", "+=", "-=", "*=", "/=", "%=", ".=", "&=", "|=", "^=", "<<", ">>", "=>",
+	"+", "-", "*", "/", "%", ".", "=", "<", ">", "!", "~", "&", "|", "^", "?", ":", ";", ",", "(", ")", "{", "}", "[", "]",
+}
+
+// tsAssignmentOperators : the operators assigning to a variable
+var tsAssignmentOperators = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true, ".=": true, "&=": true, "|=": true, "^=": true,
+	"<<=": true, ">>=": true,
+}
+
+// tsBinaryOperators : the binary operators of TrafficScript by precedence, loosest binding first
+var tsBinaryOperators = [][]string{
+	{"||"},
+	{"&&"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"<<", ">>"},
+	{"+", "-", "."},
+	{"*", "/", "%"},
+}
+
+// tsKeywords : the words of TrafficScript which can't be used as the names of functions
+var tsKeywords = map[string]bool{
+	"if": true, "else": true, "while": true, "for": true, "foreach": true, "in": true, "sub": true, "import": true,
+	"as": true, "break": true, "continue": true, "return": true,
+}
+
+// tsToken : a token of TrafficScript, and the line and column it starts at
+type tsToken struct {
+	kind   tsTokenKind
+	text   string
+	line   int
+	column int
+}
+
+// describe : returns the token as it's named in errors
+func (token tsToken) describe() string {
+	switch token.kind {
+	case tsEOF:
+		return "the end of the rule"
+	case tsString:
+		return "a string"
+	case tsNumber:
+		return "the number " + token.text
+	case tsVariable:
+		return "the variable " + token.text
+	}
+	return fmt.Sprintf("%q", token.text)
+}
+
+// TrafficScriptError : an error in a TrafficScript rule, and the line and column it was found at
+type TrafficScriptError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (err *TrafficScriptError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", err.Line, err.Column, err.Message)
+}
+
+// TrafficScriptCall : a call of a function in a TrafficScript rule
+type TrafficScriptCall struct {
+	Function string
+	Line     int
+	Column   int
+}
+
+// TrafficScriptRule : the functions defined, imported and called by a TrafficScript rule
+type TrafficScriptRule struct {
+	Subroutines map[string]bool
+	Imports     map[string]bool
+	Calls       []TrafficScriptCall
+}
+
+// tsIsIdentifierStart : whether a character starts an identifier
+func tsIsIdentifierStart(c rune) bool {
+	return c == '_' || (c < unicode.MaxASCII && unicode.IsLetter(c))
+}
+
+// tsIsIdentifierPart : whether a character continues an identifier
+func tsIsIdentifierPart(c rune) bool {
+	return tsIsIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+// lexTrafficScript : splits a TrafficScript rule into its tokens, dropping whitespace and comments. The names of
+// functions, such as http.getHeader, are single tokens.
+func lexTrafficScript(rule string) ([]tsToken, error) {
+	text := []rune(rule)
+	tokens := make([]tsToken, 0)
+	line, column := 1, 1
+	advance := func(i int) int {
+		if text[i] == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+		return i + 1
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		startLine, startColumn := line, column
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i = advance(i)
+		case c == '#':
+			for i < len(text) && text[i] != '\n' {
+				i = advance(i)
+			}
+		case c == '"' || c == '\'':
+			i = advance(i)
+			for i < len(text) && text[i] != c {
+				if text[i] == '\\' && i+1 < len(text) {
+					i = advance(i)
+				}
+				i = advance(i)
+			}
+			if i >= len(text) {
+				return nil, &TrafficScriptError{startLine, startColumn, "string is never closed"}
+			}
+			i = advance(i)
+			tokens = append(tokens, tsToken{tsString, string(text[start:i]), startLine, startColumn})
+		case c == '$':
+			i = advance(i)
+			for i < len(text) && tsIsIdentifierPart(text[i]) {
+				i = advance(i)
+			}
+			if i == start+1 {
+				return nil, &TrafficScriptError{startLine, startColumn, "$ isn't followed by the name of a variable"}
+			}
+			tokens = append(tokens, tsToken{tsVariable, string(text[start:i]), startLine, startColumn})
+		case c >= '0' && c <= '9' || (c == '.' && i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9'):
+			if c == '0' && i+1 < len(text) && (text[i+1] == 'x' || text[i+1] == 'X') {
+				i = advance(advance(i))
+				for i < len(text) && strings.ContainsRune("0123456789abcdefABCDEF", text[i]) {
+					i = advance(i)
+				}
+			} else {
+				for i < len(text) && (text[i] >= '0' && text[i] <= '9' || text[i] == '.') {
+					i = advance(i)
+				}
+				if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+					i = advance(i)
+					if i < len(text) && (text[i] == '+' || text[i] == '-') {
+						i = advance(i)
+					}
+					for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+						i = advance(i)
+					}
+				}
+			}
+			if i < len(text) && tsIsIdentifierPart(text[i]) || strings.Count(string(text[start:i]), ".") > 1 {
+				for i < len(text) && (tsIsIdentifierPart(text[i]) || text[i] == '.') {
+					i++
+				}
+				return nil, &TrafficScriptError{startLine, startColumn, fmt.Sprintf("%q isn't a valid number", string(text[start:i]))}
+			}
+			tokens = append(tokens, tsToken{tsNumber, string(text[start:i]), startLine, startColumn})
+		case tsIsIdentifierStart(c):
+			for i < len(text) && tsIsIdentifierPart(text[i]) {
+				i = advance(i)
+				if i+1 < len(text) && text[i] == '.' && tsIsIdentifierStart(text[i+1]) {
+					i = advance(i)
+				}
+			}
+			tokens = append(tokens, tsToken{tsIdentifier, string(text[start:i]), startLine, startColumn})
+		default:
+			operator, rest := "", string(text[i:])
+			for _, candidate := range tsOperators {
+				if strings.HasPrefix(rest, candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, &TrafficScriptError{startLine, startColumn, fmt.Sprintf("unexpected character %q", c)}
+			}
+			for range operator {
+				i = advance(i)
+			}
+			tokens = append(tokens, tsToken{tsOperator, operator, startLine, startColumn})
+		}
+	}
+	return append(tokens, tsToken{tsEOF, "", line, column}), nil
+}
+
+// tsParser : a recursive descent parser of TrafficScript
+type tsParser struct {
+	tokens []tsToken
+	pos    int
+	// loops is the depth of loops the parser is within, for break and continue to be checked
+	loops int
+	rule  *TrafficScriptRule
+}
+
+// tsExpression : what's known of an expression once it's parsed
+type tsExpression struct {
+	// assignable is set for variables and their elements
+	assignable bool
+}
+
+func (parser *tsParser) peek() tsToken {
+	return parser.tokens[parser.pos]
+}
+
+func (parser *tsParser) next() tsToken {
+	token := parser.tokens[parser.pos]
+	if token.kind != tsEOF {
+		parser.pos++
+	}
+	return token
+}
+
+// is : whether the next token is an operator or keyword
+func (parser *tsParser) is(texts ...string) bool {
+	token := parser.peek()
+	if token.kind != tsOperator && token.kind != tsIdentifier {
+		return false
+	}
+	for _, text := range texts {
+		if token.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// errorAt : returns an error at a token
+func (parser *tsParser) errorAt(token tsToken, format string, a ...interface{}) error {
+	return &TrafficScriptError{token.line, token.column, fmt.Sprintf(format, a...)}
+}
+
+// expect : consumes the next token, which must be an operator or keyword
+func (parser *tsParser) expect(text, context string) (tsToken, error) {
+	token := parser.peek()
+	if !parser.is(text) {
+		return token, parser.errorAt(token, "expected %q %s, but found %s", text, context, token.describe())
+	}
+	return parser.next(), nil
+}
+
+// parseStatements : parses statements until the end of a block or of the rule
+func (parser *tsParser) parseStatements(end string) error {
+	for !parser.is(end) && parser.peek().kind != tsEOF {
+		err := parser.parseStatement()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseBlock : parses statements within braces
+func (parser *tsParser) parseBlock(context string) error {
+	open, err := parser.expect("{", context)
+	if err != nil {
+		return err
+	}
+	err = parser.parseStatements("}")
+	if err != nil {
+		return err
+	}
+	if parser.peek().kind == tsEOF {
+		return parser.errorAt(open, "{ is never closed")
+	}
+	parser.next()
+	return nil
+}
+
+// parseCondition : parses an expression within parentheses
+func (parser *tsParser) parseCondition(keyword string) error {
+	_, err := parser.expect("(", "after "+keyword)
+	if err != nil {
+		return err
+	}
+	_, err = parser.parseExpression()
+	if err != nil {
+		return err
+	}
+	_, err = parser.expect(")", "to close the condition of "+keyword)
+	return err
+}
+
+// parseLoopBody : parses the block of a loop
+func (parser *tsParser) parseLoopBody(keyword string) error {
+	parser.loops++
+	defer func() { parser.loops-- }()
+	return parser.parseBlock("to start the body of " + keyword)
+}
+
+// parseStatement : parses a statement
+func (parser *tsParser) parseStatement() error {
+	token := parser.peek()
+	if token.kind == tsIdentifier {
+		switch token.text {
+		case "if":
+			parser.next()
+			err := parser.parseCondition("if")
+			if err != nil {
+				return err
+			}
+			err = parser.parseBlock("to start the body of if")
+			if err != nil {
+				return err
+			}
+			if parser.is("else") {
+				parser.next()
+				if parser.is("if") {
+					return parser.parseStatement()
+				}
+				return parser.parseBlock("or if after else")
+			}
+			return nil
+		case "while":
+			parser.next()
+			err := parser.parseCondition("while")
+			if err != nil {
+				return err
+			}
+			return parser.parseLoopBody("while")
+		case "for":
+			parser.next()
+			_, err := parser.expect("(", "after for")
+			if err != nil {
+				return err
+			}
+			for i, end := range []string{";", ";", ")"} {
+				if !parser.is(end) {
+					_, err := parser.parseExpression()
+					if err != nil {
+						return err
+					}
+				}
+				_, err = parser.expect(end, fmt.Sprintf("after part %d of for", i+1))
+				if err != nil {
+					return err
+				}
+			}
+			return parser.parseLoopBody("for")
+		case "foreach":
+			parser.next()
+			_, err := parser.expect("(", "after foreach")
+			if err != nil {
+				return err
+			}
+			if variable := parser.next(); variable.kind != tsVariable {
+				return parser.errorAt(variable, "expected a variable after foreach (, but found %s", variable.describe())
+			}
+			_, err = parser.expect("in", "after the variable of foreach")
+			if err != nil {
+				return err
+			}
+			_, err = parser.parseExpression()
+			if err != nil {
+				return err
+			}
+			_, err = parser.expect(")", "to close foreach")
+			if err != nil {
+				return err
+			}
+			return parser.parseLoopBody("foreach")
+		case "sub":
+			parser.next()
+			name := parser.next()
+			if name.kind != tsIdentifier || tsKeywords[name.text] || strings.Contains(name.text, ".") {
+				return parser.errorAt(name, "expected the name of a subroutine after sub, but found %s", name.describe())
+			}
+			parser.rule.Subroutines[strings.ToLower(name.text)] = true
+			_, err := parser.expect("(", "after the name of subroutine "+name.text)
+			if err != nil {
+				return err
+			}
+			for !parser.is(")") {
+				if variable := parser.next(); variable.kind != tsVariable {
+					return parser.errorAt(variable, "expected a variable as an argument of subroutine %s, but found %s", name.text, variable.describe())
+				}
+				if !parser.is(")") {
+					_, err := parser.expect(",", "between the arguments of subroutine "+name.text)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			parser.next()
+			loops := parser.loops
+			parser.loops = 0
+			defer func() { parser.loops = loops }()
+			return parser.parseBlock("to start the body of subroutine " + name.text)
+		case "import":
+			parser.next()
+			library := parser.next()
+			if library.kind != tsIdentifier || tsKeywords[library.text] {
+				return parser.errorAt(library, "expected the name of a rule after import, but found %s", library.describe())
+			}
+			alias := library.text
+			if parser.is("as") {
+				parser.next()
+				aliasToken := parser.next()
+				if aliasToken.kind != tsIdentifier || tsKeywords[aliasToken.text] || strings.Contains(aliasToken.text, ".") {
+					return parser.errorAt(aliasToken, "expected a name after as, but found %s", aliasToken.describe())
+				}
+				alias = aliasToken.text
+			}
+			parser.rule.Imports[strings.ToLower(alias)] = true
+			_, err := parser.expect(";", "after import")
+			return err
+		case "break", "continue":
+			parser.next()
+			if parser.loops == 0 {
+				return parser.errorAt(token, "%s is outside of a loop", token.text)
+			}
+			_, err := parser.expect(";", "after "+token.text)
+			return err
+		case "return":
+			parser.next()
+			if !parser.is(";") {
+				_, err := parser.parseExpression()
+				if err != nil {
+					return err
+				}
+			}
+			_, err := parser.expect(";", "after return")
+			return err
+		case "else", "in", "as":
+			return parser.errorAt(token, "unexpected %s", token.text)
+		}
+	}
+	if parser.is(";") {
+		parser.next()
+		return nil
+	}
+	if parser.is("{") {
+		return parser.parseBlock("")
+	}
+	_, err := parser.parseExpression()
+	if err != nil {
+		return err
+	}
+	_, err = parser.expect(";", "at the end of the statement")
+	return err
+}
+
+// parseExpression : parses an expression, including assignments
+func (parser *tsParser) parseExpression() (tsExpression, error) {
+	start := parser.peek()
+	left, err := parser.parseTernary()
+	if err != nil {
+		return left, err
+	}
+	if parser.peek().kind == tsOperator && tsAssignmentOperators[parser.peek().text] {
+		operator := parser.next()
+		if !left.assignable {
+			return left, parser.errorAt(start, "%s can only assign to a variable", operator.text)
+		}
+		_, err := parser.parseExpression()
+		return tsExpression{}, err
+	}
+	return left, nil
+}
+
+// parseTernary : parses a conditional expression
+func (parser *tsParser) parseTernary() (tsExpression, error) {
+	condition, err := parser.parseBinary(0)
+	if err != nil || !parser.is("?") {
+		return condition, err
+	}
+	parser.next()
+	_, err = parser.parseExpression()
+	if err != nil {
+		return condition, err
+	}
+	_, err = parser.expect(":", "in the conditional expression")
+	if err != nil {
+		return condition, err
+	}
+	_, err = parser.parseTernary()
+	return tsExpression{}, err
+}
+
+// parseBinary : parses the binary operators of a level of precedence and those binding tighter
+func (parser *tsParser) parseBinary(level int) (tsExpression, error) {
+	if level == len(tsBinaryOperators) {
+		return parser.parseUnary()
+	}
+	left, err := parser.parseBinary(level + 1)
+	if err != nil {
+		return left, err
+	}
+	for parser.peek().kind == tsOperator && parser.is(tsBinaryOperators[level]...) {
+		parser.next()
+		_, err := parser.parseBinary(level + 1)
+		if err != nil {
+			return left, err
+		}
+		left = tsExpression{}
+	}
+	return left, nil
+}
+
+// parseUnary : parses prefix operators, and the postfix operators of what follows them
+func (parser *tsParser) parseUnary() (tsExpression, error) {
+	if parser.peek().kind == tsOperator && parser.is("!", "-", "+", "~", "++", "--") {
+		operator := parser.next()
+		start := parser.peek()
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return operand, err
+		}
+		if (operator.text == "++" || operator.text == "--") && !operand.assignable {
+			return operand, parser.errorAt(start, "%s can only change a variable", operator.text)
+		}
+		return tsExpression{}, nil
+	}
+
+	start := parser.peek()
+	operand, err := parser.parsePrimary()
+	if err != nil {
+		return operand, err
+	}
+	for parser.peek().kind == tsOperator {
+		switch parser.peek().text {
+		case "[":
+			if !operand.assignable {
+				return operand, nil
+			}
+			parser.next()
+			_, err := parser.parseExpression()
+			if err != nil {
+				return operand, err
+			}
+			_, err = parser.expect("]", "to close the index")
+			if err != nil {
+				return operand, err
+			}
+		case "++", "--":
+			operator := parser.next()
+			if !operand.assignable {
+				return operand, parser.errorAt(start, "%s can only change a variable", operator.text)
+			}
+			return tsExpression{}, nil
+		default:
+			return operand, nil
+		}
+	}
+	return operand, nil
+}
+
+// parsePrimary : parses a literal, variable, call, array, hash or parenthesised expression
+func (parser *tsParser) parsePrimary() (tsExpression, error) {
+	token := parser.next()
+	switch token.kind {
+	case tsNumber:
+		return tsExpression{}, nil
+	case tsString:
+		return tsExpression{}, nil
+	case tsVariable:
+		return tsExpression{assignable: true}, nil
+	case tsIdentifier:
+		if tsKeywords[token.text] {
+			return tsExpression{}, parser.errorAt(token, "unexpected %s", token.text)
+		}
+		if !parser.is("(") {
+			return tsExpression{}, parser.errorAt(token, "%s isn't followed by ( to call it; variables start with $", token.text)
+		}
+		parser.next()
+		call := TrafficScriptCall{Function: token.text, Line: token.line, Column: token.column}
+		for argument := 0; !parser.is(")") || argument > 0; argument++ {
+			if parser.peek().kind == tsEOF {
+				return tsExpression{}, parser.errorAt(token, "the arguments of %s are never closed with )", token.text)
+			}
+			_, err := parser.parseExpression()
+			if err != nil {
+				return tsExpression{}, err
+			}
+			if !parser.is(",") {
+				break
+			}
+			parser.next()
+		}
+		_, err := parser.expect(")", "after the arguments of "+token.text)
+		if err != nil {
+			return tsExpression{}, err
+		}
+		parser.rule.Calls = append(parser.rule.Calls, call)
+		return tsExpression{}, nil
+	case tsOperator:
+		switch token.text {
+		case "(":
+			value, err := parser.parseExpression()
+			if err != nil {
+				return value, err
+			}
+			_, err = parser.expect(")", "to close (")
+			return value, err
+		case "[":
+			for !parser.is("]") {
+				if parser.peek().kind == tsEOF {
+					return tsExpression{}, parser.errorAt(token, "[ is never closed")
+				}
+				_, err := parser.parseExpression()
+				if err != nil {
+					return tsExpression{}, err
+				}
+				if parser.is("=>") {
+					parser.next()
+					_, err := parser.parseExpression()
+					if err != nil {
+						return tsExpression{}, err
+					}
+				}
+				if !parser.is("]") {
+					_, err := parser.expect(",", "between the elements of [")
+					if err != nil {
+						return tsExpression{}, err
+					}
+				}
+			}
+			parser.next()
+			return tsExpression{}, nil
+		}
+	}
+	return tsExpression{}, parser.errorAt(token, "expected an expression, but found %s", token.describe())
+}
+
+// ParseTrafficScript : parses a TrafficScript rule, returning the functions it defines, imports and calls, or the
+// first syntax error with the line and column it's at
+func ParseTrafficScript(rule string) (*TrafficScriptRule, error) {
+	tokens, err := lexTrafficScript(rule)
+	if err != nil {
+		return nil, err
+	}
+	parser := &tsParser{
+		tokens: tokens,
+		rule:   &TrafficScriptRule{Subroutines: make(map[string]bool), Imports: make(map[string]bool), Calls: make([]TrafficScriptCall, 0)},
+	}
+	err = parser.parseStatements("")
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tsEOF {
+		return nil, parser.errorAt(token, "unexpected %s", token.describe())
+	}
+	return parser.rule, nil
+}
+
+// UnknownFunctionWarnings : returns warnings for the calls of a rule to functions which are neither built into
+// TrafficScript nor defined or imported by the rule
+func (rule *TrafficScriptRule) UnknownFunctionWarnings() []string {
+	warnings := make([]string, 0)
+	for _, call := range rule.Calls {
+		function := strings.ToLower(call.Function)
+		parts := strings.SplitN(function, ".", 2)
+		if trafficScriptFunctions[function] || rule.Subroutines[function] || (len(parts) == 2 && rule.Imports[parts[0]]) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("line %d, column %d: %s is not a known TrafficScript function", call.Line, call.Column, call.Function))
+	}
+	return warnings
+}
+
+// ValidateTrafficScript : check a rule is valid TrafficScript, giving the line and column of any syntax error and
+// warning of calls to unknown functions
+func ValidateTrafficScript(v interface{}, k string) (ws []string, errors []error) {
+	rule, err := ParseTrafficScript(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("[ERROR] %q is not valid TrafficScript: %v", k, err))
+		return
+	}
+	for _, warning := range rule.UnknownFunctionWarnings() {
+		ws = append(ws, fmt.Sprintf("[WARN] %q %s", k, warning))
+	}
+	return
+}
@@ -0,0 +1,120 @@
+package util
+
+import "strings"
+
+// trafficScriptFunctions : the functions built into TrafficScript, by chapter of the TrafficScript reference, in lower
+// case as TrafficScript ignores the case of their names. Calls to functions missing from here are only warned of
+var trafficScriptFunctions = tsFunctionSet(
+	// auth
+	"auth.query",
+
+	// connection
+	"connection.close", "connection.data.get", "connection.data.reset", "connection.data.set", "connection.discard",
+	"connection.getBandwidthClass", "connection.getCompletionReasonCode", "connection.getCompletionReasonInfo",
+	"connection.getData", "connection.getDataLen", "connection.getLine", "connection.getLocalIP",
+	"connection.getLocalPort", "connection.getMemoryUsage", "connection.getNode", "connection.getPersistence",
+	"connection.getPool", "connection.getRemoteIP", "connection.getRemotePort", "connection.getServiceLevelClass",
+	"connection.getVirtualServer", "connection.setBandwidthClass", "connection.setIdleTimeout",
+	"connection.setPersistence", "connection.setPersistenceKey", "connection.setPersistenceNode",
+	"connection.setServiceLevelClass", "connection.sleep",
+
+	// counter, event and log
+	"counter.increment", "event.emit", "log.debug", "log.emergency", "log.error", "log.info", "log.warn",
+
+	// data
+	"data.get", "data.getMemoryUsage", "data.local.get", "data.local.reset", "data.local.set", "data.remove",
+	"data.reset", "data.set",
+
+	// geo
+	"geo.getCity", "geo.getCountry", "geo.getCountryCode", "geo.getDistance", "geo.getLatitude", "geo.getLongitude",
+	"geo.getRegion", "geo.getRegionCode",
+
+	// glb
+	"glb.service.getDomain", "glb.service.getLocation", "glb.service.getName", "glb.service.setLocation",
+
+	// http
+	"http.addHeader", "http.addResponseHeader", "http.aptimizer.bypass", "http.aptimizer.use",
+	"http.cache.disable", "http.cache.enable", "http.cache.exempt", "http.cache.getKey", "http.cache.setKey",
+	"http.changeSite", "http.compress.disable", "http.compress.enable", "http.getBody", "http.getCookie",
+	"http.getCookies", "http.getFormParam", "http.getFormParams", "http.getHeader", "http.getHeaderNames",
+	"http.getHeaders", "http.getHostHeader", "http.getMethod", "http.getPath", "http.getQueryString",
+	"http.getRawURL", "http.getRequest", "http.getResponse", "http.getResponseBody", "http.getResponseCode",
+	"http.getResponseCookie", "http.getResponseCookies", "http.getResponseHeader", "http.getResponseHeaderNames",
+	"http.getResponseHeaders", "http.getVersion", "http.headerExists", "http.listHeaderNames",
+	"http.listResponseHeaderNames", "http.redirect", "http.removeCookie", "http.removeHeader",
+	"http.removeResponseCookie", "http.removeResponseHeader", "http.responseHeaderExists", "http.sendResponse",
+	"http.setBody", "http.setCookie", "http.setHeader", "http.setPath", "http.setQueryString", "http.setRawURL",
+	"http.setResponseBody", "http.setResponseCode", "http.setResponseCookie", "http.setResponseHeader",
+	"http.stream.continueFromBackend", "http.stream.finishResponse", "http.stream.readResponse",
+	"http.stream.startResponse", "http.stream.writeResponse", "http.request.delete", "http.request.get",
+	"http.request.post", "http.request.put",
+
+	// json, xml and java
+	"java.run", "json.deserialize", "json.serialize", "xml.validate.dtd", "xml.validate.xsd",
+	"xml.xpath.matchNodeCount", "xml.xpath.matchNodeSet", "xml.xslt.transform",
+
+	// lang, math, array and hash
+	"array.contains", "array.copy", "array.filter", "array.join", "array.length", "array.pop", "array.push",
+	"array.reverse", "array.shift", "array.sort", "array.sortNumerical", "array.splice", "array.unshift",
+	"hash.contains", "hash.count", "hash.delete", "hash.empty", "hash.keys", "hash.values", "lang.assert",
+	"lang.chr", "lang.dump", "lang.isArray", "lang.isHash", "lang.ord", "lang.toArray", "lang.toDouble",
+	"lang.toHash", "lang.toInt", "lang.toString", "math.abs", "math.ceil", "math.floor", "math.random",
+	"math.round",
+
+	// net
+	"net.dns.resolveHost", "net.dns.resolveHostAll", "net.dns.resolveIP", "net.dns.resolveIPv6",
+
+	// pool and rate
+	"pool.activeNodes", "pool.getNodeState", "pool.listActiveNodes", "pool.listAllNodes", "pool.listDisabledNodes",
+	"pool.listDrainingNodes", "pool.select", "pool.use", "rate.getBacklog", "rate.getMaxRatePerMinute",
+	"rate.getMaxRatePerSecond", "rate.setMaxRatePerMinute", "rate.setMaxRatePerSecond", "rate.use",
+	"rate.use.noQueue",
+
+	// request and response
+	"request.avoidNode", "request.endsWith", "request.get", "request.getDestIP", "request.getDestPort",
+	"request.getLength", "request.getLine", "request.getLocalIP", "request.getLocalPort", "request.getRemoteIP",
+	"request.getRemotePort", "request.retry", "request.sendResponse", "request.set", "request.setLine",
+	"response.append", "response.endsWith", "response.get", "response.getLength", "response.getLine",
+	"response.set", "response.setLine",
+
+	// resource
+	"resource.exists", "resource.get", "resource.getMimeType", "resource.getMTime",
+
+	// rtsp and sip
+	"rtsp.addHeader", "rtsp.getHeader", "rtsp.getMethod", "rtsp.getPath", "rtsp.removeHeader", "rtsp.setHeader",
+	"rtsp.setPath", "sip.addHeader", "sip.getBody", "sip.getHeader", "sip.getMethod", "sip.getRequestURI",
+	"sip.getStatusCode", "sip.removeHeader", "sip.sendResponse", "sip.setBody", "sip.setHeader", "sip.setRequestURI",
+
+	// slm
+	"slm.conforming", "slm.isConforming",
+
+	// ssl
+	"ssl.clientCertPresent", "ssl.clientCert", "ssl.getCipher", "ssl.getCipherBits", "ssl.getProtocol",
+	"ssl.getServerName", "ssl.getSessionID", "ssl.isSSL",
+
+	// string
+	"string.base64decode", "string.base64encode", "string.bytesToDotted", "string.bytesToInt", "string.cmp",
+	"string.contains", "string.count", "string.decrypt", "string.dottedToBytes", "string.drop", "string.encrypt",
+	"string.endsWith", "string.escape", "string.extractBytes", "string.find", "string.findRev", "string.gmtime",
+	"string.hashMD5", "string.hashSHA1", "string.hashSHA256", "string.hashSHA384", "string.hashSHA512",
+	"string.hexDecode", "string.hexEncode", "string.htmlDecode", "string.htmlEncode", "string.icmp",
+	"string.insertBytes", "string.intToBytes", "string.ipMaskMatch", "string.left", "string.len",
+	"string.lowercase", "string.regexEscape", "string.regexMatch", "string.regexSub", "string.replace",
+	"string.replaceAll", "string.replaceBytes", "string.right", "string.skip", "string.split", "string.sprintf",
+	"string.startsWith", "string.substring", "string.toLower", "string.toUpper", "string.trim", "string.unescape",
+	"string.uppercase", "string.urlDecode", "string.urlEncode", "string.validUTF8", "string.wildMatch",
+
+	// sys
+	"sys.domainName", "sys.getPid", "sys.gmtime", "sys.gmtime.format", "sys.hostname", "sys.localtime",
+	"sys.localtime.format", "sys.time", "sys.time.highres", "sys.time.hour", "sys.time.minutes", "sys.time.month",
+	"sys.time.monthday", "sys.time.seconds", "sys.time.weekday", "sys.time.year", "sys.time.yearday",
+)
+
+// tsFunctionSet : returns the set of the names of functions, in lower case
+func tsFunctionSet(functions ...string) map[string]bool {
+	set := make(map[string]bool, len(functions))
+	for _, function := range functions {
+		set[strings.ToLower(function)] = true
+	}
+	return set
+}
@@ -0,0 +1,138 @@
+package pulsevtm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+// resourceSectionNames : the functions mapping the sections of a traffic manager resource to the Terraform
+// attributes holding them, for resource types where they differ
+var resourceSectionNames = map[string]func(string) string{
+	"pulsevtm_global_settings": globalSettingsSectionName,
+	"pulsevtm_pool":            poolSectionName,
+	"pulsevtm_virtual_server":  sectionName,
+}
+
+// vtmAttributeError : an error returned by the traffic manager, with the errors in its error_info named by the
+// Terraform attributes they relate to
+type vtmAttributeError struct {
+	err     error
+	message string
+}
+
+func (attributeError *vtmAttributeError) Error() string {
+	return attributeError.message
+}
+
+// Cause : returns the error the traffic manager returned
+func (attributeError *vtmAttributeError) Cause() error {
+	return attributeError.err
+}
+
+// wrappedError : an error giving the context an error was returned in, which keeps the error so a traffic manager
+// error can be recovered from it with vtmErrorCause
+type wrappedError struct {
+	err     error
+	message string
+}
+
+func (wrapped *wrappedError) Error() string {
+	return wrapped.message
+}
+
+// Cause : returns the error wrapped
+func (wrapped *wrappedError) Cause() error {
+	return wrapped.err
+}
+
+// wrapErrorf : returns an error with the formatted message, which should include err, wrapping err
+func wrapErrorf(err error, format string, a ...interface{}) error {
+	return &wrappedError{err: err, message: fmt.Sprintf(format, a...)}
+}
+
+// vtmErrorCause : returns the traffic manager error err is or wraps, following the causes of wrapped errors
+func vtmErrorCause(err error) (*api.VTMError, bool) {
+	for err != nil {
+		if tmErr, ok := err.(*api.VTMError); ok {
+			return tmErr, true
+		}
+		wrapped, ok := err.(interface {
+			Cause() error
+		})
+		if !ok {
+			return nil, false
+		}
+		err = wrapped.Cause()
+	}
+	return nil, false
+}
+
+// vtmErrorAttributePath : returns the path of the Terraform attribute an error in error_info relates to. Sections
+// are held in blocks of a single item, apart from the basic section, whose properties are top level attributes.
+// The path within error_info is returned when no attribute can be found.
+func vtmErrorAttributePath(resourceSchema map[string]*schema.Schema, sectionAttribute func(string) string, path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	if path[0] == "properties" {
+		path = path[1:]
+	}
+	if len(path) > 1 {
+		attribute := path[0]
+		if sectionAttribute != nil {
+			attribute = sectionAttribute(attribute)
+		}
+		if section, ok := resourceSchema[attribute]; ok && section.Type == schema.TypeList {
+			if _, ok := section.Elem.(*schema.Resource); ok {
+				return strings.Join(append([]string{attribute, "0"}, path[1:]...), ".")
+			}
+		}
+		if _, ok := resourceSchema[path[1]]; ok && path[0] == "basic" {
+			return strings.Join(path[1:], ".")
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// withAttributeErrors : returns err with each of the errors in the error_info of the traffic manager error it wraps
+// named by the Terraform attribute it relates to. err is returned as is when it doesn't wrap a traffic manager error
+// or the error has no error_info.
+func withAttributeErrors(err error, resourceSchema map[string]*schema.Schema, sectionAttribute func(string) string) error {
+	tmErr, ok := vtmErrorCause(err)
+	if !ok {
+		return err
+	}
+	leaves := tmErr.Leaves()
+	if len(leaves) == 0 {
+		return err
+	}
+
+	attributeErrors := make([]string, 0)
+	for _, leaf := range leaves {
+		attributeError := fmt.Sprintf("%s: %s", vtmErrorAttributePath(resourceSchema, sectionAttribute, leaf.Path), leaf.ErrorText)
+		if leaf.ErrorID != "" {
+			attributeError += fmt.Sprintf(" (%s)", leaf.ErrorID)
+		}
+		attributeErrors = append(attributeErrors, attributeError)
+	}
+	message := strings.Replace(err.Error(), tmErr.Error(), tmErr.Summary(), 1)
+	message = strings.TrimSpace(message) + "\n\t" + strings.Join(attributeErrors, "\n\t")
+	return &vtmAttributeError{err: err, message: message}
+}
+
+// attributeErrorResource : wraps the create and update operations of a resource so the errors the traffic manager
+// returns name the Terraform attributes they relate to rather than the traffic manager's properties
+func attributeErrorResource(resource *schema.Resource, sectionAttribute func(string) string) {
+	apply := func(applyFunc func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+		return func(d *schema.ResourceData, m interface{}) error {
+			return withAttributeErrors(applyFunc(d, m), resource.Schema, sectionAttribute)
+		}
+	}
+	resource.Create = apply(resource.Create)
+	if resource.Update != nil {
+		resource.Update = apply(resource.Update)
+	}
+}
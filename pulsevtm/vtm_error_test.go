@@ -0,0 +1,142 @@
+package pulsevtm
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+// testValidationError : the error a traffic manager returns for a pool with invalid properties
+var testValidationError = map[string]interface{}{
+	"error_id":   "resource.validation_error",
+	"error_text": "The resource provided is invalid",
+	"error_info": map[string]interface{}{
+		"basic": map[string]interface{}{
+			"monitors": map[string]interface{}{
+				"error_id":   "config.reference.missing",
+				"error_text": "Monitor 'unknown' does not exist",
+			},
+		},
+		"connection": map[string]interface{}{
+			"max_queue_size": map[string]interface{}{
+				"error_id":   "type.uint.negative",
+				"error_text": "Value must not be negative",
+			},
+		},
+		"ssl": map[string]interface{}{
+			"support_tls1_2": map[string]interface{}{
+				"error_id":   "type.enum.invalid",
+				"error_text": "Value 'sometimes' is not valid",
+			},
+		},
+	},
+}
+
+func TestVTMErrorNamesTerraformAttributes(t *testing.T) {
+	server := newTestVTMServer(t)
+	defer server.Close()
+	server.handlers[testVTMConfigPath+"pools/web"] = func(w http.ResponseWriter, r *http.Request) {
+		server.writeJSON(w, http.StatusBadRequest, testValidationError)
+	}
+
+	provider, err := testConfiguredProvider(t, server, "5.1")
+	if err != nil {
+		t.Fatalf("unexpected error configuring provider: %v", err)
+	}
+	resource := provider.ResourcesMap["pulsevtm_pool"]
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"name":       "web",
+		"nodes_list": []interface{}{"10.0.0.1:80"},
+	})
+	err = resource.Create(d, provider.Meta())
+	if err == nil {
+		t.Fatalf("expected an error creating an invalid pool")
+	}
+
+	for _, expected := range []string{
+		`error whilst creating/updating web: resource.validation_error: The resource provided is invalid\n`,
+		`\tmonitors: Monitor 'unknown' does not exist \(config.reference.missing\)`,
+		`\tpool_connection.0.max_queue_size: Value must not be negative \(type.uint.negative\)`,
+		`\tssl.0.support_tls1_2: Value 'sometimes' is not valid \(type.enum.invalid\)`,
+	} {
+		if !regexp.MustCompile(expected).MatchString(err.Error()) {
+			t.Errorf("expected error to match %q, got:\n%v", expected, err)
+		}
+	}
+
+	tmErr, ok := vtmErrorCause(err)
+	if !ok {
+		t.Fatalf("expected the traffic manager error to be recoverable from %v", err)
+	}
+	if len(tmErr.Leaves()) != 3 {
+		t.Errorf("expected the error_info tree to be kept, got %v", tmErr.Leaves())
+	}
+	if tmErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d to be kept, got %d", http.StatusBadRequest, tmErr.StatusCode)
+	}
+}
+
+func TestVTMErrorLeaves(t *testing.T) {
+	tmErr := &api.VTMError{
+		ErrorID:   "resource.validation_error",
+		ErrorText: "The resource provided is invalid",
+		ErrorInfo: map[string]interface{}{
+			"basic": map[string]interface{}{
+				"nodes_table": []interface{}{
+					map[string]interface{}{},
+					map[string]interface{}{"weight": map[string]interface{}{"error_text": "Value too large"}},
+				},
+				"port":  "Value must be a port",
+				"count": float64(3),
+			},
+		},
+	}
+
+	leaves := tmErr.Leaves()
+	expected := []api.VTMErrorLeaf{
+		{Path: []string{"basic", "count"}, ErrorText: "3"},
+		{Path: []string{"basic", "nodes_table", "1", "weight"}, ErrorText: "Value too large"},
+		{Path: []string{"basic", "port"}, ErrorText: "Value must be a port"},
+	}
+	if len(leaves) != len(expected) {
+		t.Fatalf("expected %d leaves, got %v", len(expected), leaves)
+	}
+	for i, leaf := range leaves {
+		if leaf.ErrorText != expected[i].ErrorText || strings.Join(leaf.Path, ".") != strings.Join(expected[i].Path, ".") {
+			t.Errorf("expected leaf %v, got %v", expected[i], leaf)
+		}
+	}
+
+	// Formatting the error as text mustn't panic on values other than strings and maps
+	if message := tmErr.Error(); !regexp.MustCompile(`error_text : Value too large`).MatchString(message) {
+		t.Errorf("unexpected error text: %s", message)
+	}
+}
+
+func TestVTMErrorAttributePath(t *testing.T) {
+	poolSchema := resourcePool().Schema
+	cases := []struct {
+		Path     []string
+		Expected string
+	}{
+		{[]string{"basic", "monitors"}, "monitors"},
+		{[]string{"properties", "basic", "nodes_table", "1", "weight"}, "nodes_table.1.weight"},
+		{[]string{"ssl", "support_tls1_2"}, "ssl.0.support_tls1_2"},
+		{[]string{"connection", "max_queue_size"}, "pool_connection.0.max_queue_size"},
+		{[]string{"basic", "unknown_property"}, "basic.unknown_property"},
+		{[]string{"name"}, "name"},
+	}
+	for _, tc := range cases {
+		if path := vtmErrorAttributePath(poolSchema, poolSectionName, tc.Path); path != tc.Expected {
+			t.Errorf("expected %v to map to %s, got %s", tc.Path, tc.Expected, path)
+		}
+	}
+
+	if err := withAttributeErrors(nil, poolSchema, poolSectionName); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
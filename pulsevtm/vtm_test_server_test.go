@@ -0,0 +1,343 @@
+package pulsevtm
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sky-uk/terraform-provider-pulsevtm/pulsevtm/api"
+)
+
+const testVTMConfigPath = "/api/tm/5.1/config/active/"
+const testVTMStatusPath = "/api/tm/5.1/status/"
+
+// testVTMServer : a minimal in-memory stand-in for the vTM REST API used by unit tests
+type testVTMServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	resources map[string]map[string]interface{}
+	// beforeGet, when set, is called with the resource path before every GET is answered
+	beforeGet func(path string)
+	// handlers answer paths the in-memory configuration store doesn't know about
+	handlers map[string]http.HandlerFunc
+	// backups holds the full backups taken, keyed by <traffic manager>/<name>
+	backups map[string]map[string]interface{}
+	// restored lists the backups restored, in order
+	restored []string
+	// backupTime is the time stamp given to the next backup taken
+	backupTime int
+	// backupsUnavailable, when set, fails every attempt to take a backup
+	backupsUnavailable bool
+	// backupArchives holds the tar archive of each backup, keyed like backups
+	backupArchives map[string][]byte
+	// files holds the configuration resources which are raw files, such as rules, keyed by path
+	files map[string][]byte
+}
+
+func newTestVTMServer(t *testing.T) *testVTMServer {
+	server := &testVTMServer{
+		resources:      make(map[string]map[string]interface{}),
+		handlers:       make(map[string]http.HandlerFunc),
+		backups:        make(map[string]map[string]interface{}),
+		backupArchives: make(map[string][]byte),
+		files:          make(map[string][]byte),
+		backupTime:     1500000000,
+	}
+	server.Server = httptest.NewTLSServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+func (server *testVTMServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if handler, ok := server.handlers[r.URL.Path]; ok {
+		handler(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, testVTMStatusPath) && strings.Contains(r.URL.Path, "/backups/full") {
+		server.serveBackups(w, r)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, testVTMConfigPath) {
+		http.NotFound(w, r)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, testVTMConfigPath)
+	if server.serveFile(w, r, path) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if server.beforeGet != nil {
+			server.beforeGet(path)
+		}
+		server.mu.Lock()
+		resource, ok := server.resources[path]
+		server.mu.Unlock()
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_id":"resource.not_found","error_text":"Resource does not exist"}`))
+			return
+		}
+		server.writeJSON(w, http.StatusOK, resource)
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		request := make(map[string]interface{})
+		json.Unmarshal(body, &request)
+		server.mu.Lock()
+		status := http.StatusOK
+		if _, ok := server.resources[path]; !ok {
+			status = http.StatusCreated
+			server.resources[path] = map[string]interface{}{"properties": map[string]interface{}{}}
+		}
+		properties := server.resources[path]["properties"].(map[string]interface{})
+		for sectionName, section := range request["properties"].(map[string]interface{}) {
+			if _, ok := properties[sectionName]; !ok {
+				properties[sectionName] = make(map[string]interface{})
+			}
+			for key, value := range section.(map[string]interface{}) {
+				properties[sectionName].(map[string]interface{})[key] = value
+			}
+		}
+		resource := server.resources[path]
+		server.mu.Unlock()
+		server.writeJSON(w, status, resource)
+	case http.MethodDelete:
+		server.mu.Lock()
+		delete(server.resources, path)
+		server.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// serveFile : answers requests for configuration resources which are raw files, returning whether the request was for
+// one
+func (server *testVTMServer) serveFile(w http.ResponseWriter, r *http.Request, path string) bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	file, exists := server.files[path]
+	switch {
+	case r.Method == http.MethodPut && r.Header.Get("Content-Type") == "application/octet-stream":
+		body, _ := ioutil.ReadAll(r.Body)
+		server.files[path] = body
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case !exists:
+		return false
+	case r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(file)
+	case r.Method == http.MethodDelete:
+		delete(server.files, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return true
+}
+
+// serveBackups : answers requests to list, take, restore and delete full backups
+func (server *testVTMServer) serveBackups(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.SplitN(strings.TrimPrefix(r.URL.Path, testVTMStatusPath), "/backups/full", 2)
+	trafficManager, name := pathParts[0], strings.TrimPrefix(pathParts[1], "/")
+	key := trafficManager + "/" + name
+
+	if name == "" {
+		server.mu.Lock()
+		names := make([]string, 0)
+		for backupKey := range server.backups {
+			if strings.HasPrefix(backupKey, trafficManager+"/") {
+				names = append(names, strings.TrimPrefix(backupKey, trafficManager+"/"))
+			}
+		}
+		server.mu.Unlock()
+		sort.Strings(names)
+		children := make([]interface{}, 0)
+		for _, backupName := range names {
+			children = append(children, map[string]interface{}{"name": backupName, "href": r.URL.Path + "/" + backupName})
+		}
+		server.writeJSON(w, http.StatusOK, map[string]interface{}{"children": children})
+		return
+	}
+
+	server.mu.Lock()
+	backup, exists := server.backups[key]
+	server.mu.Unlock()
+	notFound := func() {
+		server.writeJSON(w, http.StatusNotFound, map[string]interface{}{"error_id": "resource.not_found", "error_text": "Backup does not exist"})
+	}
+
+	switch {
+	case r.Method == http.MethodPut && r.URL.RawQuery == "restore":
+		if !exists {
+			notFound()
+			return
+		}
+		server.mu.Lock()
+		server.restored = append(server.restored, key)
+		server.mu.Unlock()
+		server.writeJSON(w, http.StatusOK, map[string]interface{}{})
+	case r.Method == http.MethodGet && r.Header.Get("Accept") == "application/x-tar":
+		server.mu.Lock()
+		archive, exists := server.backupArchives[key]
+		server.mu.Unlock()
+		if !exists {
+			notFound()
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-tar")
+		w.Write(archive)
+	case r.Method == http.MethodPut && r.Header.Get("Content-Type") == "application/x-tar":
+		archive, _ := ioutil.ReadAll(r.Body)
+		if _, err := tar.NewReader(bytes.NewReader(archive)).Next(); err != nil {
+			server.writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error_id": "backup.invalid", "error_text": "The backup archive is invalid"})
+			return
+		}
+		server.mu.Lock()
+		server.backups[key] = map[string]interface{}{"properties": map[string]interface{}{"backup": map[string]interface{}{
+			"description": "uploaded",
+			"time_stamp":  float64(server.backupTime),
+			"version":     "17.2",
+		}}}
+		server.backupTime++
+		server.backupArchives[key] = archive
+		server.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && server.backupsUnavailable:
+		server.writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"error_id": "backup.failed", "error_text": "Insufficient disk space"})
+	case r.Method == http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		request := make(map[string]interface{})
+		json.Unmarshal(body, &request)
+		description := ""
+		if properties, ok := request["properties"].(map[string]interface{}); ok {
+			if backupInfo, ok := properties["backup"].(map[string]interface{}); ok {
+				description, _ = backupInfo["description"].(string)
+			}
+		}
+		server.mu.Lock()
+		backup = map[string]interface{}{"properties": map[string]interface{}{"backup": map[string]interface{}{
+			"description": description,
+			"time_stamp":  float64(server.backupTime),
+			"version":     "17.2",
+		}}}
+		server.backupTime++
+		server.backups[key] = backup
+		server.backupArchives[key] = testBackupArchive(key)
+		server.mu.Unlock()
+		server.writeJSON(w, http.StatusCreated, backup)
+	case r.Method == http.MethodGet:
+		if !exists {
+			notFound()
+			return
+		}
+		server.writeJSON(w, http.StatusOK, backup)
+	case r.Method == http.MethodDelete:
+		if !exists {
+			notFound()
+			return
+		}
+		server.mu.Lock()
+		delete(server.backups, key)
+		delete(server.backupArchives, key)
+		server.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// testBackupArchive : returns a tar archive standing in for the full backup of a traffic manager
+func testBackupArchive(key string) []byte {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	content := []byte("backup " + key + "\n")
+	writer.WriteHeader(&tar.Header{Name: "config/backup", Mode: 0600, Size: int64(len(content))})
+	writer.Write(content)
+	writer.Close()
+	return archive.Bytes()
+}
+
+func (server *testVTMServer) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	server.mu.Lock()
+	encoded, _ := json.Marshal(body)
+	server.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(encoded)
+}
+
+// setResource : stores a configuration resource, round-tripping it through JSON like the appliance would
+func (server *testVTMServer) setResource(path string, resource map[string]interface{}) {
+	encoded, _ := json.Marshal(resource)
+	decoded := make(map[string]interface{})
+	json.Unmarshal(encoded, &decoded)
+	server.mu.Lock()
+	server.resources[path] = decoded
+	server.mu.Unlock()
+}
+
+// getResource : returns a copy of a stored configuration resource
+func (server *testVTMServer) getResource(path string) map[string]interface{} {
+	server.mu.Lock()
+	encoded, _ := json.Marshal(server.resources[path])
+	server.mu.Unlock()
+	decoded := make(map[string]interface{})
+	json.Unmarshal(encoded, &decoded)
+	return decoded
+}
+
+// meta : builds the provider meta object pointing at the test server
+func (server *testVTMServer) meta(t *testing.T) map[string]interface{} {
+	client, err := api.Connect(api.Params{
+		APIVersion: "5.1",
+		Server:     server.URL,
+		IgnoreSSL:  true,
+		Timeout:    5,
+	})
+	if err != nil {
+		t.Fatalf("[ERROR] connecting to test vTM server: %v", err)
+	}
+	octetClient, err := api.Connect(api.Params{
+		APIVersion: "5.1",
+		Server:     server.URL,
+		IgnoreSSL:  true,
+		Timeout:    5,
+		Headers: map[string]string{
+			"Content-Type":              "application/octet-stream",
+			"Content-Transfer-Encoding": "text",
+		},
+	})
+	if err != nil {
+		t.Fatalf("[ERROR] connecting to test vTM server: %v", err)
+	}
+	statusClient, err := api.Connect(api.Params{
+		APIVersion: "5.1",
+		Server:     server.URL,
+		IgnoreSSL:  true,
+		Timeout:    5,
+	})
+	if err != nil {
+		t.Fatalf("[ERROR] connecting to test vTM server: %v", err)
+	}
+	statusClient.WorkWithStatus()
+	return map[string]interface{}{"jsonClient": client, "octetClient": octetClient, "statusClient": statusClient}
+}
+
+// setAPIVersions : sets the REST API versions the test server lists for clients detecting the version to use
+func (server *testVTMServer) setAPIVersions(versions ...string) {
+	children := make([]map[string]interface{}, 0)
+	for _, version := range versions {
+		children = append(children, map[string]interface{}{"name": version, "href": "/api/tm/" + version + "/"})
+	}
+	server.handlers["/api/tm"] = func(w http.ResponseWriter, r *http.Request) {
+		server.writeJSON(w, http.StatusOK, map[string]interface{}{"children": children})
+	}
+}